@@ -0,0 +1,148 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const (
+	eventTypeAppHomeOpened = "app_home_opened"
+	appHomeTab             = "home"
+
+	actionHomeStartMeeting = "home_start_meeting"
+
+	homeView = `{"type":"home","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"Start a meeting and get a link to share with anyone you'd like to meet with."}},{"type":"actions","elements":[{"type":"button","action_id":"%s","text":{"type":"plain_text","text":"Start a meeting"},"style":"primary"}]}]}`
+
+	// homeBusyView replaces the Home tab while the workers are saturated.
+	homeBusyView = `{"type":"home","blocks":[{"type":"section","text":{"type":"mrkdwn","text":%s}}]}`
+
+	homeMeetingMsg = "Your meeting is ready."
+)
+
+type appHomeOpenedEvent struct {
+	Type string `json:"type"`
+	User string `json:"user"`
+	Tab  string `json:"tab"`
+}
+
+// publishHome publishes the app's Home tab with a button that starts a
+// meeting.
+func (s *SlashCommandHandlers) publishHome(ctx context.Context, teamID string, event appHomeOpenedEvent) {
+	log := zerolog.Ctx(ctx)
+	token, err := s.botToken(ctx, teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving token")
+		return
+	}
+	err = s.callSlackAPI(ctx, token, "views.publish", map[string]interface{}{
+		"user_id": event.User,
+		"view":    json.RawMessage(fmt.Sprintf(homeView, actionHomeStartMeeting)),
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("publishing home view")
+	}
+}
+
+// homeMeeting starts a meeting from the Home tab and DMs its link to the
+// user who started it.
+func (s *SlashCommandHandlers) homeMeeting(ctx context.Context, payload interactionPayload) {
+	log := zerolog.Ctx(ctx)
+	if s.MaintenanceMode {
+		return
+	}
+	token, err := s.botToken(ctx, payload.Team.ID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving token")
+		return
+	}
+	features, err := s.teamFeatures(payload.Team.ID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving features")
+		return
+	}
+
+	slackClient := slack.New(token, slack.OptionHTTPClient(httpClientOrDefault(s.HTTPClient)))
+	allowed, err := s.canHost(ctx, slackClient, payload.Team.ID, payload.User.ID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("checking host policy")
+		return
+	}
+	if !allowed {
+		return
+	}
+	userInfo, err := s.userInfo(ctx, slackClient, payload.Team.ID, payload.User.ID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving user info from slack")
+		return
+	}
+
+	serverCfg, err := s.teamServerConfig(ctx, payload.Team.ID)
+	if err != nil {
+		return
+	}
+	room := s.roomName(RandomName())
+	confURL, err := s.conferenceURL(ctx, serverCfg, JWTInput{
+		TenantID:     strings.ToLower(payload.Team.ID),
+		TenantName:   strings.ToLower(payload.Team.Domain),
+		RoomClaim:    room,
+		UserID:       userInfo.ID,
+		UserName:     s.conferenceName(userInfo),
+		AvatarURL:    userInfo.Profile.Image192,
+		Features:     features,
+		MaxOccupants: s.MaxOccupants,
+		Lifetime:     serverCfg.MeetingDuration,
+		AppSecret:    serverCfg.AppSecret,
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("creating conference token")
+		return
+	}
+	logMeetingURL(ctx, payload.Team.ID, serverCfg.authenticatedURLs())
+
+	_, err = s.postMessage(ctx, token, payload.User.ID, slack.PostMessageParameters{
+		Username:  s.InviteIdentity.Username,
+		IconURL:   s.InviteIdentity.IconURL,
+		IconEmoji: s.InviteIdentity.IconEmoji,
+		Attachments: []slack.Attachment{{
+			Fallback: homeMeetingMsg,
+			Title:    s.titlePrefix() + homeMeetingMsg,
+			Text:     s.linkExpiry(time.Now(), serverCfg.MeetingDuration),
+			Color:    "#3AA3E3",
+			Fields: []slack.AttachmentField{
+				{Title: "Room", Value: room, Short: true},
+			},
+			Actions: []slack.AttachmentAction{{
+				Name:  "join",
+				Text:  "Join",
+				Type:  "button",
+				Style: "primary",
+				URL:   s.shorten(ctx, confURL),
+			}},
+		}},
+	})
+	if !delivered(err) {
+		log.Error().
+			Err(err).
+			Msg("posting home meeting")
+	}
+}
@@ -0,0 +1,123 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func homeOpened(tab string) string {
+	return `{"type":"event_callback","team_id":"T1","event":{"type":"app_home_opened","user":"UHOST","tab":"` + tab + `"}}`
+}
+
+const homeButtonPayload = `{
+	"type": "block_actions",
+	"user": {"id": "UHOST"},
+	"team": {"id": "T1", "domain": "acme"},
+	"actions": [{"action_id": "home_start_meeting"}]
+}`
+
+func TestAppHomeOpenedPublishesHome(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	w := httptest.NewRecorder()
+	s.Events(w, signedRequest(t, PathEvents, "application/json", homeOpened("home")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	published := waitForCalls(t, slack, "views.publish")
+	if len(published) != 1 {
+		t.Fatalf("views.publish calls = %d, want 1", len(published))
+	}
+	var payload struct {
+		UserID string `json:"user_id"`
+		View   struct {
+			Type   string `json:"type"`
+			Blocks []struct {
+				Type     string `json:"type"`
+				Elements []struct {
+					Type     string `json:"type"`
+					ActionID string `json:"action_id"`
+				} `json:"elements"`
+			} `json:"blocks"`
+		} `json:"view"`
+	}
+	body := mustMarshal(t, published[0].Body)
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	if payload.UserID != "UHOST" || payload.View.Type != "home" {
+		t.Errorf("published %+v, want the home view for UHOST", payload)
+	}
+	var button string
+	for _, block := range payload.View.Blocks {
+		for _, element := range block.Elements {
+			if element.Type == "button" {
+				button = element.ActionID
+			}
+		}
+	}
+	if button != actionHomeStartMeeting {
+		t.Errorf("home view %s, want a %s button", body, actionHomeStartMeeting)
+	}
+}
+
+func TestAppHomeOtherTabIgnored(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.Workers = &WorkerPool{Size: 1, Queue: 1}
+
+	w := httptest.NewRecorder()
+	s.Events(w, signedRequest(t, PathEvents, "application/json", homeOpened("messages")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if queued := s.Workers.Stats().Queued; queued != 0 {
+		t.Errorf("queued = %d, want no view published", queued)
+	}
+}
+
+func TestHomeButtonDMsMeeting(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	if w := interact(t, s, homeButtonPayload); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	posted := waitForCalls(t, slack, "chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want the meeting dm", len(posted))
+	}
+	if got := posted[0].Form.Get("channel"); got != "UHOST" {
+		t.Errorf("meeting posted to %s, want the user who pressed the button", got)
+	}
+	if got := inviteTitle(t, posted[0]); got != homeMeetingMsg {
+		t.Errorf("title = %q, want %q", got, homeMeetingMsg)
+	}
+	link := inviteURL(t, posted[0])
+	if !strings.HasPrefix(link, testConfHost+"/acme/") || !strings.Contains(link, "?jwt=") {
+		t.Fatalf("meeting url = %s, want a token for the team's server", link)
+	}
+	if user := contextOf(t, tokenClaims(t, link)).User; user.ID != "UHOST" {
+		t.Errorf("token user = %+v, want the user who pressed the button", user)
+	}
+}
+
+func TestHomeButtonInMaintenance(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.MaintenanceMode = true
+
+	var payload interactionPayload
+	if err := json.Unmarshal([]byte(homeButtonPayload), &payload); err != nil {
+		t.Fatal(err)
+	}
+	s.homeMeeting(context.Background(), payload)
+	if posted := slack.Calls("chat.postMessage"); len(posted) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want no meeting in maintenance", len(posted))
+	}
+}
@@ -0,0 +1,106 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const (
+	eventTypeAppMention = "app_mention"
+
+	// mentionStartKeyword starts a meeting when it follows a mention of the
+	// app, i.e. '@jitsi start @bob'.
+	mentionStartKeyword = "start"
+)
+
+type appMentionEvent struct {
+	Type    string `json:"type"`
+	User    string `json:"user"`
+	Channel string `json:"channel"`
+	Text    string `json:"text"`
+}
+
+// mentionCommand returns the command text following the start keyword of an
+// app mention and whether the mention asks for a meeting. The app's own
+// mention leads the text.
+func mentionCommand(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) > 0 && atMentionRE.MatchString(fields[0]) {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || !strings.EqualFold(fields[0], mentionStartKeyword) {
+		return "", false
+	}
+	return strings.Join(fields[1:], " "), true
+}
+
+// mentionMeeting runs the command following an app mention as if it were
+// sent with the slash command. Channel meetings are posted to the channel
+// and anything else is shown to the user who mentioned the app.
+func (s *SlashCommandHandlers) mentionMeeting(ctx context.Context, teamID string, event appMentionEvent) {
+	log := zerolog.Ctx(ctx)
+	text, ok := mentionCommand(event.Text)
+	if !ok || event.User == "" {
+		return
+	}
+
+	token, err := s.botToken(ctx, teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving token")
+		return
+	}
+	slackClient := slack.New(token, slack.OptionHTTPClient(httpClientOrDefault(s.HTTPClient)))
+	var team *slack.TeamInfo
+	err = s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		team, err = slackClient.GetTeamInfoContext(ctx)
+		return err
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving team info from slack")
+		return
+	}
+
+	result, err := s.ProcessCommand(ctx, CommandInput{
+		TeamID:    teamID,
+		TeamName:  team.Domain,
+		UserID:    event.User,
+		ChannelID: event.Channel,
+		Text:      text,
+	})
+	if err != nil {
+		result = commandFailed(mentionStartKeyword + " " + text)
+	}
+
+	var msg map[string]interface{}
+	err = json.Unmarshal([]byte(result.Body), &msg)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("decoding mention result")
+		return
+	}
+	method := "chat.postEphemeral"
+	if msg["response_type"] == "in_channel" {
+		method = "chat.postMessage"
+	} else {
+		msg["user"] = event.User
+	}
+	delete(msg, "response_type")
+	msg["channel"] = event.Channel
+	err = s.callSlackAPI(ctx, token, method, msg)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("method", method).
+			Msg("posting mention result")
+	}
+}
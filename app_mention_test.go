@@ -0,0 +1,96 @@
+package jitsi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// mentionOf builds an event for UHOST mentioning the app in C1.
+func mentionOf(text string) string {
+	return `{"type":"event_callback","team_id":"T1","event":{"type":"app_mention","user":"UHOST","channel":"C1","text":"` + text + `"}}`
+}
+
+func TestMentionCommand(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  string
+		start bool
+	}{
+		{"<@UAPP> start", "", true},
+		{"<@UAPP> Start <@UBOB> <@UALICE>", "<@UBOB> <@UALICE>", true},
+		{"<@UAPP>   start   --private  ", "--private", true},
+		{"start <@UBOB>", "<@UBOB>", true},
+		{"<@UAPP>", "", false},
+		{"<@UAPP> hello", "", false},
+		{"<@UAPP> started <@UBOB>", "", false},
+		{"hey <@UAPP> start", "", false},
+	}
+	for _, tt := range tests {
+		got, start := mentionCommand(tt.text)
+		if got != tt.want || start != tt.start {
+			t.Errorf("mentionCommand(%q) = %q, %v, want %q, %v", tt.text, got, start, tt.want, tt.start)
+		}
+	}
+}
+
+func TestMentionStartsChannelMeeting(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	w := httptest.NewRecorder()
+	s.Events(w, signedRequest(t, PathEvents, "application/json", mentionOf("<@UAPP> start")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	posted := waitForCalls(t, slack, "chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want the meeting posted", len(posted))
+	}
+	body := posted[0].Body
+	if body["channel"] != "C1" || body["response_type"] != nil {
+		t.Errorf("message = %v, want it posted to C1 without a response type", body)
+	}
+	if !strings.Contains(string(mustMarshal(t, body)), testConfHost+"/acme/") {
+		t.Errorf("message = %v, want the room link", body)
+	}
+}
+
+func TestMentionInvitesMentionedUsers(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+
+	s.mentionMeeting(context.Background(), testTeamID, appMentionEvent{Type: eventTypeAppMention, User: "UHOST", Channel: "C1", Text: "<@UAPP> start <@UBOB>"})
+	invites := slack.Calls("chat.postMessage")
+	if len(invites) != 1 || invites[0].Form.Get("channel") != "DUBOB" {
+		t.Fatalf("chat.postMessage calls = %+v, want the invite sent to bob", invites)
+	}
+	replies := slack.Calls("chat.postEphemeral")
+	if len(replies) != 1 {
+		t.Fatalf("chat.postEphemeral calls = %d, want the reply shown to the host", len(replies))
+	}
+	body := replies[0].Body
+	if body["channel"] != "C1" || body["user"] != "UHOST" {
+		t.Errorf("reply = %v, want it shown to UHOST in C1", body)
+	}
+	if !strings.Contains(string(mustMarshal(t, body)), "Invitations have been sent") {
+		t.Errorf("reply = %v, want the invite confirmation", body)
+	}
+}
+
+func TestMentionWithoutStartIgnored(t *testing.T) {
+	for _, text := range []string{"<@UAPP>", "<@UAPP> hello there", "<@UAPP> help"} {
+		slack := newFakeSlack(t)
+		s := newTestHandlers(t, slack)
+
+		s.mentionMeeting(context.Background(), testTeamID, appMentionEvent{Type: eventTypeAppMention, User: "UHOST", Channel: "C1", Text: text})
+		for _, method := range []string{"team.info", "chat.postMessage", "chat.postEphemeral"} {
+			if calls := slack.Calls(method); len(calls) != 0 {
+				t.Errorf("%q made %d %s calls, want the mention ignored", text, len(calls), method)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+// KeyUnauthenticatedURLs is the dynamo key for storing whether a team's
+// meeting links are left without tokens.
+const KeyUnauthenticatedURLs = "unauthenticated-urls"
+
+const (
+	authUnsupportedMsg = "Changing authenticated urls is not supported by this installation."
+	authAdminMsg       = "Only workspace admins can change authenticated urls."
+	authUsageMsg       = "Please use '%s auth on' or '%s auth off'."
+	authOnMsg          = "Meeting links are authenticated."
+	authOffMsg         = "Meeting links are not authenticated, anyone with a link can join as any name."
+	authNoSigningMsg   = "Meeting links can't be authenticated, there is no signing key or app secret configured for this workspace."
+)
+
+// authenticatedURLs reports whether the team's meeting links carry a
+// conference token.
+func (cfg ServerConfig) authenticatedURLs() bool {
+	return !cfg.UnauthenticatedURLs
+}
+
+// roomURL returns the url of a room with no token.
+func roomURL(confHost, tenant, room string) string {
+	return fmt.Sprintf("%s/%s/%s", confHost, tenant, room)
+}
+
+// canSign reports whether conference tokens can be signed for a team's
+// server config, signing a throwaway token the way Validate does.
+func (s *SlashCommandHandlers) canSign(ctx context.Context, cfg ServerConfig) bool {
+	_, err := s.createJWT(ctx, JWTInput{
+		TenantID:   "validate",
+		TenantName: "validate",
+		RoomClaim:  "validate",
+		AppSecret:  cfg.AppSecret,
+	})
+	return err == nil
+}
+
+// setAuth shows whether a team's meeting links are authenticated, or turns
+// authentication on or off for workspace admins. Authentication isn't
+// turned on when tokens can't be signed for the team.
+func (s *SlashCommandHandlers) setAuth(ctx context.Context, client *slack.Client, teamID, userID, text string) (CommandResult, error) {
+	if s.ServerConfigs == nil {
+		return ephemeral(authUnsupportedMsg), nil
+	}
+	log := zerolog.Ctx(ctx)
+
+	cfg, err := s.serverConfig(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving server config")
+		return CommandResult{}, err
+	}
+	var authenticated bool
+	switch strings.TrimSpace(text) {
+	case "":
+		if cfg.authenticatedURLs() {
+			return ephemeral(authOnMsg), nil
+		}
+		return ephemeral(authOffMsg), nil
+	case "on":
+		authenticated = true
+	case "off":
+		authenticated = false
+	default:
+		return ephemeral(fmt.Sprintf(authUsageMsg, s.commandName(), s.commandName())), nil
+	}
+
+	user, err := s.fetchUserInfo(ctx, client, userID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving user info from slack")
+			return CommandResult{}, err
+		}
+	}
+	if !user.IsAdmin && !user.IsOwner {
+		return ephemeral(authAdminMsg), nil
+	}
+	if authenticated && !s.canSign(ctx, cfg) {
+		log.Warn().
+			Str("team_id", teamID).
+			Msg("authenticated urls requested without signing config")
+		return ephemeral(authNoSigningMsg), nil
+	}
+
+	cfg.UnauthenticatedURLs = !authenticated
+	err = s.ServerConfigs.StoreServerConfig(teamID, cfg)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("storing server config")
+		return CommandResult{}, err
+	}
+	if authenticated {
+		return ephemeral(authOnMsg), nil
+	}
+	return ephemeral(authOffMsg), nil
+}
@@ -0,0 +1,105 @@
+package jitsi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSetAuth(t *testing.T) {
+	tests := []struct {
+		name            string
+		admin           bool
+		noPrivateKey    bool
+		stored          ServerConfig
+		text            string
+		want            string
+		unauthenticated bool
+	}{
+		{"show default", true, false, ServerConfig{}, "", authOnMsg, false},
+		{"show off", true, false, ServerConfig{UnauthenticatedURLs: true}, "", authOffMsg, true},
+		{"off", true, false, ServerConfig{}, "off", authOffMsg, true},
+		{"on", true, false, ServerConfig{UnauthenticatedURLs: true}, "on", authOnMsg, false},
+		{"on with team secret", true, true, ServerConfig{UnauthenticatedURLs: true, AppSecret: "team-secret"}, "on", authOnMsg, false},
+		{"on without signing", true, true, ServerConfig{UnauthenticatedURLs: true}, "on", authNoSigningMsg, true},
+		{"off without signing", true, true, ServerConfig{}, "off", authOffMsg, true},
+		{"usage", true, false, ServerConfig{}, "maybe", fmt.Sprintf(authUsageMsg, "/jitsi", "/jitsi"), false},
+		{"not admin", false, false, ServerConfig{}, "off", authAdminMsg, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			if tt.admin {
+				slack.Handle("users.info", adminUserInfo)
+			}
+			configs := &MemoryServerConfigStore{}
+			configs.StoreServerConfig(testTeamID, tt.stored)
+			s := newTestHandlers(t, slack)
+			s.ServerConfigs = configs
+			if tt.noPrivateKey {
+				s.TokenGenerator = TokenGenerator{}
+			}
+
+			_, got := responseOf(t, processCommand(t, s, strings.TrimSpace("auth "+tt.text)))
+			if got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+			cfg, _ := configs.GetServerConfig(testTeamID)
+			if cfg.UnauthenticatedURLs != tt.unauthenticated {
+				t.Errorf("stored unauthenticated urls = %v, want %v", cfg.UnauthenticatedURLs, tt.unauthenticated)
+			}
+		})
+	}
+}
+
+func TestSetAuthUnsupported(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	if _, got := responseOf(t, processCommand(t, s, "auth off")); got != authUnsupportedMsg {
+		t.Errorf("reply = %q, want %q", got, authUnsupportedMsg)
+	}
+}
+
+func TestUnauthenticatedURLsLeaveTokensOff(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{UnauthenticatedURLs: true})
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = configs
+	s.GuestTokens = true
+	s.GroupInviteLimit = 2
+
+	result := processCommand(t, s, "<@UBOB>")
+	if got, want := hostURL(t, result), testConfHost+"/acme/"+result.Room; got != want {
+		t.Errorf("host url = %s, want %s", got, want)
+	}
+	processCommand(t, s, "<@UBOB> <@UALICE>")
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 2 {
+		t.Fatalf("chat.postMessage calls = %d, want an invite and a group invite", len(posted))
+	}
+	for _, call := range posted {
+		if got := inviteURL(t, call); strings.Contains(got, "jwt=") {
+			t.Errorf("invite url = %s, want no token", got)
+		}
+	}
+	guest := processCommand(t, s, "guest")
+	if strings.Contains(guest.Body, "jwt=") {
+		t.Errorf("guest reply = %s, want no token", guest.Body)
+	}
+}
+
+func TestDynamoServerConfigUnauthenticatedURLs(t *testing.T) {
+	_, db := newFakeDynamo(t)
+	store := &DynamoServerConfigStore{TableName: "configs", DB: db}
+	for _, want := range []bool{true, false} {
+		if err := store.StoreServerConfig(testTeamID, ServerConfig{UnauthenticatedURLs: want}); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := store.GetServerConfig(testTeamID)
+		if err != nil || cfg.UnauthenticatedURLs != want {
+			t.Errorf("read %+v, %v, want unauthenticated urls %v", cfg, err, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// KeyCapabilities is the dynamo key for storing the capabilities of a
+// team's server config.
+const KeyCapabilities = "capabilities"
+
+const (
+	capabilityLobby            = "lobby"
+	capabilityGuest            = "guest"
+	capabilityInviteChannel    = "invite-channel"
+	capabilityChannelRoom      = "channel-room"
+	capabilityTemplate         = "template"
+	capabilityChannelMeetings  = "channel-meetings"
+	capabilityReactionMeetings = "reaction-meetings"
+	capabilityWorkflowMeetings = "workflow-meetings"
+
+	capabilityDisabledMsg = "%s is not enabled for this workspace."
+)
+
+// knownCapabilities describes the optional capabilities that can be turned
+// off for a team.
+var knownCapabilities = map[string]string{
+	capabilityLobby:            "Holding invitees in a lobby",
+	capabilityGuest:            "Guest links",
+	capabilityInviteChannel:    "Inviting everyone in a channel",
+	capabilityChannelRoom:      "Channel rooms",
+	capabilityTemplate:         "Meeting templates",
+	capabilityChannelMeetings:  "Posting meetings to channels",
+	capabilityReactionMeetings: "Starting meetings with a reaction",
+	capabilityWorkflowMeetings: "Starting meetings from a workflow",
+}
+
+// subcommandCapabilities are the capabilities subcommands depend on.
+var subcommandCapabilities = map[string]string{
+	subcommandLobby:         capabilityLobby,
+	subcommandGuest:         capabilityGuest,
+	subcommandInviteChannel: capabilityInviteChannel,
+	subcommandChannelRoom:   capabilityChannelRoom,
+	subcommandTemplate:      capabilityTemplate,
+}
+
+// Capabilities turns optional capabilities on or off by name. Capabilities
+// that aren't set are enabled.
+type Capabilities map[string]bool
+
+// Enabled reports whether a capability is enabled.
+func (c Capabilities) Enabled(name string) bool {
+	enabled, ok := c[name]
+	return !ok || enabled
+}
+
+// ParseCapabilities parses capability settings of the form name=on or
+// name=off, separated by spaces or commas.
+func ParseCapabilities(text string) (Capabilities, error) {
+	capabilities := Capabilities{}
+	settings := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	for _, setting := range settings {
+		parts := strings.SplitN(setting, "=", 2)
+		if _, ok := knownCapabilities[parts[0]]; len(parts) != 2 || !ok {
+			return nil, fmt.Errorf("unknown capability %q, use one of %s", setting, strings.Join(capabilityNames(), ", "))
+		}
+		switch parts[1] {
+		case "on":
+			capabilities[parts[0]] = true
+		case "off":
+			capabilities[parts[0]] = false
+		default:
+			return nil, fmt.Errorf("capability %s must be set to on or off", parts[0])
+		}
+	}
+	return capabilities, nil
+}
+
+func capabilityNames() []string {
+	names := map[string]bool{}
+	for name := range knownCapabilities {
+		names[name] = true
+	}
+	return featureNames(names)
+}
+
+// resolveCapabilities applies a team's capabilities over the operator's.
+func (s *SlashCommandHandlers) resolveCapabilities(team Capabilities) Capabilities {
+	resolved := Capabilities{}
+	for name, enabled := range s.Capabilities {
+		resolved[name] = enabled
+	}
+	for name, enabled := range team {
+		resolved[name] = enabled
+	}
+	return resolved
+}
+
+// capabilityDisabled returns the response for a capability that's turned
+// off.
+func capabilityDisabled(name string) CommandResult {
+	return ephemeral(fmt.Sprintf(capabilityDisabledMsg, knownCapabilities[name]))
+}
+
+// subcommandAllowed checks that the capability a subcommand depends on is
+// enabled for a team, returning the response to send when it isn't.
+func (s *SlashCommandHandlers) subcommandAllowed(ctx context.Context, teamID, subcommand string) (CommandResult, bool, error) {
+	name, ok := subcommandCapabilities[subcommand]
+	if !ok {
+		return CommandResult{}, true, nil
+	}
+	cfg, err := s.teamServerConfig(ctx, teamID)
+	if err != nil {
+		return CommandResult{}, false, err
+	}
+	if !cfg.Capabilities.Enabled(name) {
+		return capabilityDisabled(name), false, nil
+	}
+	return CommandResult{}, true, nil
+}
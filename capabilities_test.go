@@ -0,0 +1,83 @@
+package jitsi
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	got, err := ParseCapabilities("guest=off, template=on lobby=off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Capabilities{capabilityGuest: false, capabilityTemplate: true, capabilityLobby: false}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("capabilities = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"scheduling=off", "guest", "guest=maybe"} {
+		if _, err := ParseCapabilities(bad); err == nil {
+			t.Errorf("ParseCapabilities(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestCapabilitiesEnabledByDefault(t *testing.T) {
+	var unset Capabilities
+	for name := range knownCapabilities {
+		if !unset.Enabled(name) {
+			t.Errorf("%s disabled without being set", name)
+		}
+	}
+}
+
+func TestDisabledCapabilityResponses(t *testing.T) {
+	tests := []struct {
+		text       string
+		capability string
+	}{
+		{"guest", capabilityGuest},
+		{"lobby <@UBOB>", capabilityLobby},
+		{"invite-channel", capabilityInviteChannel},
+		{"channel-room", capabilityChannelRoom},
+		{"template list", capabilityTemplate},
+		{"", capabilityChannelMeetings},
+	}
+	for _, tt := range tests {
+		t.Run(tt.capability, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			s := newTestHandlers(t, slack)
+			s.ChannelRooms = &MemoryChannelRoomStore{}
+			s.Templates = &MemoryTemplateStore{}
+			s.ServerConfigs = &MemoryServerConfigStore{}
+			s.ServerConfigs.StoreServerConfig(testTeamID, ServerConfig{
+				Capabilities: Capabilities{tt.capability: false},
+			})
+
+			result := processCommand(t, s, tt.text)
+			want := fmt.Sprintf(capabilityDisabledMsg, knownCapabilities[tt.capability])
+			if _, got := responseOf(t, result); got != want {
+				t.Errorf("reply = %q, want %q", got, want)
+			}
+			if calls := slack.Calls("chat.postMessage"); len(calls) != 0 {
+				t.Errorf("posted %d messages for a disabled capability", len(calls))
+			}
+		})
+	}
+}
+
+func TestTeamCapabilitiesOverrideOperator(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.Capabilities = Capabilities{capabilityGuest: false, capabilityChannelMeetings: false}
+	s.ServerConfigs = &MemoryServerConfigStore{}
+	s.ServerConfigs.StoreServerConfig(testTeamID, ServerConfig{
+		Capabilities: Capabilities{capabilityGuest: true},
+	})
+
+	if result := processCommand(t, s, "guest"); result.Room == "" {
+		t.Errorf("guest = %s, want a guest link the team enabled", result.Body)
+	}
+	want := fmt.Sprintf(capabilityDisabledMsg, knownCapabilities[capabilityChannelMeetings])
+	if _, got := responseOf(t, processCommand(t, s, "")); got != want {
+		t.Errorf("reply = %q, want the operator's default %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package jitsi
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	recentMeetingMsg = "A meeting was just started in this channel, join it at <%s|%s>."
+	// recentMeetingReplaceTemplate replaces a confirmation with the recent
+	// meeting message.
+	recentMeetingReplaceTemplate = `{"response_type":"ephemeral","replace_original":true,"text":%s}`
+)
+
+type recentMeeting struct {
+	meetingURL string
+	expires    time.Time
+}
+
+// ChannelDebounce remembers the meeting last posted to each channel for
+// Window so people running the command at the same time join one meeting
+// rather than each posting their own.
+type ChannelDebounce struct {
+	Window time.Duration
+
+	mu       sync.Mutex
+	meetings map[string]recentMeeting
+}
+
+// Recent returns the url of the meeting posted to a channel within the
+// Window with true, or false when there isn't one.
+func (d *ChannelDebounce) Recent(teamID, channelID string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	recent, ok := d.meetings[channelKey(teamID, channelID)]
+	if !ok || !time.Now().Before(recent.expires) {
+		return "", false
+	}
+	return recent.meetingURL, true
+}
+
+// Start records meetingURL as the meeting posted to a channel and returns
+// false, unless a meeting was posted there within the Window in which case
+// its url is returned with true and nothing is recorded. It's called as the
+// meeting is posted so a meeting that's never posted isn't recorded.
+func (d *ChannelDebounce) Start(teamID, channelID, meetingURL string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	key := channelKey(teamID, channelID)
+	if recent, ok := d.meetings[key]; ok && now.Before(recent.expires) {
+		return recent.meetingURL, true
+	}
+	if d.meetings == nil {
+		d.meetings = map[string]recentMeeting{}
+	}
+	// Expired meetings are dropped so quiet channels aren't kept forever.
+	for k, recent := range d.meetings {
+		if !now.Before(recent.expires) {
+			delete(d.meetings, k)
+		}
+	}
+	d.meetings[key] = recentMeeting{
+		meetingURL: meetingURL,
+		expires:    now.Add(d.Window),
+	}
+	return "", false
+}
+
+// Record records meetingURL as the meeting posted to a channel, replacing
+// any recent meeting. It's for meetings posted after a Recent check, such as
+// a confirmed post to a large channel.
+func (d *ChannelDebounce) Record(teamID, channelID, meetingURL string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.meetings == nil {
+		d.meetings = map[string]recentMeeting{}
+	}
+	d.meetings[channelKey(teamID, channelID)] = recentMeeting{
+		meetingURL: meetingURL,
+		expires:    time.Now().Add(d.Window),
+	}
+}
+
+// recentMeetingResult points the caller to the meeting recently posted to
+// the channel.
+func recentMeetingResult(meetingURL string) CommandResult {
+	return ephemeral(fmt.Sprintf(recentMeetingMsg, meetingURL, path.Base(meetingURL)))
+}
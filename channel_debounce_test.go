@@ -0,0 +1,43 @@
+package jitsi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChannelDebounceRecordsOnlyPostedMeetings(t *testing.T) {
+	d := &ChannelDebounce{Window: time.Minute}
+
+	// A confirmation that's never accepted only checks for a recent meeting.
+	if _, ok := d.Recent("T1", "C1"); ok {
+		t.Fatal("new channel has a recent meeting")
+	}
+	if _, ok := d.Recent("T1", "C1"); ok {
+		t.Fatal("unposted meeting was recorded")
+	}
+
+	d.Record("T1", "C1", "https://meet.example.com/acme/Posted")
+	got, ok := d.Recent("T1", "C1")
+	if !ok || got != "https://meet.example.com/acme/Posted" {
+		t.Errorf("Recent = %q, %v, want the recorded meeting", got, ok)
+	}
+	got, ok = d.Start("T1", "C1", "https://meet.example.com/acme/Other")
+	if !ok || got != "https://meet.example.com/acme/Posted" {
+		t.Errorf("Start = %q, %v, want the recorded meeting", got, ok)
+	}
+	if _, ok := d.Recent("T1", "C2"); ok {
+		t.Error("meeting was recorded for another channel")
+	}
+}
+
+func TestChannelDebounceExpires(t *testing.T) {
+	d := &ChannelDebounce{Window: time.Millisecond}
+	d.Record("T1", "C1", "https://meet.example.com/acme/Posted")
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := d.Recent("T1", "C1"); ok {
+		t.Error("expired meeting is still recent")
+	}
+	if _, ok := d.Start("T1", "C1", "https://meet.example.com/acme/Next"); ok {
+		t.Error("expired meeting blocked a new one")
+	}
+}
@@ -0,0 +1,118 @@
+package jitsi
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// KeyChannel is the dynamo key for storing the team and channel id of
+	// a channel room. This is the primary.
+	KeyChannel = "channel"
+	// KeyRoom is the dynamo key for storing the room name of a channel room.
+	KeyRoom = "room"
+
+	channelRoomReset = "reset"
+
+	channelRoomUnsupportedMsg = "Channel rooms are not supported by this installation."
+)
+
+// ChannelRoomStore provides an interface for reading and writing the
+// standing room of a channel. An empty room is returned for channels
+// without one.
+type ChannelRoomStore interface {
+	GetChannelRoom(teamID, channelID string) (string, error)
+	StoreChannelRoom(teamID, channelID, room string) error
+}
+
+func channelKey(teamID, channelID string) string {
+	return teamID + "/" + channelID
+}
+
+// MemoryChannelRoomStore stores channel rooms in memory. Rooms that have
+// been reset are lost on restart, channels then return to the room named
+// after the channel.
+type MemoryChannelRoomStore struct {
+	mu    sync.RWMutex
+	rooms map[string]string
+}
+
+// GetChannelRoom retrieves the room stored for a channel.
+func (m *MemoryChannelRoomStore) GetChannelRoom(teamID, channelID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.rooms[channelKey(teamID, channelID)], nil
+}
+
+// StoreChannelRoom stores the room for a channel.
+func (m *MemoryChannelRoomStore) StoreChannelRoom(teamID, channelID, room string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rooms == nil {
+		m.rooms = map[string]string{}
+	}
+	m.rooms[channelKey(teamID, channelID)] = room
+	return nil
+}
+
+// DynamoChannelRoomStore stores and retrieves channel rooms from aws dynamodb.
+type DynamoChannelRoomStore struct {
+	TableName string
+	DB        *dynamodb.DynamoDB
+}
+
+// GetChannelRoom retrieves the room stored for a channel.
+func (d *DynamoChannelRoomStore) GetChannelRoom(teamID, channelID string) (string, error) {
+	result, err := d.DB.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			KeyChannel: {
+				S: aws.String(channelKey(teamID, channelID)),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	room, ok := result.Item[KeyRoom]
+	if !ok || room.S == nil {
+		return "", nil
+	}
+	return *room.S, nil
+}
+
+// StoreChannelRoom stores the room for a channel.
+func (d *DynamoChannelRoomStore) StoreChannelRoom(teamID, channelID, room string) error {
+	_, err := d.DB.PutItem(&dynamodb.PutItemInput{
+		Item: map[string]*dynamodb.AttributeValue{
+			KeyChannel: {
+				S: aws.String(channelKey(teamID, channelID)),
+			},
+			KeyRoom: {
+				S: aws.String(room),
+			},
+		},
+		TableName: aws.String(d.TableName),
+	})
+	return err
+}
+
+// channelRoom returns the standing room of a channel. A channel's first
+// room is named after the channel. Resetting it stores a new random room
+// that's reused until the next reset.
+func (s *SlashCommandHandlers) channelRoom(teamID, channelID, text string) (string, error) {
+	if args := strings.Fields(text); len(args) > 0 && args[0] == channelRoomReset {
+		room := s.roomName(RandomName())
+		return room, s.ChannelRooms.StoreChannelRoom(teamID, channelID, room)
+	}
+
+	room, err := s.ChannelRooms.GetChannelRoom(teamID, channelID)
+	if err != nil || room != "" {
+		return room, err
+	}
+	room = s.roomName(ChannelName(teamID, channelID))
+	return room, s.ChannelRooms.StoreChannelRoom(teamID, channelID, room)
+}
@@ -0,0 +1,61 @@
+package jitsi
+
+import (
+	"testing"
+)
+
+func TestChannelRoomIsReused(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ChannelRooms = &MemoryChannelRoomStore{}
+
+	first := processCommand(t, s, "channel-room").Room
+	if want := ChannelName(testTeamID, "C1"); first != want {
+		t.Errorf("room = %q, want %q named after the channel", first, want)
+	}
+	if again := processCommand(t, s, "channel-room").Room; again != first {
+		t.Errorf("room = %q, want the channel's room %q again", again, first)
+	}
+	if ChannelName(testTeamID, "C2") == first {
+		t.Error("channels share a room name")
+	}
+}
+
+func TestChannelRoomReset(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ChannelRooms = &MemoryChannelRoomStore{}
+
+	first := processCommand(t, s, "channel-room").Room
+	reset := processCommand(t, s, "channel-room reset").Room
+	if reset == first {
+		t.Errorf("reset kept room %q, want a new one", reset)
+	}
+	if again := processCommand(t, s, "channel-room").Room; again != reset {
+		t.Errorf("room = %q, want the reset room %q", again, reset)
+	}
+}
+
+func TestChannelRoomUnsupported(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+
+	if _, got := responseOf(t, processCommand(t, s, "channel-room")); got != channelRoomUnsupportedMsg {
+		t.Errorf("reply = %q, want %q", got, channelRoomUnsupportedMsg)
+	}
+}
+
+func TestDynamoChannelRoomStore(t *testing.T) {
+	_, db := newFakeDynamo(t)
+	store := &DynamoChannelRoomStore{TableName: "rooms", DB: db}
+
+	if room, err := store.GetChannelRoom(testTeamID, "C1"); room != "" || err != nil {
+		t.Fatalf("GetChannelRoom = %q, %v, want no room", room, err)
+	}
+	if err := store.StoreChannelRoom(testTeamID, "C1", "StandingRoom"); err != nil {
+		t.Fatal(err)
+	}
+	if room, err := store.GetChannelRoom(testTeamID, "C1"); room != "StandingRoom" || err != nil {
+		t.Errorf("GetChannelRoom = %q, %v, want StandingRoom", room, err)
+	}
+	if room, _ := store.GetChannelRoom(testTeamID, "C2"); room != "" {
+		t.Errorf("GetChannelRoom of another channel = %q, want none", room)
+	}
+}
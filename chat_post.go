@@ -0,0 +1,121 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+type postMessageResponse struct {
+	OK               bool   `json:"ok"`
+	Error            string `json:"error"`
+	Warning          string `json:"warning"`
+	Channel          string `json:"channel"`
+	TS               string `json:"ts"`
+	ResponseMetadata struct {
+		Warnings []string `json:"warnings"`
+	} `json:"response_metadata"`
+}
+
+// warnings returns the distinct warnings from both the legacy warning field
+// and the response metadata.
+func (r postMessageResponse) warnings() []string {
+	var warnings []string
+	seen := map[string]bool{}
+	all := append(strings.Split(r.Warning, ","), r.ResponseMetadata.Warnings...)
+	for _, warning := range all {
+		warning = strings.TrimSpace(warning)
+		if warning == "" || seen[warning] {
+			continue
+		}
+		seen[warning] = true
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// PostWarningError reports a message that Slack posted with warnings, i.e.
+// with text truncated or actions dropped. The message was still delivered.
+type PostWarningError struct {
+	Channel  string
+	Warnings []string
+}
+
+func (e *PostWarningError) Error() string {
+	return "message posted with warnings: " + strings.Join(e.Warnings, ", ")
+}
+
+// delivered reports whether a message posted with postMessage was
+// delivered, which it was when it's posted with warnings.
+func delivered(err error) bool {
+	var warning *PostWarningError
+	return err == nil || errors.As(err, &warning)
+}
+
+// postMessage posts a message with chat.postMessage and returns its ts. The
+// slack client drops the warnings Slack includes in its response, so the
+// call is made directly to log them and return them as a PostWarningError
+// along with the ts.
+func (s *SlashCommandHandlers) postMessage(ctx context.Context, token, channelID string, params slack.PostMessageParameters) (string, error) {
+	attachments, err := json.Marshal(params.Attachments)
+	if err != nil {
+		return "", err
+	}
+	values := url.Values{
+		"token":       {token},
+		"channel":     {channelID},
+		"attachments": {string(attachments)},
+	}
+	if params.Username != "" {
+		values.Set("username", params.Username)
+	}
+	if params.IconURL != "" {
+		values.Set("icon_url", params.IconURL)
+	}
+	if params.IconEmoji != "" {
+		values.Set("icon_emoji", params.IconEmoji)
+	}
+
+	var posted postMessageResponse
+	err = s.callSlackPost(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodPost, slackPostMessageURL, strings.NewReader(values.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		resp, err := httpClientOrDefault(s.HTTPClient).Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := rateLimited(resp); err != nil {
+			return err
+		}
+		posted = postMessageResponse{}
+		return json.NewDecoder(resp.Body).Decode(&posted)
+	})
+	if err != nil {
+		return "", err
+	}
+	if !posted.OK {
+		return "", errors.New(posted.Error)
+	}
+
+	warnings := posted.warnings()
+	if len(warnings) > 0 {
+		zerolog.Ctx(ctx).Warn().
+			Str("channel", channelID).
+			Strs("warnings", warnings).
+			Msg("message posted with warnings")
+		return posted.TS, &PostWarningError{Channel: channelID, Warnings: warnings}
+	}
+	return posted.TS, nil
+}
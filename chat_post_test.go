@@ -0,0 +1,54 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nlopes/slack"
+)
+
+const testWarningResponse = `{"ok":true,"channel":"D1","ts":"1500000000.000200","warning":"superfluous_charset","response_metadata":{"warnings":["superfluous_charset","missing_attachment_fallback"]}}`
+
+func TestPostMessageReturnsWarnings(t *testing.T) {
+	fake := newFakeSlack(t)
+	fake.Handle("chat.postMessage", testWarningResponse)
+	s := newTestHandlers(t, fake)
+
+	ts, err := s.postMessage(context.Background(), testBotToken, "D1", slack.PostMessageParameters{})
+	var warning *PostWarningError
+	if !errors.As(err, &warning) {
+		t.Fatalf("postMessage = %v, want a PostWarningError", err)
+	}
+	if want := []string{"superfluous_charset", "missing_attachment_fallback"}; !reflect.DeepEqual(warning.Warnings, want) || warning.Channel != "D1" {
+		t.Errorf("warning = %+v, want %v for D1", warning, want)
+	}
+	if ts != "1500000000.000200" || !delivered(err) {
+		t.Errorf("postMessage = %q, delivered %v, want the message delivered", ts, delivered(err))
+	}
+}
+
+func TestPostMessageErrors(t *testing.T) {
+	fake := newFakeSlack(t)
+	fake.Handle("chat.postMessage", `{"ok":false,"error":"channel_not_found"}`)
+	s := newTestHandlers(t, fake)
+
+	_, err := s.postMessage(context.Background(), testBotToken, "D1", slack.PostMessageParameters{})
+	if err == nil || err.Error() != "channel_not_found" || delivered(err) {
+		t.Errorf("postMessage = %v, want an undelivered channel_not_found", err)
+	}
+}
+
+func TestInvitesPostedWithWarningsAreDelivered(t *testing.T) {
+	fake := newFakeSlack(t)
+	fake.AddUser("UBOB", "bob")
+	fake.Handle("chat.postMessage", testWarningResponse)
+	s := newTestHandlers(t, fake)
+
+	result := processCommand(t, s, "<@UBOB>")
+	if strings.Contains(result.Body, "couldn't be delivered") || !strings.Contains(result.Body, "Invitations have been sent") {
+		t.Errorf("reply = %s, want the invite reported delivered", result.Body)
+	}
+}
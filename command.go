@@ -0,0 +1,627 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+// CommandInput is a slash command parsed from the transport it arrived on.
+type CommandInput struct {
+	TeamID    string
+	TeamName  string
+	UserID    string
+	ChannelID string
+	Text      string
+}
+
+// CommandResult is the response to a slash command.
+type CommandResult struct {
+	// Body is the json encoded Slack message to respond with.
+	Body string
+	// Room is the name of the room created by the command, if any.
+	Room string
+}
+
+// ephemeral creates a plain text response only visible to the caller.
+func ephemeral(msg string) CommandResult {
+	text, _ := json.Marshal(msg)
+	return CommandResult{Body: fmt.Sprintf(ephemeralTemplate, text)}
+}
+
+func install(installURL string) CommandResult {
+	return CommandResult{Body: fmt.Sprintf(installMessage, installURL)}
+}
+
+// whoami reports the identifiers of a request for support purposes. Only the
+// presence of a bot token is reported, never the token itself.
+func (s *SlashCommandHandlers) whoami(ctx context.Context, teamID, userID, channelID string) CommandResult {
+	installed := "yes"
+	token, err := s.botToken(ctx, teamID)
+	switch {
+	case err != nil && err.Error() == errMissingAuthToken:
+		installed = "no"
+	case err != nil:
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("retrieving token")
+		installed = "unknown"
+	case token == "":
+		installed = "no"
+	}
+
+	confHost := "unknown"
+	if cfg, err := s.teamServerConfig(ctx, teamID); err == nil {
+		confHost = cfg.ConferenceHost
+	}
+	return CommandResult{
+		Body: fmt.Sprintf(whoamiTemplate, teamID, userID, channelID, installed, confHost),
+	}
+}
+
+// logMeetingURL records whether a meeting url carries a token so adoption
+// of authenticated urls can be measured per team.
+func logMeetingURL(ctx context.Context, teamID string, authenticated bool) {
+	zerolog.Ctx(ctx).Info().
+		Str("team_id", teamID).
+		Bool("authenticated_url", authenticated).
+		Msg("meeting url generated")
+}
+
+// startedText formats when a meeting started with a Slack date token so
+// each viewer sees the time in their own timezone. The fallback is shown by
+// clients that can't render the token.
+func startedText(started time.Time) string {
+	return fmt.Sprintf(
+		"Started <!date^%d^{time}|%s>",
+		started.Unix(),
+		started.UTC().Format("3:04 PM UTC"),
+	)
+}
+
+// mentions formats user ids as a list of Slack mentions.
+func mentions(userIDs []string) string {
+	formatted := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		formatted[i] = fmt.Sprintf("<@%s>", userID)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func (s *SlashCommandHandlers) maintenanceMessage() string {
+	if s.MaintenanceMessage == "" {
+		return defaultMaintenanceMessage
+	}
+	return s.MaintenanceMessage
+}
+
+func (s *SlashCommandHandlers) maintenance() CommandResult {
+	return ephemeral(s.maintenanceMessage())
+}
+
+// errBotInvitee is returned by inviteUser for bots, which can't join a
+// meeting so they aren't sent an invite.
+var errBotInvitee = errors.New("bots can't join meetings")
+
+func (s *SlashCommandHandlers) inviteUser(ctx context.Context, client *slack.Client, token string, serverCfg ServerConfig, hostID, userID, channelID, teamID, teamName, room string, lobby bool, features map[string]bool, maxOccupants int) error {
+	userInfo, err := s.userInfo(ctx, client, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if userInfo.IsBot {
+		return errBotInvitee
+	}
+	confURL, err := s.conferenceURL(ctx, serverCfg, JWTInput{
+		TenantID:     strings.ToLower(teamID),
+		TenantName:   strings.ToLower(teamName),
+		RoomClaim:    room,
+		UserID:       userInfo.ID,
+		UserName:     s.conferenceName(userInfo),
+		AvatarURL:    userInfo.Profile.Image192,
+		Lobby:        lobby,
+		Features:     features,
+		MaxOccupants: maxOccupants,
+		Lifetime:     serverCfg.MeetingDuration,
+		AppSecret:    serverCfg.AppSecret,
+	})
+	if err != nil {
+		return err
+	}
+	logMeetingURL(ctx, teamID, serverCfg.authenticatedURLs())
+	params, err := s.inviteMessage(ctx, hostID, serverCfg.ConferenceHost, strings.ToLower(teamName), room, s.shorten(ctx, confURL), serverCfg.MeetingDuration)
+	if err != nil {
+		return err
+	}
+
+	if s.EphemeralInvites && channelID != "" {
+		err = s.postEphemeralInvite(ctx, client, channelID, userID, params)
+		if err == nil {
+			return nil
+		}
+		// Invites fall back to a DM when the channel can't be posted to.
+		switch err.Error() {
+		case errNotInChannel, errChannelNotFound, errUserNotInChannel:
+		default:
+			return err
+		}
+	}
+
+	var channel *slack.Channel
+	err = s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		channel, _, _, err = client.OpenConversationContext(
+			ctx,
+			&slack.OpenConversationParameters{
+				Users: []string{userID},
+			},
+		)
+		return err
+	})
+	var ts string
+	if err == nil {
+		ts, err = s.postMessage(ctx, token, channel.ID, params)
+	}
+	if delivered(err) {
+		s.recordInvite(teamID, channel.ID, ts, serverCfg.MeetingDuration)
+		return nil
+	}
+	if dmBlockedReasons[err.Error()] == "" {
+		return err
+	}
+	// Users that can't be sent a DM may still be reachable in the channel.
+	if s.DMFallbackEphemeral && channelID != "" && !s.EphemeralInvites {
+		if s.postEphemeralInvite(ctx, client, channelID, userID, params) == nil {
+			return nil
+		}
+	}
+	return &DMBlockedError{UserID: userID, Code: err.Error()}
+}
+
+// postEphemeralInvite posts an invite in a channel visible only to the
+// invitee.
+func (s *SlashCommandHandlers) postEphemeralInvite(ctx context.Context, client *slack.Client, channelID, userID string, params slack.PostMessageParameters) error {
+	return s.callSlackPost(ctx, func(ctx context.Context) error {
+		_, err := client.PostEphemeralContext(
+			ctx,
+			channelID,
+			userID,
+			slack.MsgOptionPostMessageParameters(params),
+			slack.MsgOptionAttachments(params.Attachments...),
+		)
+		return err
+	})
+}
+
+// inviteMessage creates the invite message with a join button for confURL.
+func (s *SlashCommandHandlers) inviteMessage(ctx context.Context, hostID, confHost, tenant, room, confURL string, lifetime time.Duration) (slack.PostMessageParameters, error) {
+	params := slack.PostMessageParameters{
+		Username:  s.InviteIdentity.Username,
+		IconURL:   s.InviteIdentity.IconURL,
+		IconEmoji: s.InviteIdentity.IconEmoji,
+	}
+	msg, err := s.inviteText(hostID, confHost, room)
+	if err != nil {
+		return params, err
+	}
+	attachment := slack.Attachment{
+		Fallback: fallback(s.Fallbacks.Invite, s.inviteTextTemplate(), hostID, confHost, room),
+		Title:    truncate(s.titlePrefix()+msg, maxTitleLength),
+		Text:     s.linkExpiry(time.Now(), lifetime),
+		Color:    "#3AA3E3",
+		Actions: []slack.AttachmentAction{
+			slack.AttachmentAction{
+				Name:  "join",
+				Text:  "Join",
+				Type:  "button",
+				Style: "primary",
+				URL:   confURL,
+			},
+		},
+	}
+	if dialIn := s.dialInField(ctx, confHost, tenant, room); dialIn != nil {
+		attachment.Fields = append(attachment.Fields, *dialIn)
+	}
+	if s.InviteAcks != nil {
+		attachment.CallbackID = actionAcknowledgeInvite
+		attachment.Actions = append(attachment.Actions, slack.AttachmentAction{
+			Name:  actionAcknowledgeInvite,
+			Text:  "I'll join",
+			Type:  "button",
+			Value: inviteAckValue(hostID, room),
+		})
+	}
+	params.Attachments = []slack.Attachment{attachment}
+	return params, nil
+}
+
+// channelMembers lists every member of a channel.
+func (s *SlashCommandHandlers) channelMembers(ctx context.Context, client *slack.Client, channelID string) ([]string, error) {
+	var members []string
+	cursor := ""
+	for {
+		var page []string
+		var next string
+		err := s.callSlack(ctx, func(ctx context.Context) error {
+			var err error
+			page, next, err = client.GetUsersInConversationContext(
+				ctx,
+				&slack.GetUsersInConversationParameters{
+					ChannelID: channelID,
+					Cursor:    cursor,
+					Limit:     200,
+				},
+			)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, page...)
+		if next == "" {
+			return members, nil
+		}
+		cursor = next
+	}
+}
+
+// ProcessCommand creates a conference for a slash command and dispatches
+// invites to any mentioned users. It is independent of the transport the
+// command arrived on. Errors are logged with the logger from ctx before
+// they're returned.
+func (s *SlashCommandHandlers) ProcessCommand(ctx context.Context, in CommandInput) (CommandResult, error) {
+	log := zerolog.Ctx(ctx)
+	if in.TeamName == "" {
+		in.TeamName = s.storedTeamDomain(ctx, in.TeamID)
+	}
+
+	cmd, err := ParseCommand(in.Text)
+	if err != nil {
+		return ephemeral(err.Error()), nil
+	}
+	subcommand, text := cmd.Subcommand, cmd.Text
+	if result, ok, err := s.subcommandAllowed(ctx, in.TeamID, subcommand); !ok {
+		return result, err
+	}
+	switch subcommand {
+	case subcommandHelp:
+		return s.help(ctx, in.TeamID)
+	case subcommandWhoami:
+		return s.whoami(ctx, in.TeamID, in.UserID, in.ChannelID), nil
+	case subcommandWho:
+		return s.who(ctx, in.TeamName, text), nil
+	case subcommandTemplate:
+		return s.template(ctx, in, text)
+	}
+
+	if s.MaintenanceMode {
+		return s.maintenance(), nil
+	}
+	features, err := s.teamFeatures(in.TeamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving features")
+		return CommandResult{}, err
+	}
+	lobby := s.LobbyEnabled || subcommand == subcommandLobby
+	maxOccupants, err := s.maxOccupants(cmd)
+	if err != nil {
+		return ephemeral(err.Error()), nil
+	}
+
+	// Grab an access token before any Slack api use
+	// so we can fail early if we don't have one.
+	token, err := s.botToken(ctx, in.TeamID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving token")
+			return CommandResult{}, err
+		}
+	}
+
+	slackClient := slack.New(token, slack.OptionHTTPClient(httpClientOrDefault(s.HTTPClient)))
+	if subcommand == subcommandFeatures {
+		return s.features(ctx, slackClient, in.TeamID, in.UserID, text)
+	}
+	if subcommand == subcommandTokens {
+		return s.tokens(ctx, slackClient, in.TeamID, in.UserID, text)
+	}
+	if subcommand == subcommandSetDuration {
+		return s.setDuration(ctx, slackClient, in.TeamID, in.UserID, text)
+	}
+	if subcommand == subcommandAuth {
+		return s.setAuth(ctx, slackClient, in.TeamID, in.UserID, text)
+	}
+	if subcommand == subcommandExport || subcommand == subcommandImport {
+		return s.transferConfig(ctx, slackClient, in.TeamID, in.UserID, subcommand, text)
+	}
+	if subcommand == subcommandCopyConfig {
+		return s.copyConfig(ctx, slackClient, in.TeamID, in.UserID, text)
+	}
+
+	allowed, err := s.canHost(ctx, slackClient, in.TeamID, in.UserID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("checking host policy")
+			return CommandResult{}, err
+		}
+	}
+	if !allowed {
+		return ephemeral(notHostMsg), nil
+	}
+	if subcommand == subcommandGuest {
+		return s.guest(ctx, in.TeamID, in.TeamName, features, maxOccupants)
+	}
+
+	activeOnly := cmd.Flags[flagActiveOnly]
+	if cmd.Flags[flagPublic] && cmd.Flags[flagPrivate] {
+		return ephemeral(publicPrivateMsg), nil
+	}
+	private := (s.PrivateMeetings || cmd.Flags[flagPrivate]) && !cmd.Flags[flagPublic]
+	room := s.roomName(RandomName())
+	if subcommand == subcommandChannelRoom {
+		if s.ChannelRooms == nil {
+			return ephemeral(channelRoomUnsupportedMsg), nil
+		}
+		room, err = s.channelRoom(in.TeamID, in.ChannelID, text)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("retrieving channel room")
+			return CommandResult{}, err
+		}
+	}
+	serverCfg, err := s.teamServerConfig(ctx, in.TeamID)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	confHost, lifetime := serverCfg.ConferenceHost, serverCfg.MeetingDuration
+	if !serverCfg.Capabilities.Enabled(capabilityLobby) {
+		lobby = false
+	}
+	// The caller joins with their own link and this app's bot can't join,
+	// so neither is ever invited. Other bots are skipped by inviteUser and
+	// inviteGroup.
+	botUserID := s.storedBotUserID(ctx, in.TeamID)
+	var invitees, bots []string
+	selfMentioned := false
+	for _, mention := range cmd.Mentions {
+		switch {
+		case mention == in.UserID:
+			selfMentioned = true
+		case mention == botUserID:
+			bots = append(bots, mention)
+		default:
+			invitees = append(invitees, mention)
+		}
+	}
+	if len(invitees) == 0 && subcommand != subcommandInviteChannel {
+		if len(bots) > 0 {
+			return ephemeral(botInviteMsg), nil
+		}
+		if selfMentioned {
+			return ephemeral(selfInviteMsg), nil
+		}
+	}
+	if subcommand == subcommandInviteChannel {
+		members, err := s.channelMembers(ctx, slackClient, in.ChannelID)
+		if err != nil {
+			switch err.Error() {
+			case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+				return install(s.installURL()), nil
+			default:
+				log.Error().
+					Err(err).
+					Msg("listing channel members")
+				return CommandResult{}, err
+			}
+		}
+		var others []string
+		for _, member := range members {
+			if member != in.UserID && member != botUserID {
+				others = append(others, member)
+			}
+		}
+		if s.MaxChannelInvitees > 0 && len(others) > s.MaxChannelInvitees {
+			return ephemeral(fmt.Sprintf(inviteChannelTooLargeMsg, len(others), s.MaxChannelInvitees)), nil
+		}
+		confirmed := strings.TrimSpace(text) == inviteChannelConfirmed
+		if !confirmed && s.ConfirmChannelSize > 0 && len(members) >= s.ConfirmChannelSize {
+			return confirmInviteChannel(len(members), activeOnly), nil
+		}
+		invitees = append(invitees, others...)
+		if len(invitees) == 0 {
+			return ephemeral(inviteChannelEmptyMsg), nil
+		}
+	}
+
+	var notes []string
+	if selfMentioned {
+		notes = append(notes, selfInviteIgnoredMsg)
+	}
+	if activeOnly && len(invitees) > 0 {
+		active, away, err := s.activeUsers(ctx, slackClient, in.TeamID, invitees)
+		if err != nil {
+			switch err.Error() {
+			case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+				return install(s.installURL()), nil
+			default:
+				log.Error().
+					Err(err).
+					Msg("retrieving user presence from slack")
+				return CommandResult{}, err
+			}
+		}
+		if len(active) == 0 {
+			return ephemeral(fmt.Sprintf(noActiveInviteesMsg, mentions(away))), nil
+		}
+		if len(away) > 0 {
+			notes = append(notes, fmt.Sprintf(awayInviteesMsg, mentions(away)))
+		}
+		invitees = active
+	}
+
+	if len(invitees) == 0 {
+		meetingURL := fmt.Sprintf(
+			"%s/%s/%s",
+			confHost,
+			strings.ToLower(in.TeamName),
+			room,
+		)
+		started := time.Now()
+		if private {
+			// Only the caller sees the meeting, to share as they like.
+			logMeetingURL(ctx, in.TeamID, false)
+			return CommandResult{
+				Body: fmt.Sprintf(roomTemplate, meetingURL, room, startedText(started), s.titlePrefix(), jsonFallback(s.Fallbacks.Room, defaultRoomFallback, in.UserID, confHost, room), "ephemeral"),
+				Room: room,
+			}, nil
+		}
+		if !serverCfg.Capabilities.Enabled(capabilityChannelMeetings) {
+			return capabilityDisabled(capabilityChannelMeetings), nil
+		}
+		debounce := s.ChannelDebounce != nil && in.ChannelID != ""
+		if debounce {
+			if recentURL, ok := s.ChannelDebounce.Recent(in.TeamID, in.ChannelID); ok {
+				return recentMeetingResult(recentURL), nil
+			}
+		}
+		logMeetingURL(ctx, in.TeamID, false)
+		if s.largeChannel(ctx, slackClient, in.ChannelID) {
+			// The meeting is recorded once it's confirmed and posted.
+			result := confirmBroadcast(meetingURL, started)
+			result.Room = room
+			return result, nil
+		}
+		if debounce {
+			if recentURL, ok := s.ChannelDebounce.Start(in.TeamID, in.ChannelID, meetingURL); ok {
+				return recentMeetingResult(recentURL), nil
+			}
+		}
+		return CommandResult{
+			Body: fmt.Sprintf(roomTemplate, meetingURL, room, startedText(started), s.titlePrefix(), jsonFallback(s.Fallbacks.Room, defaultRoomFallback, in.UserID, confHost, room), "in_channel"),
+			Room: room,
+		}, nil
+	}
+
+	if s.groupInvite(len(invitees)) {
+		var skipped []string
+		skipped, err = s.inviteGroup(ctx, slackClient, token, serverCfg, in.UserID, invitees, in.TeamID, in.TeamName, room, lobby, features, maxOccupants)
+		bots = append(bots, skipped...)
+		if err != nil {
+			switch err.Error() {
+			case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+				return install(s.installURL()), nil
+			default:
+				log.Error().
+					Err(err).
+					Msg("inviting group")
+				notes = append(notes, fmt.Sprintf(failedInvitesMsg, mentions(invitees)))
+			}
+		}
+	} else {
+		// Failed invites are reported per invitee so the caller knows who
+		// to share the meeting with themselves.
+		var delivered, failed []string
+		var blocked []*DMBlockedError
+		for _, invitee := range invitees {
+			err = s.inviteUser(ctx, slackClient, token, serverCfg, in.UserID, invitee, in.ChannelID, in.TeamID, in.TeamName, room, lobby, features, maxOccupants)
+			if err == errBotInvitee {
+				bots = append(bots, invitee)
+				continue
+			}
+			var blockedErr *DMBlockedError
+			if errors.As(err, &blockedErr) {
+				blocked = append(blocked, blockedErr)
+				continue
+			}
+			if err != nil {
+				switch err.Error() {
+				case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+					return install(s.installURL()), nil
+				default:
+					log.Error().
+						Err(err).
+						Msg("inviting user")
+					failed = append(failed, invitee)
+					continue
+				}
+			}
+			delivered = append(delivered, invitee)
+		}
+		if len(failed) > 0 || len(blocked) > 0 {
+			if len(delivered) > 0 {
+				notes = append(notes, fmt.Sprintf(deliveredInvitesMsg, mentions(delivered)))
+			}
+			if len(failed) > 0 {
+				notes = append(notes, fmt.Sprintf(failedInvitesMsg, mentions(failed)))
+			}
+			notes = append(notes, dmBlockedNotes(blocked)...)
+		}
+	}
+	if len(bots) > 0 {
+		notes = append(notes, fmt.Sprintf(botInviteesMsg, mentions(bots)))
+	}
+
+	callerInfo, err := s.userInfo(ctx, slackClient, in.TeamID, in.UserID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving user info from slack")
+			return CommandResult{}, err
+		}
+	}
+	// The host moderates a lobby enabled meeting so they can admit invitees.
+	callerConfURL, err := s.conferenceURL(ctx, serverCfg, JWTInput{
+		TenantID:     strings.ToLower(in.TeamID),
+		TenantName:   strings.ToLower(in.TeamName),
+		RoomClaim:    room,
+		UserID:       in.UserID,
+		UserName:     s.conferenceName(callerInfo),
+		AvatarURL:    callerInfo.Profile.Image192,
+		Moderator:    lobby,
+		Lobby:        lobby,
+		Features:     features,
+		MaxOccupants: maxOccupants,
+		Lifetime:     lifetime,
+		AppSecret:    serverCfg.AppSecret,
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("creating conference token")
+		return CommandResult{}, err
+	}
+	logMeetingURL(ctx, in.TeamID, serverCfg.authenticatedURLs())
+
+	if expiry := s.linkExpiry(time.Now(), lifetime); expiry != "" {
+		notes = append(notes, expiry)
+	}
+	note, _ := json.Marshal(strings.Join(notes, " "))
+
+	// TODO: determine what's an error that gets exposed to the user.
+	return CommandResult{
+		Body: fmt.Sprintf(userTemplate, s.shorten(ctx, callerConfURL), room, note, s.titlePrefix(), jsonFallback(s.Fallbacks.Host, defaultHostFallback, in.UserID, confHost, room), s.guestAction(ctx, in.TeamID, confHost, strings.ToLower(in.TeamName), room)),
+		Room: room,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package jitsi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var atMentionRE = regexp.MustCompile(`<@([^>|]+)`)
+
+// flagRE matches words shaped like a flag or option, i.e. --name or
+// --name=value.
+var flagRE = regexp.MustCompile(`^--[a-zA-Z][a-zA-Z0-9-]*(=|$)`)
+
+const (
+	// flagActiveOnly limits invites to users whose presence is active.
+	flagActiveOnly = "--active-only"
+	// flagPublic and flagPrivate override PrivateMeetings for a meeting
+	// without invitees.
+	flagPublic  = "--public"
+	flagPrivate = "--private"
+)
+
+var flags = map[string]bool{
+	flagActiveOnly: true,
+	flagPublic:     true,
+	flagPrivate:    true,
+}
+
+// options are the flags that take a value, given as '--name=value'.
+var options = map[string]bool{
+	flagMaxOccupants: true,
+}
+
+// Command is slash command text parsed into its parts.
+type Command struct {
+	// Subcommand is the subcommand named by the first word of the text. It's
+	// empty for the default command, which starts a meeting.
+	Subcommand string
+	// Mentions are the ids of the users mentioned in the text in order.
+	Mentions []string
+	// Flags are the flags given anywhere in the text.
+	Flags map[string]bool
+	// Options are the values of the options given anywhere in the text,
+	// keyed by option name.
+	Options map[string]string
+	// Text is the text following the subcommand with flags removed.
+	Text string
+}
+
+// ParseCommand parses slash command text. The subcommand must be the whole
+// first word of the text, so text such as 'helpful' is kept as text for the
+// default command rather than being treated as 'help'. Known flags and
+// options are taken from anywhere in the text. Other words shaped like a
+// flag are rejected while they're in flag position, before any free text,
+// so a mistyped flag isn't taken as text, and are kept as text after it.
+func ParseCommand(text string) (Command, error) {
+	cmd := Command{Flags: map[string]bool{}, Options: map[string]string{}}
+	text = strings.TrimSpace(text)
+	fields := strings.Fields(text)
+	if len(fields) > 0 && subcommands[strings.ToLower(fields[0])] {
+		cmd.Subcommand = strings.ToLower(fields[0])
+		text = strings.TrimSpace(text[len(fields[0]):])
+	}
+
+	var words []string
+	freeText := false
+	for _, word := range strings.Fields(text) {
+		if name, value, ok := strings.Cut(word, "="); ok && options[name] {
+			cmd.Options[name] = value
+			continue
+		}
+		if flags[word] {
+			cmd.Flags[word] = true
+			continue
+		}
+		if flagRE.MatchString(word) && !freeText {
+			return Command{}, fmt.Errorf("%s isn't a known option", word)
+		}
+		if !atMentionRE.MatchString(word) {
+			freeText = true
+		}
+		words = append(words, word)
+	}
+	if len(cmd.Flags) > 0 || len(cmd.Options) > 0 {
+		text = strings.Join(words, " ")
+	}
+	cmd.Text = text
+
+	for _, match := range atMentionRE.FindAllStringSubmatch(text, -1) {
+		cmd.Mentions = append(cmd.Mentions, match[1])
+	}
+	return cmd, nil
+}
@@ -0,0 +1,169 @@
+package jitsi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseCommandMatchesWholeWords(t *testing.T) {
+	tests := map[string]string{
+		"help":              subcommandHelp,
+		"HELP":              subcommandHelp,
+		"  help  ":          subcommandHelp,
+		"helpful":           "",
+		"helpme":            "",
+		"whoami":            subcommandWhoami,
+		"whoamis":           "",
+		"guest":             subcommandGuest,
+		"guests":            "",
+		"lobby <@UBOB>":     subcommandLobby,
+		"lobbying <@UBOB>":  "",
+		"standup with help": "",
+	}
+	for text, want := range tests {
+		cmd, err := ParseCommand(text)
+		if err != nil {
+			t.Errorf("ParseCommand(%q): %v", text, err)
+			continue
+		}
+		if cmd.Subcommand != want {
+			t.Errorf("ParseCommand(%q).Subcommand = %q, want %q", text, cmd.Subcommand, want)
+		}
+	}
+}
+
+func TestNearMissSubcommandsStartMeetings(t *testing.T) {
+	for _, text := range []string{"helpme", "helpful", "whoamis", "guests"} {
+		t.Run(text, func(t *testing.T) {
+			result := processCommand(t, newTestHandlers(t, newFakeSlack(t)), text)
+			if result.Room == "" || strings.Contains(result.Body, "How to use") {
+				t.Errorf("%q = %s, want a meeting", text, result.Body)
+			}
+		})
+	}
+}
+
+func TestParseCommandSubcommands(t *testing.T) {
+	var names []string
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			for _, text := range []string{name, strings.ToUpper(name), name + " some args <@UBOB>"} {
+				cmd, err := ParseCommand(text)
+				if err != nil {
+					t.Fatalf("ParseCommand(%q): %v", text, err)
+				}
+				if cmd.Subcommand != name {
+					t.Errorf("ParseCommand(%q).Subcommand = %q, want %q", text, cmd.Subcommand, name)
+				}
+				if want := strings.TrimSpace(text[len(name):]); cmd.Text != want {
+					t.Errorf("ParseCommand(%q).Text = %q, want %q", text, cmd.Text, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		want Command
+	}{
+		{"", Command{}},
+		{"standup", Command{Text: "standup"}},
+		{"<@UBOB> <@UALICE|alice>", Command{Mentions: []string{"UBOB", "UALICE"}, Text: "<@UBOB> <@UALICE|alice>"}},
+		{"lobby <@UBOB>", Command{Subcommand: subcommandLobby, Mentions: []string{"UBOB"}, Text: "<@UBOB>"}},
+		{"--active-only", Command{Flags: map[string]bool{flagActiveOnly: true}}},
+		{"--public", Command{Flags: map[string]bool{flagPublic: true}}},
+		{"--private", Command{Flags: map[string]bool{flagPrivate: true}}},
+		{"--public --private", Command{Flags: map[string]bool{flagPublic: true, flagPrivate: true}}},
+		{"<@UBOB> --active-only", Command{Mentions: []string{"UBOB"}, Flags: map[string]bool{flagActiveOnly: true}, Text: "<@UBOB>"}},
+		{"--active-only <@UBOB>", Command{Mentions: []string{"UBOB"}, Flags: map[string]bool{flagActiveOnly: true}, Text: "<@UBOB>"}},
+		{"--active-only --active-only <@UBOB>", Command{Mentions: []string{"UBOB"}, Flags: map[string]bool{flagActiveOnly: true}, Text: "<@UBOB>"}},
+		{"lobby --active-only <@UBOB>", Command{Subcommand: subcommandLobby, Mentions: []string{"UBOB"}, Flags: map[string]bool{flagActiveOnly: true}, Text: "<@UBOB>"}},
+		{"invite-channel --active-only", Command{Subcommand: subcommandInviteChannel, Flags: map[string]bool{flagActiveOnly: true}}},
+		{"invite-channel --active-only confirm", Command{Subcommand: subcommandInviteChannel, Flags: map[string]bool{flagActiveOnly: true}, Text: "confirm"}},
+		{"--max-occupants=5", Command{Options: map[string]string{flagMaxOccupants: "5"}}},
+		{"--max-occupants=", Command{Options: map[string]string{flagMaxOccupants: ""}}},
+		{"<@UBOB> --max-occupants=5 --active-only", Command{Mentions: []string{"UBOB"}, Flags: map[string]bool{flagActiveOnly: true}, Options: map[string]string{flagMaxOccupants: "5"}, Text: "<@UBOB>"}},
+		{"lobby <@UBOB> --max-occupants=3", Command{Subcommand: subcommandLobby, Mentions: []string{"UBOB"}, Options: map[string]string{flagMaxOccupants: "3"}, Text: "<@UBOB>"}},
+		{"--private standup", Command{Flags: map[string]bool{flagPrivate: true}, Text: "standup"}},
+		{"standup --private", Command{Flags: map[string]bool{flagPrivate: true}, Text: "standup"}},
+		{"template save standup <@UBOB> --active-only", Command{Subcommand: subcommandTemplate, Mentions: []string{"UBOB"}, Flags: map[string]bool{flagActiveOnly: true}, Text: "save standup <@UBOB>"}},
+		{"set-duration 45m", Command{Subcommand: subcommandSetDuration, Text: "45m"}},
+		{"features lobby=on", Command{Subcommand: subcommandFeatures, Text: "lobby=on"}},
+		{"auth off", Command{Subcommand: subcommandAuth, Text: "off"}},
+		{"tokens revoke", Command{Subcommand: subcommandTokens, Text: "revoke"}},
+		{"channel-room  weekly   sync", Command{Subcommand: subcommandChannelRoom, Text: "weekly   sync"}},
+		{`import {"conference_host":"https://meet.example.com"}`, Command{Subcommand: subcommandImport, Text: `{"conference_host":"https://meet.example.com"}`}},
+
+		// Words shaped like flags are kept as text after free text.
+		{"standup --notes", Command{Text: "standup --notes"}},
+		{"standup --notes --active-only", Command{Flags: map[string]bool{flagActiveOnly: true}, Text: "standup --notes"}},
+		{"template save standup --typo", Command{Subcommand: subcommandTemplate, Text: "save standup --typo"}},
+		{"channel-room sync --weekly", Command{Subcommand: subcommandChannelRoom, Text: "sync --weekly"}},
+		{"standup --size=large", Command{Text: "standup --size=large"}},
+
+		// So are words that aren't shaped like flags.
+		{"--", Command{Text: "--"}},
+		{"---", Command{Text: "---"}},
+		{"-- <@UBOB>", Command{Mentions: []string{"UBOB"}, Text: "-- <@UBOB>"}},
+		{"--5 minutes", Command{Text: "--5 minutes"}},
+		{"-active-only", Command{Text: "-active-only"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			cmd, err := ParseCommand(tt.text)
+			if err != nil {
+				t.Fatalf("ParseCommand(%q): %v", tt.text, err)
+			}
+			if tt.want.Flags == nil {
+				tt.want.Flags = map[string]bool{}
+			}
+			if tt.want.Options == nil {
+				tt.want.Options = map[string]string{}
+			}
+			if !reflect.DeepEqual(cmd, tt.want) {
+				t.Errorf("ParseCommand(%q) = %+v, want %+v", tt.text, cmd, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommandRejectsUnknownFlags(t *testing.T) {
+	tests := map[string]string{
+		"--active-onyl":              "--active-onyl",
+		"--active-onyl <@UBOB>":      "--active-onyl",
+		"<@UBOB> --active-onyl":      "--active-onyl",
+		"<@UBOB> --active-only --x":  "--x",
+		"lobby <@UBOB> --publik":     "--publik",
+		"invite-channel --everyone":  "--everyone",
+		"--max-occupant=5":           "--max-occupant=5",
+		"--Private":                  "--Private",
+		"<@UBOB> <@UALICE> --silent": "--silent",
+	}
+	for text, flag := range tests {
+		t.Run(text, func(t *testing.T) {
+			_, err := ParseCommand(text)
+			if err == nil || err.Error() != flag+" isn't a known option" {
+				t.Errorf("ParseCommand(%q) = %v, want %s rejected", text, err, flag)
+			}
+		})
+	}
+}
+
+func TestUnknownFlagsAfterTextStartMeetings(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	result := processCommand(t, s, "standup --notes")
+	if result.Room == "" {
+		t.Errorf("body = %s, want a meeting", result.Body)
+	}
+	if _, got := responseOf(t, processCommand(t, s, "--notes standup")); got != "--notes isn't a known option" {
+		t.Errorf("reply = %q, want the flag rejected", got)
+	}
+}
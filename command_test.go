@@ -0,0 +1,1009 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// responseOf decodes the response type and text of a command result.
+func responseOf(t *testing.T, result CommandResult) (responseType, text string) {
+	t.Helper()
+	var msg struct {
+		ResponseType string `json:"response_type"`
+		Text         string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &msg); err != nil {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	return msg.ResponseType, msg.Text
+}
+
+func processCommand(t *testing.T, s *SlashCommandHandlers, text string) CommandResult {
+	t.Helper()
+	result, err := s.ProcessCommand(context.Background(), CommandInput{
+		TeamID:    testTeamID,
+		TeamName:  testTeamDomain,
+		UserID:    "UHOST",
+		ChannelID: "C1",
+		Text:      text,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// mustJSON returns s escaped as in a json string.
+func mustJSON(t *testing.T, s string) string {
+	t.Helper()
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Trim(string(b), `"`)
+}
+
+func TestProcessCommandReplies(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"self invite", "<@UHOST>", selfInviteMsg},
+		{"public and private", "--public --private", publicPrivateMsg},
+		{"unknown user", "<@UNOBODY>", fmt.Sprintf(failedInvitesMsg, "<@UNOBODY>")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			result := processCommand(t, newTestHandlers(t, slack), tt.text)
+			if !strings.Contains(result.Body, mustJSON(t, tt.want)) {
+				t.Errorf("body = %s, want %q", result.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessCommandHelpNeedsNoToken(t *testing.T) {
+	s := &SlashCommandHandlers{TokenReader: &MemoryTokenStore{}}
+	result := processCommand(t, s, "help")
+	if !strings.Contains(result.Body, "/jitsi") {
+		t.Errorf("body = %s, want the help text", result.Body)
+	}
+}
+
+func TestProcessCommandPromptsInstallWithoutToken(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.TokenReader = &MemoryTokenStore{}
+	result := processCommand(t, s, "")
+	if !strings.Contains(result.Body, testInstallURL) {
+		t.Errorf("body = %s, want the install prompt", result.Body)
+	}
+}
+
+func TestProcessCommandStartsMeeting(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		responseType string
+	}{
+		{"channel", "", "in_channel"},
+		{"private", "--private", "ephemeral"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			result := processCommand(t, newTestHandlers(t, slack), tt.text)
+			if result.Room == "" {
+				t.Fatalf("no room in %s", result.Body)
+			}
+			if got, _ := responseOf(t, result); got != tt.responseType {
+				t.Errorf("response_type = %q, want %q", got, tt.responseType)
+			}
+			meetingURL := testConfHost + "/" + testTeamDomain + "/" + result.Room
+			if !strings.Contains(result.Body, meetingURL) {
+				t.Errorf("body = %s, want %s", result.Body, meetingURL)
+			}
+			if calls := slack.Calls("chat.postMessage"); len(calls) != 0 {
+				t.Errorf("posted %d messages, want the meeting only in the response", len(calls))
+			}
+		})
+	}
+}
+
+func TestPrivateMeetingsDefault(t *testing.T) {
+	tests := []struct {
+		name         string
+		private      bool
+		text         string
+		responseType string
+	}{
+		{"broadcast default", false, "", "in_channel"},
+		{"broadcast default made private", false, "--private", "ephemeral"},
+		{"broadcast default made public", false, "--public", "in_channel"},
+		{"private default", true, "", "ephemeral"},
+		{"private default made public", true, "--public", "in_channel"},
+		{"private default made private", true, "--private", "ephemeral"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHandlers(t, newFakeSlack(t))
+			s.PrivateMeetings = tt.private
+
+			result := processCommand(t, s, tt.text)
+			if got, _ := responseOf(t, result); got != tt.responseType {
+				t.Errorf("response_type = %q, want %q", got, tt.responseType)
+			}
+			if !strings.Contains(result.Body, testConfHost+"/"+testTeamDomain+"/"+result.Room) {
+				t.Errorf("body = %s, want the meeting url", result.Body)
+			}
+		})
+	}
+}
+
+func TestPublicAndPrivateConflict(t *testing.T) {
+	for _, private := range []bool{false, true} {
+		s := newTestHandlers(t, newFakeSlack(t))
+		s.PrivateMeetings = private
+		result := processCommand(t, s, "--public --private")
+		if _, got := responseOf(t, result); got != publicPrivateMsg || result.Room != "" {
+			t.Errorf("reply = %q with room %q, want %q", got, result.Room, publicPrivateMsg)
+		}
+	}
+}
+
+func TestProcessCommandInvitesEachUser(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UCAROL", "carol")
+	result := processCommand(t, newTestHandlers(t, slack), "<@UBOB> <@UCAROL>")
+
+	if result.Room == "" {
+		t.Fatalf("no room in %s", result.Body)
+	}
+	var channels []string
+	for _, call := range slack.Calls("chat.postMessage") {
+		channels = append(channels, call.Form.Get("channel"))
+	}
+	if got := strings.Join(channels, ","); got != "DUBOB,DUCAROL" {
+		t.Errorf("invites posted to %s, want DUBOB,DUCAROL", got)
+	}
+	if strings.Contains(result.Body, "⚠️") {
+		t.Errorf("body = %s, want no failures", result.Body)
+	}
+}
+
+// hostURL returns the url of the join button on a command's confirmation.
+func hostURL(t *testing.T, result CommandResult) string {
+	t.Helper()
+	var confirmation struct {
+		Attachments []struct {
+			Actions []struct {
+				URL string `json:"url"`
+			} `json:"actions"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &confirmation); err != nil {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	if len(confirmation.Attachments) == 0 || len(confirmation.Attachments[0].Actions) == 0 {
+		t.Fatalf("confirmation %s has no join button", result.Body)
+	}
+	return confirmation.Attachments[0].Actions[0].URL
+}
+
+// inviteURL returns the url of the join button of a posted invite.
+func inviteURL(t *testing.T, call slackCall) string {
+	t.Helper()
+	var invite []struct {
+		Actions []struct {
+			URL string `json:"url"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(call.Form.Get("attachments")), &invite); err != nil {
+		t.Fatalf("decoding invite %s: %v", call.Form.Get("attachments"), err)
+	}
+	if len(invite) == 0 || len(invite[0].Actions) == 0 {
+		t.Fatalf("invite %s has no join button", call.Form.Get("attachments"))
+	}
+	return invite[0].Actions[0].URL
+}
+
+func TestLobbySubcommand(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+
+	host := contextOf(t, tokenClaims(t, hostURL(t, processCommand(t, s, "lobby <@UBOB>"))))
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want 1", len(posted))
+	}
+	invitee := contextOf(t, tokenClaims(t, inviteURL(t, posted[0])))
+	if !host.User.Moderator || host.Room == nil || !host.Room.Lobby {
+		t.Errorf("host context = %+v, want a moderator of a lobby", host)
+	}
+	if invitee.User.Moderator || invitee.Room == nil || !invitee.Room.Lobby {
+		t.Errorf("invitee context = %+v, want a lobby without moderation", invitee)
+	}
+}
+
+func TestInvitesUseBotIdentity(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.InviteIdentity = BotIdentity{
+		Username:  "Acme Meetings",
+		IconURL:   "https://acme.example.com/icon.png",
+		IconEmoji: ":camera:",
+	}
+
+	processCommand(t, s, "<@UBOB>")
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want 1", len(posted))
+	}
+	form := posted[0].Form
+	if form.Get("username") != "Acme Meetings" || form.Get("icon_url") != "https://acme.example.com/icon.png" || form.Get("icon_emoji") != ":camera:" {
+		t.Errorf("invite posted with %v, want the configured identity", form)
+	}
+}
+
+func TestInvitesKeepDefaultBotIdentity(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	processCommand(t, newTestHandlers(t, slack), "<@UBOB>")
+	form := slack.Calls("chat.postMessage")[0].Form
+	for _, name := range []string{"username", "icon_url", "icon_emoji"} {
+		if _, ok := form[name]; ok {
+			t.Errorf("invite posted with %s = %q, want Slack's default", name, form.Get(name))
+		}
+	}
+}
+
+type failingTokenReader struct{}
+
+func (failingTokenReader) GetFirstBotTokenForTeam(teamID string) (string, error) {
+	return "", errors.New("table unavailable")
+}
+
+func TestWhoami(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokens    TokenReader
+		configs   ServerConfigStore
+		installed string
+		host      string
+	}{
+		{"installed", nil, nil, "yes", testConfHost},
+		{"not installed", &MemoryTokenStore{}, nil, "no", testConfHost},
+		{"token store down", failingTokenReader{}, nil, "unknown", testConfHost},
+		{"team host", nil, stubServerConfigs{cfg: ServerConfig{ConferenceHost: "https://team.example.com"}}, "yes", "https://team.example.com"},
+		{"config store down", nil, stubServerConfigs{err: errors.New("table unavailable")}, "yes", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHandlers(t, newFakeSlack(t))
+			if tt.tokens != nil {
+				s.TokenReader = tt.tokens
+			}
+			s.ServerConfigs = tt.configs
+
+			result := processCommand(t, s, "whoami")
+			var msg struct {
+				ResponseType string `json:"response_type"`
+				Attachments  []struct {
+					Text string `json:"text"`
+				} `json:"attachments"`
+			}
+			if err := json.Unmarshal([]byte(result.Body), &msg); err != nil {
+				t.Fatal(err)
+			}
+			want := fmt.Sprintf("team_id: T1\nuser_id: UHOST\nchannel_id: C1\nbot token installed: %s\nconference host: %s", tt.installed, tt.host)
+			if msg.ResponseType != "ephemeral" || len(msg.Attachments) != 1 || msg.Attachments[0].Text != want {
+				t.Errorf("whoami = %s, want %q", result.Body, want)
+			}
+			if strings.Contains(result.Body, testBotToken) {
+				t.Errorf("whoami = %s, leaks the bot token", result.Body)
+			}
+		})
+	}
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		text    string
+		want    string
+	}{
+		{"meeting", "", "", defaultMaintenanceMessage},
+		{"invite", "", "<@UBOB>", defaultMaintenanceMessage},
+		{"guest", "", "guest", defaultMaintenanceMessage},
+		{"custom message", "Back at 5pm.", "<@UBOB>", "Back at 5pm."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.MaintenanceMode = true
+			s.MaintenanceMessage = tt.message
+
+			result := processCommand(t, s, tt.text)
+			if _, got := responseOf(t, result); got != tt.want || result.Room != "" {
+				t.Errorf("reply = %q, room %q, want %q without a meeting", got, result.Room, tt.want)
+			}
+			if calls := slack.Calls("chat.postMessage"); len(calls) != 0 {
+				t.Errorf("sent %d invites during maintenance", len(calls))
+			}
+		})
+	}
+}
+
+func TestMaintenanceModeAllowsReadOnlySubcommands(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.MaintenanceMode = true
+
+	if _, text := helpText(t, processCommand(t, s, "help")); text == "" {
+		t.Error("help is unavailable during maintenance")
+	}
+	if body := processCommand(t, s, "whoami").Body; !strings.Contains(body, "team_id: T1") {
+		t.Errorf("whoami = %s during maintenance, want the support details", body)
+	}
+}
+
+func TestMaintenanceModeOff(t *testing.T) {
+	if result := processCommand(t, newTestHandlers(t, newFakeSlack(t)), ""); result.Room == "" {
+		t.Errorf("meeting = %s, want a room outside maintenance", result.Body)
+	}
+}
+
+// installButton returns the url of the Add to Slack button of an install
+// prompt.
+func installButton(t *testing.T, result CommandResult) string {
+	t.Helper()
+	var prompt struct {
+		Blocks []struct {
+			Type     string `json:"type"`
+			Elements []struct {
+				Type string `json:"type"`
+				Text struct {
+					Text string `json:"text"`
+				} `json:"text"`
+				URL string `json:"url"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &prompt); err != nil {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	for _, block := range prompt.Blocks {
+		for _, element := range block.Elements {
+			if block.Type == "actions" && element.Type == "button" && element.Text.Text == "Add to Slack" {
+				return element.URL
+			}
+		}
+	}
+	t.Fatalf("install prompt %s has no Add to Slack button", result.Body)
+	return ""
+}
+
+func TestInstallPromptButton(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.TokenReader = &MemoryTokenStore{}
+	s.InstallURL = AuthorizeURL("client", []string{"commands", "chat:write"})
+
+	button, err := url.Parse(installButton(t, processCommand(t, s, "<@UBOB>")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if button.Host != "slack.com" || button.Path != "/oauth/v2/authorize" {
+		t.Errorf("button links to %s, want the oauth authorize url", button)
+	}
+	if q := button.Query(); q.Get("client_id") != "client" || q.Get("scope") != "commands,chat:write" {
+		t.Errorf("button query = %v, want the client id and scopes", q)
+	}
+}
+
+func TestInstallPromptButtonCarriesSignedState(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.TokenReader = &MemoryTokenStore{}
+	s.InstallLinks = &InstallLinkSigner{Secret: "secret", Validity: time.Hour}
+
+	button, err := url.Parse(installButton(t, processCommand(t, s, "")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InstallLinks.Verify(button.Query().Get("state"), time.Now()); err != nil {
+		t.Errorf("button state %q doesn't verify: %v", button.Query().Get("state"), err)
+	}
+}
+
+// roomField returns the Room field of a meeting reply.
+func roomField(t *testing.T, result CommandResult) string {
+	t.Helper()
+	var reply struct {
+		Attachments []struct {
+			Fields []struct {
+				Title string `json:"title"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &reply); err != nil {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	for _, attachment := range reply.Attachments {
+		for _, field := range attachment.Fields {
+			if field.Title == "Room" {
+				return field.Value
+			}
+		}
+	}
+	t.Fatalf("reply %s has no Room field", result.Body)
+	return ""
+}
+
+func TestRepliesShowRoomName(t *testing.T) {
+	for name, text := range map[string]string{"channel": "", "invite": "<@UBOB>"} {
+		t.Run(name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+
+			result := processCommand(t, s, text)
+			room := path.Base(strings.SplitN(hostURL(t, result), "?", 2)[0])
+			if result.Room != room {
+				t.Errorf("Room = %q, want %q from the meeting url", result.Room, room)
+			}
+			if got := roomField(t, result); got != room {
+				t.Errorf("Room field = %q, want %q", got, room)
+			}
+		})
+	}
+}
+
+const testChannelMembers = `{"ok":true,"members":["UHOST","UBOB","UALICE","UCAROL"],"response_metadata":{"next_cursor":""}}`
+
+// channelSlack returns a fakeSlack for a channel of the host, bob, alice
+// and carol.
+func channelSlack(t *testing.T) *fakeSlack {
+	t.Helper()
+	slack := newFakeSlack(t)
+	slack.Handle("conversations.members", testChannelMembers)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	slack.AddUser("UCAROL", "carol")
+	return slack
+}
+
+// invitedChannels returns the channels invites were posted to.
+func invitedChannels(slack *fakeSlack) []string {
+	var channels []string
+	for _, call := range slack.Calls("chat.postMessage") {
+		channels = append(channels, call.Form.Get("channel"))
+	}
+	return channels
+}
+
+func TestInviteChannel(t *testing.T) {
+	slack := channelSlack(t)
+	s := newTestHandlers(t, slack)
+
+	processCommand(t, s, "invite-channel")
+	if got := strings.Join(invitedChannels(slack), ","); got != "DUBOB,DUALICE,DUCAROL" {
+		t.Errorf("invited %s, want every member but the caller", got)
+	}
+}
+
+func TestInviteChannelConfirmsLargeChannels(t *testing.T) {
+	slack := channelSlack(t)
+	s := newTestHandlers(t, slack)
+	s.ConfirmChannelSize = 4
+
+	result := processCommand(t, s, "invite-channel")
+	if !strings.Contains(result.Body, actionInviteChannel) || !strings.Contains(result.Body, "each of the 4 members") {
+		t.Errorf("reply = %s, want a confirmation for 4 members", result.Body)
+	}
+	if channels := invitedChannels(slack); len(channels) != 0 {
+		t.Errorf("invited %v before confirming", channels)
+	}
+
+	processCommand(t, s, "invite-channel "+inviteChannelConfirmed)
+	if channels := invitedChannels(slack); len(channels) != 3 {
+		t.Errorf("invited %v once confirmed, want 3 members", channels)
+	}
+}
+
+func TestInviteChannelRefusesChannelsOverLimit(t *testing.T) {
+	slack := channelSlack(t)
+	s := newTestHandlers(t, slack)
+	s.MaxChannelInvitees = 2
+
+	_, got := responseOf(t, processCommand(t, s, "invite-channel"))
+	if want := fmt.Sprintf(inviteChannelTooLargeMsg, 3, 2); got != want {
+		t.Errorf("reply = %q, want %q", got, want)
+	}
+	if channels := invitedChannels(slack); len(channels) != 0 {
+		t.Errorf("invited %v, want nobody over the limit", channels)
+	}
+
+	s.MaxChannelInvitees = 3
+	processCommand(t, s, "invite-channel")
+	if channels := invitedChannels(slack); len(channels) != 3 {
+		t.Errorf("invited %v, want 3 members at the limit", channels)
+	}
+}
+
+// guestLink returns the link of a guest reply.
+func guestLink(t *testing.T, result CommandResult) string {
+	t.Helper()
+	var reply struct {
+		Attachments []struct {
+			Text string `json:"text"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &reply); err != nil || len(reply.Attachments) == 0 {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	return reply.Attachments[0].Text
+}
+
+func TestGuestLink(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+
+	result := processCommand(t, s, "guest")
+	if want := testConfHost + "/" + testTeamDomain + "/" + result.Room; guestLink(t, result) != want {
+		t.Errorf("guest link = %s, want the plain room url %s", guestLink(t, result), want)
+	}
+	if got := roomField(t, result); got != result.Room {
+		t.Errorf("Room field = %q, want %q", got, result.Room)
+	}
+}
+
+func TestGuestLinkWithToken(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.GuestTokens = true
+	s.GuestName = "Visitor"
+
+	link := guestLink(t, processCommand(t, s, "guest"))
+	claims := tokenClaims(t, link)
+	user := contextOf(t, claims).User
+	if user.ID != guestUserID || user.DisplayName != "Visitor" {
+		t.Errorf("token user = %+v, want the generic guest identity", user)
+	}
+}
+
+// meetingURLLogs runs a command and returns the authenticated_url field of
+// each meeting url it logged.
+func meetingURLLogs(t *testing.T, s *SlashCommandHandlers, text string) []bool {
+	t.Helper()
+	var logs bytes.Buffer
+	ctx := zerolog.New(&logs).WithContext(context.Background())
+	_, err := s.ProcessCommand(ctx, CommandInput{
+		TeamID:    testTeamID,
+		TeamName:  testTeamDomain,
+		UserID:    "UHOST",
+		ChannelID: "C1",
+		Text:      text,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var authenticated []bool
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var line struct {
+			Message       string `json:"message"`
+			TeamID        string `json:"team_id"`
+			Authenticated bool   `json:"authenticated_url"`
+		}
+		if err := dec.Decode(&line); err != nil {
+			t.Fatal(err)
+		}
+		if line.Message == "meeting url generated" && line.TeamID == testTeamID {
+			authenticated = append(authenticated, line.Authenticated)
+		}
+	}
+	return authenticated
+}
+
+func TestLogsMeetingURLAuthentication(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		guestTokens bool
+		want        []bool
+	}{
+		{"channel", "", false, []bool{false}},
+		{"invite", "<@UBOB>", false, []bool{true, true}},
+		{"guest", "guest", false, []bool{false}},
+		{"guest token", "guest", true, []bool{true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.GuestTokens = tt.guestTokens
+
+			if got := meetingURLLogs(t, s, tt.text); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("logged authenticated urls %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// withAppBot stores the app's bot user id UBOT with the test team's tokens.
+func withAppBot(t *testing.T, s *SlashCommandHandlers) {
+	t.Helper()
+	tokens := s.TokenReader.(*MemoryTokenStore)
+	err := tokens.Store(&TokenData{
+		TeamID:     testTeamID,
+		UserID:     "UHOST",
+		BotToken:   testBotToken,
+		BotUserID:  "UBOT",
+		TeamDomain: testTeamDomain,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Teams = tokens
+}
+
+// lookedUp reports whether users.info was asked about a user.
+func lookedUp(slack *fakeSlack, userID string) bool {
+	for _, call := range slack.Calls("users.info") {
+		if call.Form.Get("user") == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInviteFiltersCallerAndBots(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		reply    string
+		note     string
+		channels []string
+	}{
+		{"self", "<@UHOST>", selfInviteMsg, "", nil},
+		{"self and bob", "<@UHOST> <@UBOB>", "", selfInviteIgnoredMsg, []string{"DUBOB"}},
+		{"app", "<@UBOT>", botInviteMsg, "", nil},
+		{"app and bob", "<@UBOT> <@UBOB>", "", fmt.Sprintf(botInviteesMsg, "<@UBOT>"), []string{"DUBOB"}},
+		{"self and app", "<@UHOST> <@UBOT>", botInviteMsg, "", nil},
+		{"other bot", "<@UOTHERBOT> <@UBOB>", "", fmt.Sprintf(botInviteesMsg, "<@UOTHERBOT>"), []string{"DUBOB"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			slack.Users["UOTHERBOT"] = `{"ok":true,"user":{"id":"UOTHERBOT","name":"other","is_bot":true}}`
+			s := newTestHandlers(t, slack)
+			withAppBot(t, s)
+
+			result := processCommand(t, s, tt.text)
+			if tt.reply != "" {
+				if _, got := responseOf(t, result); got != tt.reply {
+					t.Errorf("reply = %q, want %q", got, tt.reply)
+				}
+			}
+			if tt.note != "" && !strings.Contains(result.Body, mustJSON(t, tt.note)) {
+				t.Errorf("reply = %s, want the note %q", result.Body, tt.note)
+			}
+			if got := invitedChannels(slack); fmt.Sprint(got) != fmt.Sprint(tt.channels) {
+				t.Errorf("invites posted to %v, want %v", got, tt.channels)
+			}
+			for _, user := range []string{"UHOST", "UBOT"} {
+				for _, call := range slack.Calls("conversations.open") {
+					if call.Form.Get("users") == user {
+						t.Errorf("opened a dm with %s", user)
+					}
+				}
+			}
+			if lookedUp(slack, "UBOT") {
+				t.Error("looked up the app's bot, want it filtered by id")
+			}
+		})
+	}
+}
+
+func TestGroupInviteReportsBots(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	slack.Users["UOTHERBOT"] = `{"ok":true,"user":{"id":"UOTHERBOT","name":"other","is_bot":true}}`
+	s := newTestHandlers(t, slack)
+	s.GroupInviteLimit = 3
+
+	result := processCommand(t, s, "<@UBOB> <@UALICE> <@UOTHERBOT>")
+	if want := mustJSON(t, fmt.Sprintf(botInviteesMsg, "<@UOTHERBOT>")); !strings.Contains(result.Body, want) {
+		t.Errorf("reply = %s, want the skipped bot reported", result.Body)
+	}
+}
+
+func TestInviteChannelSkipsAppBot(t *testing.T) {
+	slack := channelSlack(t)
+	slack.Handle("conversations.members", `{"ok":true,"members":["UHOST","UBOB","UBOT"],"response_metadata":{"next_cursor":""}}`)
+	s := newTestHandlers(t, slack)
+	withAppBot(t, s)
+
+	processCommand(t, s, "invite-channel")
+	if got := invitedChannels(slack); fmt.Sprint(got) != "[DUBOB]" {
+		t.Errorf("invites posted to %v, want only bob", got)
+	}
+	if lookedUp(slack, "UBOT") {
+		t.Error("looked up the app's bot, want it filtered by id")
+	}
+}
+
+func TestActiveOnlySkipsAwayInvitees(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	slack.AddUser("UCAROL", "carol")
+	slack.Presence["UBOB"] = "active"
+	slack.Presence["UALICE"] = "away"
+	slack.Presence["UCAROL"] = "active"
+	s := newTestHandlers(t, slack)
+
+	result := processCommand(t, s, "<@UBOB> <@UALICE> <@UCAROL> --active-only")
+	if got := invitedChannels(slack); fmt.Sprint(got) != "[DUBOB DUCAROL]" {
+		t.Errorf("invites posted to %v, want bob and carol", got)
+	}
+	if want := mustJSON(t, fmt.Sprintf(awayInviteesMsg, "<@UALICE>")); !strings.Contains(result.Body, want) {
+		t.Errorf("reply = %s, want alice reported as away", result.Body)
+	}
+}
+
+func TestActiveOnlyWithEveryoneAway(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	slack.Presence["UBOB"] = "away"
+	slack.Presence["UALICE"] = "away"
+	s := newTestHandlers(t, slack)
+
+	_, got := responseOf(t, processCommand(t, s, "--active-only <@UBOB> <@UALICE>"))
+	if want := fmt.Sprintf(noActiveInviteesMsg, "<@UBOB>, <@UALICE>"); got != want {
+		t.Errorf("reply = %q, want %q", got, want)
+	}
+	if posted := slack.Calls("chat.postMessage"); len(posted) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want nobody invited", len(posted))
+	}
+}
+
+func TestPresenceOnlyCheckedForActiveOnly(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.Presence["UBOB"] = "away"
+	s := newTestHandlers(t, slack)
+
+	processCommand(t, s, "<@UBOB>")
+	if calls := slack.Calls("users.getPresence"); len(calls) != 0 {
+		t.Errorf("users.getPresence calls = %d, want none without --active-only", len(calls))
+	}
+	if got := invitedChannels(slack); fmt.Sprint(got) != "[DUBOB]" {
+		t.Errorf("invites posted to %v, want bob invited while away", got)
+	}
+}
+
+func TestActiveOnlyInviteChannel(t *testing.T) {
+	slack := channelSlack(t)
+	slack.Presence["UBOB"] = "active"
+	slack.Presence["UALICE"] = "away"
+	slack.Presence["UCAROL"] = "away"
+	s := newTestHandlers(t, slack)
+
+	processCommand(t, s, "invite-channel --active-only")
+	if got := invitedChannels(slack); fmt.Sprint(got) != "[DUBOB]" {
+		t.Errorf("invites posted to %v, want only the active member", got)
+	}
+}
+
+func TestEphemeralInvites(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.EphemeralInvites = true
+
+	processCommand(t, s, "<@UBOB>")
+	posted := slack.Calls("chat.postEphemeral")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postEphemeral calls = %d, want 1", len(posted))
+	}
+	if channel, user := posted[0].Form.Get("channel"), posted[0].Form.Get("user"); channel != "C1" || user != "UBOB" {
+		t.Errorf("ephemeral invite posted to %s for %s, want C1 for UBOB", channel, user)
+	}
+	if got := inviteURL(t, posted[0]); !strings.HasPrefix(got, testConfHost+"/acme/") {
+		t.Errorf("invite url = %s, want a meeting url", got)
+	}
+	if opened := slack.Calls("conversations.open"); len(opened) != 0 {
+		t.Errorf("conversations.open calls = %d, want no dm", len(opened))
+	}
+}
+
+func TestEphemeralInvitesFallBackToDM(t *testing.T) {
+	for _, slackErr := range []string{errNotInChannel, errChannelNotFound, errUserNotInChannel} {
+		t.Run(slackErr, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			slack.Handle("chat.postEphemeral", `{"ok":false,"error":"`+slackErr+`"}`)
+			s := newTestHandlers(t, slack)
+			s.EphemeralInvites = true
+
+			processCommand(t, s, "<@UBOB>")
+			if got := invitedChannels(slack); fmt.Sprint(got) != "[DUBOB]" {
+				t.Errorf("invites posted to %v, want a dm to bob", got)
+			}
+		})
+	}
+}
+
+func TestEphemeralInviteFailureReported(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.Handle("chat.postEphemeral", `{"ok":false,"error":"restricted_action"}`)
+	s := newTestHandlers(t, slack)
+	s.EphemeralInvites = true
+
+	result := processCommand(t, s, "<@UBOB>")
+	if posted := slack.Calls("chat.postMessage"); len(posted) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want no dm for other errors", len(posted))
+	}
+	if want := mustJSON(t, fmt.Sprintf(failedInvitesMsg, "<@UBOB>")); !strings.Contains(result.Body, want) {
+		t.Errorf("reply = %s, want the failed invite reported", result.Body)
+	}
+}
+
+func TestStartedText(t *testing.T) {
+	started := time.Date(2023, 11, 14, 15, 2, 0, 0, time.FixedZone("CET", 3600))
+	if got, want := startedText(started), "Started <!date^1699970520^{time}|2:02 PM UTC>"; got != want {
+		t.Errorf("startedText = %q, want %q", got, want)
+	}
+}
+
+// startedTokenRE matches the date token of a room message.
+var startedTokenRE = regexp.MustCompile(`Started <!date\^(\d+)\^\{time\}\|\d{1,2}:\d{2} [AP]M UTC>`)
+
+func TestRoomMessageShowsStartTime(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	before := time.Now().Unix()
+	result := processCommand(t, s, "")
+	after := time.Now().Unix()
+
+	var reply struct {
+		Attachments []struct {
+			Text string `json:"text"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &reply); err != nil || len(reply.Attachments) == 0 {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	match := startedTokenRE.FindStringSubmatch(reply.Attachments[0].Text)
+	if match == nil {
+		t.Fatalf("room message text = %q, want a start time date token", reply.Attachments[0].Text)
+	}
+	if started, _ := strconv.ParseInt(match[1], 10, 64); started < before || started > after {
+		t.Errorf("started at %d, want between %d and %d", started, before, after)
+	}
+}
+
+func TestInviteDeliveryStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		open    string
+		want    []string
+		notWant []string
+	}{
+		{
+			"all delivered",
+			"<@UBOB> <@UALICE>",
+			"",
+			nil,
+			[]string{"✅", "⚠️"},
+		},
+		{
+			"some failed",
+			"<@UBOB> <@UNOBODY> <@UALICE>",
+			"",
+			[]string{fmt.Sprintf(deliveredInvitesMsg, "<@UBOB>, <@UALICE>"), fmt.Sprintf(failedInvitesMsg, "<@UNOBODY>")},
+			nil,
+		},
+		{
+			"all failed",
+			"<@UNOBODY>",
+			"",
+			[]string{fmt.Sprintf(failedInvitesMsg, "<@UNOBODY>")},
+			[]string{"✅"},
+		},
+		{
+			"dm blocked",
+			"<@UBOB> <@UALICE>",
+			`{"ok":false,"error":"messages_tab_disabled"}`,
+			[]string{fmt.Sprintf(dmBlockedMsg, "<@UBOB>, <@UALICE>", dmBlockedReasons["messages_tab_disabled"])},
+			[]string{"✅"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			slack.AddUser("UALICE", "alice")
+			if tt.open != "" {
+				slack.Handle("conversations.open", tt.open)
+			}
+			s := newTestHandlers(t, slack)
+
+			_, text := responseOf(t, processCommand(t, s, tt.text))
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("reply = %q, want %q", text, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("reply = %q, want no %q", text, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestHostGuestLinkButton(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		slack := newFakeSlack(t)
+		slack.AddUser("UBOB", "bob")
+		s := newTestHandlers(t, slack)
+		s.HostGuestLinks = enabled
+
+		result := processCommand(t, s, "<@UBOB>")
+		var confirmation struct {
+			Attachments []struct {
+				Actions []struct {
+					Name  string `json:"name"`
+					URL   string `json:"url"`
+					Style string `json:"style"`
+				} `json:"actions"`
+			} `json:"attachments"`
+		}
+		if err := json.Unmarshal([]byte(result.Body), &confirmation); err != nil || len(confirmation.Attachments) != 1 {
+			t.Fatalf("decoding %s: %v", result.Body, err)
+		}
+		actions := confirmation.Attachments[0].Actions
+		want := 1
+		if enabled {
+			want = 2
+		}
+		if len(actions) != want {
+			t.Fatalf("HostGuestLinks %v: buttons = %+v, want %d", enabled, actions, want)
+		}
+		join := actions[0]
+		if join.Name != "join" || join.Style != "primary" || !strings.Contains(join.URL, "/"+result.Room+"?jwt=") {
+			t.Errorf("HostGuestLinks %v: first button = %+v, want the primary authenticated join", enabled, join)
+		}
+		if !enabled {
+			continue
+		}
+		guest := actions[1]
+		if plain := fmt.Sprintf("%s/%s/%s", testConfHost, testTeamDomain, result.Room); guest.Name != "guest" || guest.URL != plain || guest.Style != "" {
+			t.Errorf("guest button = %+v, want a secondary button with %s", guest, plain)
+		}
+	}
+}
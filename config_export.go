@@ -0,0 +1,282 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const (
+	configUnsupportedMsg = "Exporting, importing and copying the workspace config is not supported by this installation."
+	configAdminMsg       = "Only workspace admins can export or import the workspace config."
+	configExportMsg      = "Workspace config, import it with '%s import <config>':\n```%s```"
+	configImportUsageMsg = "Please provide a config from '%s export' i.e. '%s import {\"conference_host\":\"https://meet.example.com\"}'."
+	configInvalidMsg     = "The config couldn't be imported: %s."
+	configImportedMsg    = "The workspace config was imported."
+
+	configCopyUsageMsg        = "Please give the id of the workspace to copy the config to i.e. '%s copy-config T0123456'."
+	configCopyAdminMsg        = "Only admins of both workspaces can copy the workspace config."
+	configCopySameMsg         = "The config can't be copied to the workspace it's from."
+	configCopyNotInstalledMsg = "The config can't be copied, the app isn't installed in %s."
+	configCopyInvalidMsg      = "The config couldn't be copied: %s."
+	configCopiedMsg           = "The workspace config was copied to %s."
+)
+
+// slackLinkRE matches links Slack escaped in command text, i.e.
+// <https://meet.example.com> or <https://meet.example.com|meet.example.com>.
+var slackLinkRE = regexp.MustCompile(`<((?:https?|mailto):[^|>]*)(?:\|[^>]*)?>`)
+
+// slackTextUnescaper undoes the escaping Slack applies to command text.
+var slackTextUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// exportedServerConfig is a team's server config as it's exported and
+// imported. The app secret is exported redacted, importing it redacted
+// keeps the app secret the team already has.
+type exportedServerConfig struct {
+	ConferenceHost      string       `json:"conference_host,omitempty"`
+	MeetingDuration     string       `json:"meeting_duration,omitempty"`
+	Capabilities        Capabilities `json:"capabilities,omitempty"`
+	AppSecret           string       `json:"app_secret,omitempty"`
+	UnauthenticatedURLs bool         `json:"unauthenticated_urls,omitempty"`
+}
+
+// exportServerConfig converts a server config for export, redacting its
+// app secret.
+func exportServerConfig(cfg ServerConfig) exportedServerConfig {
+	exported := exportedServerConfig{
+		ConferenceHost:      cfg.ConferenceHost,
+		Capabilities:        cfg.Capabilities,
+		UnauthenticatedURLs: cfg.UnauthenticatedURLs,
+	}
+	if cfg.MeetingDuration > 0 {
+		exported.MeetingDuration = durationText(cfg.MeetingDuration)
+	}
+	if cfg.AppSecret != "" {
+		exported.AppSecret = redacted
+	}
+	return exported
+}
+
+// importServerConfig validates an exported server config and converts it
+// to the server config replacing current, keeping the current app secret
+// when it's redacted.
+func importServerConfig(exported exportedServerConfig, current ServerConfig) (ServerConfig, error) {
+	cfg := ServerConfig{
+		ConferenceHost:      exported.ConferenceHost,
+		Capabilities:        exported.Capabilities,
+		AppSecret:           exported.AppSecret,
+		UnauthenticatedURLs: exported.UnauthenticatedURLs,
+	}
+	if cfg.ConferenceHost != "" {
+		if err := validateURL("conference_host", cfg.ConferenceHost); err != nil {
+			return ServerConfig{}, err
+		}
+	}
+	if exported.MeetingDuration != "" {
+		duration, ok := parseMeetingDuration(exported.MeetingDuration)
+		if !ok {
+			return ServerConfig{}, fmt.Errorf("meeting_duration must be from %s to %s", durationText(minMeetingDuration), durationText(maxMeetingDuration))
+		}
+		cfg.MeetingDuration = duration
+	}
+	for name := range cfg.Capabilities {
+		if _, ok := knownCapabilities[name]; !ok {
+			return ServerConfig{}, fmt.Errorf("unknown capability %q, use one of %s", name, strings.Join(capabilityNames(), ", "))
+		}
+	}
+	if cfg.AppSecret == redacted {
+		cfg.AppSecret = current.AppSecret
+	}
+	return cfg, nil
+}
+
+// parseExportedServerConfig parses a config given as command text, which
+// may be wrapped in the code block it was exported in.
+func parseExportedServerConfig(text string) (exportedServerConfig, error) {
+	text = slackLinkRE.ReplaceAllString(text, "$1")
+	text = slackTextUnescaper.Replace(text)
+	text = strings.TrimSpace(strings.Trim(strings.TrimSpace(text), "`"))
+
+	var exported exportedServerConfig
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&exported); err != nil {
+		return exportedServerConfig{}, err
+	}
+	if dec.More() {
+		return exportedServerConfig{}, errors.New("unexpected text after the config")
+	}
+	return exported, nil
+}
+
+// transferConfig exports a team's server config for workspace admins, or
+// imports one given as the text of the import subcommand.
+func (s *SlashCommandHandlers) transferConfig(ctx context.Context, client *slack.Client, teamID, userID, subcommand, text string) (CommandResult, error) {
+	if s.ServerConfigs == nil {
+		return ephemeral(configUnsupportedMsg), nil
+	}
+	log := zerolog.Ctx(ctx)
+	if subcommand == subcommandImport && strings.TrimSpace(text) == "" {
+		return ephemeral(fmt.Sprintf(configImportUsageMsg, s.commandName(), s.commandName())), nil
+	}
+
+	user, err := s.fetchUserInfo(ctx, client, userID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving user info from slack")
+			return CommandResult{}, err
+		}
+	}
+	if !user.IsAdmin && !user.IsOwner {
+		return ephemeral(configAdminMsg), nil
+	}
+
+	current, err := s.serverConfig(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving server config")
+		return CommandResult{}, err
+	}
+	if subcommand == subcommandExport {
+		var exported bytes.Buffer
+		enc := json.NewEncoder(&exported)
+		enc.SetEscapeHTML(false)
+		enc.Encode(exportServerConfig(current))
+		return ephemeral(fmt.Sprintf(configExportMsg, s.commandName(), strings.TrimSpace(exported.String()))), nil
+	}
+
+	exported, err := parseExportedServerConfig(text)
+	if err != nil {
+		return ephemeral(fmt.Sprintf(configInvalidMsg, err)), nil
+	}
+	cfg, err := importServerConfig(exported, current)
+	if err != nil {
+		return ephemeral(fmt.Sprintf(configInvalidMsg, err)), nil
+	}
+	if cfg.authenticatedURLs() && !s.canSign(ctx, cfg) {
+		return ephemeral(authNoSigningMsg), nil
+	}
+	err = s.ServerConfigs.StoreServerConfig(teamID, cfg)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("storing server config")
+		return CommandResult{}, err
+	}
+	return ephemeral(configImportedMsg), nil
+}
+
+// copyConfig copies a team's server config to the team given as the text
+// of the copy-config subcommand, for users who are admins of both. Slack
+// shares user ids between the workspaces of an org, so the caller is
+// looked up again in the target team with its own bot token. The app
+// secret is copied as is since it never leaves the server.
+func (s *SlashCommandHandlers) copyConfig(ctx context.Context, client *slack.Client, teamID, userID, text string) (CommandResult, error) {
+	if s.ServerConfigs == nil {
+		return ephemeral(configUnsupportedMsg), nil
+	}
+	log := zerolog.Ctx(ctx)
+	targetID := strings.ToUpper(strings.TrimSpace(text))
+	if targetID == "" || strings.ContainsAny(targetID, " \t\n") {
+		return ephemeral(fmt.Sprintf(configCopyUsageMsg, s.commandName())), nil
+	}
+	if targetID == teamID {
+		return ephemeral(configCopySameMsg), nil
+	}
+
+	user, err := s.fetchUserInfo(ctx, client, userID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving user info from slack")
+			return CommandResult{}, err
+		}
+	}
+	if !user.IsAdmin && !user.IsOwner {
+		return ephemeral(configCopyAdminMsg), nil
+	}
+
+	targetToken, err := s.botToken(ctx, targetID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errMissingAuthToken:
+			return ephemeral(fmt.Sprintf(configCopyNotInstalledMsg, targetID)), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving token")
+			return CommandResult{}, err
+		}
+	}
+	targetClient := slack.New(targetToken, slack.OptionHTTPClient(httpClientOrDefault(s.HTTPClient)))
+	targetUser, err := s.fetchUserInfo(ctx, targetClient, userID)
+	if err != nil {
+		switch err.Error() {
+		case errUserNotFound:
+			return ephemeral(configCopyAdminMsg), nil
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return ephemeral(fmt.Sprintf(configCopyNotInstalledMsg, targetID)), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving user info from slack")
+			return CommandResult{}, err
+		}
+	}
+	if !targetUser.IsAdmin && !targetUser.IsOwner {
+		return ephemeral(configCopyAdminMsg), nil
+	}
+
+	source, err := s.serverConfig(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving server config")
+		return CommandResult{}, err
+	}
+	current, err := s.serverConfig(targetID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving server config")
+		return CommandResult{}, err
+	}
+	exported := exportServerConfig(source)
+	exported.AppSecret = source.AppSecret
+	cfg, err := importServerConfig(exported, current)
+	if err != nil {
+		return ephemeral(fmt.Sprintf(configCopyInvalidMsg, err)), nil
+	}
+	if cfg.authenticatedURLs() && !s.canSign(ctx, cfg) {
+		return ephemeral(authNoSigningMsg), nil
+	}
+	err = s.ServerConfigs.StoreServerConfig(targetID, cfg)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("storing server config")
+		return CommandResult{}, err
+	}
+	log.Info().
+		Str("source_team_id", teamID).
+		Str("target_team_id", targetID).
+		Msg("server config copied")
+	return ephemeral(fmt.Sprintf(configCopiedMsg, targetID)), nil
+}
@@ -0,0 +1,269 @@
+package jitsi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportedConfig runs the export subcommand as an admin and returns the
+// exported config from its reply.
+func exportedConfig(t *testing.T, s *SlashCommandHandlers) string {
+	t.Helper()
+	_, text := responseOf(t, processCommand(t, s, "export"))
+	parts := strings.Split(text, "```")
+	if len(parts) != 3 {
+		t.Fatalf("export = %q, want a config in a code block", text)
+	}
+	return parts[1]
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("users.info", adminUserInfo)
+	want := ServerConfig{
+		ConferenceHost:      "https://team.example.com",
+		MeetingDuration:     90 * time.Minute,
+		Capabilities:        Capabilities{capabilityGuest: false, capabilityLobby: true},
+		AppSecret:           "team-secret",
+		UnauthenticatedURLs: true,
+	}
+	source := &MemoryServerConfigStore{}
+	source.StoreServerConfig(testTeamID, want)
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = source
+
+	exported := exportedConfig(t, s)
+	if strings.Contains(exported, "team-secret") {
+		t.Fatalf("export = %s, want the app secret redacted", exported)
+	}
+	if !strings.Contains(exported, `"app_secret":"`+redacted+`"`) {
+		t.Errorf("export = %s, want the app secret shown as redacted", exported)
+	}
+
+	// Importing over the same team keeps its secret.
+	target := &MemoryServerConfigStore{}
+	target.StoreServerConfig(testTeamID, ServerConfig{AppSecret: "team-secret"})
+	s.ServerConfigs = target
+	if _, got := responseOf(t, processCommand(t, s, "import ```"+exported+"```")); got != configImportedMsg {
+		t.Fatalf("import = %q, want %q", got, configImportedMsg)
+	}
+	if got, _ := target.GetServerConfig(testTeamID); !reflect.DeepEqual(got, want) {
+		t.Errorf("imported %+v, want %+v", got, want)
+	}
+}
+
+func TestImportSlackEscapedConfig(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("users.info", adminUserInfo)
+	configs := &MemoryServerConfigStore{}
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = configs
+
+	text := `import {"conference_host":"<https://team.example.com|team.example.com>","meeting_duration":"45m"}`
+	if _, got := responseOf(t, processCommand(t, s, text)); got != configImportedMsg {
+		t.Fatalf("import = %q, want %q", got, configImportedMsg)
+	}
+	cfg, _ := configs.GetServerConfig(testTeamID)
+	if cfg.ConferenceHost != "https://team.example.com" || cfg.MeetingDuration != 45*time.Minute {
+		t.Errorf("imported %+v, want the unescaped host and duration", cfg)
+	}
+}
+
+func TestImportRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"not json", `meet.example.com`, "invalid character"},
+		{"unknown field", `{"host":"https://team.example.com"}`, `unknown field "host"`},
+		{"bad host", `{"conference_host":"team.example.com"}`, "conference_host must be an absolute http(s) url"},
+		{"bad duration", `{"meeting_duration":"9h"}`, "meeting_duration must be from 5m to 8h"},
+		{"unknown capability", `{"capabilities":{"teleport":true}}`, `unknown capability "teleport"`},
+		{"trailing text", `{} {}`, "unexpected text after the config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.Handle("users.info", adminUserInfo)
+			configs := &MemoryServerConfigStore{}
+			configs.StoreServerConfig(testTeamID, ServerConfig{MeetingDuration: time.Hour})
+			s := newTestHandlers(t, slack)
+			s.ServerConfigs = configs
+
+			_, got := responseOf(t, processCommand(t, s, "import "+tt.config))
+			if !strings.HasPrefix(got, "The config couldn't be imported: ") || !strings.Contains(got, tt.want) {
+				t.Errorf("import = %q, want it rejected with %q", got, tt.want)
+			}
+			if cfg, _ := configs.GetServerConfig(testTeamID); cfg.MeetingDuration != time.Hour {
+				t.Errorf("stored %+v, want the config unchanged", cfg)
+			}
+		})
+	}
+}
+
+func TestImportAuthenticatedNeedsSigning(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("users.info", adminUserInfo)
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{UnauthenticatedURLs: true})
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = configs
+	s.TokenGenerator = TokenGenerator{}
+
+	if _, got := responseOf(t, processCommand(t, s, `import {"conference_host":"https://team.example.com"}`)); got != authNoSigningMsg {
+		t.Errorf("import = %q, want %q", got, authNoSigningMsg)
+	}
+	if cfg, _ := configs.GetServerConfig(testTeamID); !cfg.UnauthenticatedURLs || cfg.ConferenceHost != "" {
+		t.Errorf("stored %+v, want the config unchanged", cfg)
+	}
+}
+
+func TestExportImportAdminOnly(t *testing.T) {
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{AppSecret: "team-secret"})
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerConfigs = configs
+
+	for _, text := range []string{"export", `import {"conference_host":"https://team.example.com"}`} {
+		if _, got := responseOf(t, processCommand(t, s, text)); got != configAdminMsg {
+			t.Errorf("%s = %q, want %q", text, got, configAdminMsg)
+		}
+	}
+	if cfg, _ := configs.GetServerConfig(testTeamID); cfg.ConferenceHost != "" {
+		t.Errorf("stored %+v, want the config unchanged", cfg)
+	}
+}
+
+func TestImportUsage(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerConfigs = &MemoryServerConfigStore{}
+	if _, got := responseOf(t, processCommand(t, s, "import")); got != fmt.Sprintf(configImportUsageMsg, "/jitsi", "/jitsi") {
+		t.Errorf("import = %q, want the usage", got)
+	}
+}
+
+const plainUserInfo = `{"ok":true,"user":{"id":"UHOST","name":"host"}}`
+
+// copyConfigHandlers returns handlers where the caller is an admin of the
+// test team and of T2, which has the bot token xoxb-t2.
+func copyConfigHandlers(t *testing.T, slack *fakeSlack, configs *MemoryServerConfigStore) *SlashCommandHandlers {
+	t.Helper()
+	slack.Handle("users.info", adminUserInfo)
+	s := newTestHandlers(t, slack)
+	s.TokenReader.(*MemoryTokenStore).Store(&TokenData{
+		TeamID:     "T2",
+		UserID:     "UHOST",
+		BotToken:   "xoxb-t2",
+		TeamDomain: "globex",
+	})
+	s.ServerConfigs = configs
+	return s
+}
+
+func TestCopyConfig(t *testing.T) {
+	want := ServerConfig{
+		ConferenceHost:      "https://team.example.com",
+		MeetingDuration:     90 * time.Minute,
+		Capabilities:        Capabilities{capabilityGuest: false},
+		AppSecret:           "team-secret",
+		UnauthenticatedURLs: true,
+	}
+	for _, target := range []string{"T2", "t2"} {
+		t.Run(target, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			configs := &MemoryServerConfigStore{}
+			configs.StoreServerConfig(testTeamID, want)
+			configs.StoreServerConfig("T2", ServerConfig{ConferenceHost: "https://other.example.com", AppSecret: "other-secret"})
+			s := copyConfigHandlers(t, slack, configs)
+
+			if _, got := responseOf(t, processCommand(t, s, "copy-config "+target)); got != fmt.Sprintf(configCopiedMsg, "T2") {
+				t.Fatalf("copy-config = %q, want it copied", got)
+			}
+			if got, _ := configs.GetServerConfig("T2"); !reflect.DeepEqual(got, want) {
+				t.Errorf("copied %+v, want %+v", got, want)
+			}
+			if got, _ := configs.GetServerConfig(testTeamID); !reflect.DeepEqual(got, want) {
+				t.Errorf("source %+v, want it unchanged", got)
+			}
+			// The caller is checked in both workspaces.
+			tokens := map[string]bool{}
+			for _, call := range slack.Calls("users.info") {
+				tokens[call.Form.Get("token")] = true
+			}
+			if !tokens[testBotToken] || !tokens["xoxb-t2"] {
+				t.Errorf("users.info called with %v, want both workspaces' tokens", tokens)
+			}
+		})
+	}
+}
+
+func TestCopyConfigNeedsAdminOfBoth(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceUser string
+		targetUser string
+		want       string
+	}{
+		{"not source admin", plainUserInfo, adminUserInfo, configCopyAdminMsg},
+		{"not target admin", adminUserInfo, plainUserInfo, configCopyAdminMsg},
+		{"not in target", adminUserInfo, `{"ok":false,"error":"user_not_found"}`, configCopyAdminMsg},
+		{"target uninstalled", adminUserInfo, `{"ok":false,"error":"invalid_auth"}`, fmt.Sprintf(configCopyNotInstalledMsg, "T2")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			configs := &MemoryServerConfigStore{}
+			configs.StoreServerConfig(testTeamID, ServerConfig{ConferenceHost: "https://team.example.com"})
+			s := copyConfigHandlers(t, slack, configs)
+			slack.HandleToken("users.info", testBotToken, tt.sourceUser)
+			slack.HandleToken("users.info", "xoxb-t2", tt.targetUser)
+
+			if _, got := responseOf(t, processCommand(t, s, "copy-config T2")); got != tt.want {
+				t.Errorf("copy-config = %q, want %q", got, tt.want)
+			}
+			if _, err := configs.GetServerConfig("T2"); err != ErrServerConfigNotFound {
+				t.Errorf("target config read %v, want nothing copied", err)
+			}
+		})
+	}
+}
+
+func TestCopyConfigRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		source ServerConfig
+		text   string
+		want   string
+	}{
+		{"usage", ServerConfig{}, "copy-config", fmt.Sprintf(configCopyUsageMsg, "/jitsi")},
+		{"several teams", ServerConfig{}, "copy-config T2 T3", fmt.Sprintf(configCopyUsageMsg, "/jitsi")},
+		{"same team", ServerConfig{}, "copy-config " + testTeamID, configCopySameMsg},
+		{"not installed", ServerConfig{}, "copy-config T3", fmt.Sprintf(configCopyNotInstalledMsg, "T3")},
+		{"invalid", ServerConfig{ConferenceHost: "team.example.com"}, "copy-config T2", fmt.Sprintf(configCopyInvalidMsg, "conference_host must be an absolute http(s) url: team.example.com")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs := &MemoryServerConfigStore{}
+			configs.StoreServerConfig(testTeamID, tt.source)
+			s := copyConfigHandlers(t, newFakeSlack(t), configs)
+
+			if _, got := responseOf(t, processCommand(t, s, tt.text)); got != tt.want {
+				t.Errorf("copy-config = %q, want %q", got, tt.want)
+			}
+			if _, err := configs.GetServerConfig("T2"); err != ErrServerConfigNotFound {
+				t.Errorf("target config read %v, want nothing copied", err)
+			}
+		})
+	}
+}
+
+func TestCopyConfigUnsupported(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	if _, got := responseOf(t, processCommand(t, s, "copy-config T2")); got != configUnsupportedMsg {
+		t.Errorf("copy-config = %q, want %q", got, configUnsupportedMsg)
+	}
+}
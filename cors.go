@@ -0,0 +1,57 @@
+package jitsi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CORS is middleware that allows browser based tools to call the service's
+// non-Slack endpoints. It shouldn't be applied to the Slack signature
+// protected routes. A CORS with no allowed origins adds no headers.
+type CORS struct {
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+}
+
+func (c *CORS) allowedOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler wraps next with CORS handling. Preflight requests from allowed
+// origins are answered without calling next.
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !c.allowedOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions &&
+			r.Header.Get("Access-Control-Request-Method") != ""
+		if !preflight {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		methods := c.AllowedMethods
+		if len(methods) == 0 {
+			methods = []string{http.MethodGet}
+		}
+		h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if len(c.AllowedHeaders) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
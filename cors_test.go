@@ -0,0 +1,114 @@
+package jitsi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testCORS = &CORS{
+	AllowedOrigins: []string{"https://admin.example.com"},
+	AllowedMethods: []string{http.MethodGet, http.MethodPost},
+	AllowedHeaders: []string{"Authorization", "Content-Type"},
+}
+
+func preflight(path, origin string) *http.Request {
+	r := httptest.NewRequest(http.MethodOptions, path, nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	return r
+}
+
+func TestCORSPreflight(t *testing.T) {
+	called := false
+	h := testCORS.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, preflight("/health", "https://admin.example.com"))
+	if w.Code != http.StatusNoContent || called {
+		t.Errorf("preflight = %d, called next %v, want 204 without next", w.Code, called)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://admin.example.com",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type",
+		"Vary":                         "Origin",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCORSRequest(t *testing.T) {
+	h := testCORS.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	r.Header.Set("Origin", "https://admin.example.com")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != "ok" {
+		t.Errorf("body = %q, want the handler's", w.Body)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://admin.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the origin", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "" {
+		t.Errorf("Access-Control-Allow-Methods = %q, want none outside a preflight", got)
+	}
+}
+
+func TestCORSIgnoresOtherOrigins(t *testing.T) {
+	for name, cors := range map[string]*CORS{
+		"other origin": testCORS,
+		"disabled":     {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			h := cors.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, preflight("/health", "https://evil.example.com"))
+			if !called {
+				t.Error("preflight answered, want it passed to next")
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+			}
+		})
+	}
+}
+
+func TestRoutesAnswerAdminPreflight(t *testing.T) {
+	mux := http.NewServeMux()
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.AdminAPIToken = "admin"
+	Routes(mux, s, &SlackOAuthHandlers{}, func(h http.Handler) http.Handler { return h }, testCORS)
+
+	for _, path := range []string{PathInstallURL, PathMetrics} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, preflight(path, "https://admin.example.com"))
+		if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") == "" {
+			t.Errorf("%s preflight = %d %v, want a 204 cors answer", path, w.Code, w.Header())
+		}
+
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Origin", "https://admin.example.com")
+		r.Header.Set("Authorization", "Bearer admin")
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://admin.example.com" {
+			t.Errorf("%s = %d %v, want a 200 with cors headers", path, w.Code, w.Header())
+		}
+	}
+
+	for _, path := range []string{PathSlashCommand, PathInteraction, PathEvents, PathOAuth} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, preflight(path, "https://admin.example.com"))
+		if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s preflight = %d %v, want a 405 without cors", path, w.Code, w.Header())
+		}
+	}
+}
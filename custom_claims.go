@@ -0,0 +1,41 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// reservedClaims are set by CreateJWT and can't be replaced by custom claims.
+var reservedClaims = map[string]bool{
+	"iss":     true,
+	"nbf":     true,
+	"exp":     true,
+	"sub":     true,
+	"aud":     true,
+	"room":    true,
+	"context": true,
+}
+
+// ParseCustomClaims decodes custom token claims from a json object, i.e.
+// '{"tenant_tier":"premium"}'. Empty text has no custom claims.
+func ParseCustomClaims(text string) (map[string]interface{}, error) {
+	if text == "" {
+		return nil, nil
+	}
+	var claims map[string]interface{}
+	err := json.Unmarshal([]byte(text), &claims)
+	if err != nil {
+		return nil, fmt.Errorf("custom claims must be a json object: %v", err)
+	}
+	return claims, validateCustomClaims(claims)
+}
+
+// validateCustomClaims checks that custom claims don't name a reserved claim.
+func validateCustomClaims(claims map[string]interface{}) error {
+	for name := range claims {
+		if reservedClaims[name] {
+			return fmt.Errorf("custom claims can't set the reserved %s claim", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package jitsi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCustomClaims(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		want  map[string]interface{}
+		valid bool
+	}{
+		{"empty", "", nil, true},
+		{"object", `{"tier":"premium","seats":5}`, map[string]interface{}{"tier": "premium", "seats": float64(5)}, true},
+		{"not json", `tier=premium`, nil, false},
+		{"not an object", `["premium"]`, nil, false},
+		{"reserved", `{"tier":"premium","room":"*"}`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCustomClaims(tt.text)
+			if !tt.valid {
+				if err == nil {
+					t.Errorf("ParseCustomClaims(%q) = %v, want an error", tt.text, got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCustomClaims(%q) = %v, %v, want %v", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomClaimsMerged(t *testing.T) {
+	g := testTokenGenerator(t)
+	g.CustomClaims = map[string]interface{}{
+		"tier":     "premium",
+		"features": map[string]interface{}{"recording": true},
+	}
+	claims := createTestJWT(t, g, JWTInput{TenantName: "acme", RoomClaim: "room", UserID: "UHOST"})
+	if claims["tier"] != "premium" {
+		t.Errorf("tier claim = %v, want premium", claims["tier"])
+	}
+	if got := claims["features"]; !reflect.DeepEqual(got, map[string]interface{}{"recording": true}) {
+		t.Errorf("features claim = %v, want it kept as an object", got)
+	}
+	if claims["room"] != "room" || claims["sub"] != "acme" {
+		t.Errorf("claims = %v, want the room and sub set as usual", claims)
+	}
+}
+
+func TestCustomClaimsCantReplaceReserved(t *testing.T) {
+	g := testTokenGenerator(t)
+	want := createTestJWT(t, g, JWTInput{TenantName: "acme", RoomClaim: "room", UserID: "UHOST"})
+	g.CustomClaims = map[string]interface{}{}
+	for name := range reservedClaims {
+		g.CustomClaims[name] = "custom"
+	}
+	got := createTestJWT(t, g, JWTInput{TenantName: "acme", RoomClaim: "room", UserID: "UHOST"})
+	for name := range reservedClaims {
+		if got[name] == "custom" {
+			t.Errorf("%s claim = custom, want the reserved claim kept", name)
+		}
+	}
+	for _, name := range []string{"room", "sub", "iss", "aud"} {
+		if got[name] != want[name] {
+			t.Errorf("%s claim = %v, want %v", name, got[name], want[name])
+		}
+	}
+}
+
+func TestCustomClaimsInMeetingTokens(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	g := testTokenGenerator(t)
+	g.CustomClaims = map[string]interface{}{"tier": "premium"}
+	s.TokenGenerator = g
+
+	result := processCommand(t, s, "<@UBOB>")
+	if got := tokenClaims(t, hostURL(t, result))["tier"]; got != "premium" {
+		t.Errorf("host token tier claim = %v, want premium", got)
+	}
+	if got := tokenClaims(t, inviteURL(t, slack.Calls("chat.postMessage")[0]))["tier"]; got != "premium" {
+		t.Errorf("invite token tier claim = %v, want premium", got)
+	}
+}
@@ -0,0 +1,78 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+// DialIn is how people join meetings by phone on deployments with SIP
+// dial-in.
+type DialIn struct {
+	// Numbers are the phone numbers to dial in on, i.e. "US: +1 512 402 2718".
+	Numbers []string
+	// MapperURL is a Jitsi conference mapper url that's queried for the PIN
+	// of a room with a conference parameter. Rooms have no PIN when it's
+	// empty.
+	MapperURL string
+}
+
+type conferenceMapping struct {
+	ID int64 `json:"id"`
+}
+
+// pin looks up the dial-in PIN of a room of a tenant from the conference
+// mapper.
+func (d *DialIn) pin(ctx context.Context, client *http.Client, confHost, tenant, room string) (string, error) {
+	host, err := url.Parse(confHost)
+	if err != nil {
+		return "", err
+	}
+	// Jitsi names tenant rooms room@conference.tenant.domain.
+	conference := fmt.Sprintf("%s@conference.%s.%s", strings.ToLower(room), tenant, host.Hostname())
+	req, err := http.NewRequest(http.MethodGet, d.MapperURL+"?conference="+url.QueryEscape(conference), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClientOrDefault(client).Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	var mapping conferenceMapping
+	err = json.NewDecoder(resp.Body).Decode(&mapping)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d#", mapping.ID), nil
+}
+
+// dialInField describes how to join a room by phone for invites, or is nil
+// when DialIn isn't configured. A PIN that can't be looked up is logged and
+// left out so the invite is still sent.
+func (s *SlashCommandHandlers) dialInField(ctx context.Context, confHost, tenant, room string) *slack.AttachmentField {
+	if s.DialIn == nil || len(s.DialIn.Numbers) == 0 {
+		return nil
+	}
+	value := strings.Join(s.DialIn.Numbers, "\n")
+	if s.DialIn.MapperURL != "" {
+		pin, err := s.DialIn.pin(ctx, s.HTTPClient, confHost, tenant, room)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().
+				Err(err).
+				Msg("retrieving dial-in pin")
+		} else {
+			value += "\nPIN: " + pin
+		}
+	}
+	return &slack.AttachmentField{Title: "Dial-in", Value: value}
+}
@@ -0,0 +1,112 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// inviteFields returns the fields of the invite attachment posted by call,
+// keyed by title.
+func inviteFields(t *testing.T, call slackCall) map[string]string {
+	t.Helper()
+	var invite []struct {
+		Fields []struct {
+			Title string `json:"title"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(call.Form.Get("attachments")), &invite); err != nil || len(invite) == 0 {
+		t.Fatalf("decoding invite %s: %v", call.Form.Get("attachments"), err)
+	}
+	fields := map[string]string{}
+	for _, field := range invite[0].Fields {
+		fields[field.Title] = field.Value
+	}
+	return fields
+}
+
+// conferenceMapper serves a conference mapper answering with body and
+// status, recording the conferences it's asked for.
+func conferenceMapper(t *testing.T, status int, body string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var conferences []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conferences = append(conferences, r.URL.Query().Get("conference"))
+		mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return conferences
+	}
+}
+
+func TestDialInField(t *testing.T) {
+	numbers := []string{"US: +1 512 402 2718", "DE: +49 89 380 38719"}
+	tests := []struct {
+		name   string
+		mapper bool
+		status int
+		body   string
+		want   string
+	}{
+		{"numbers", false, 0, "", strings.Join(numbers, "\n")},
+		{"pin", true, http.StatusOK, `{"message":"Successfully retrieved conference mapping","id":1234567,"conference":"room"}`, strings.Join(numbers, "\n") + "\nPIN: 1234567#"},
+		{"mapper failing", true, http.StatusInternalServerError, "", strings.Join(numbers, "\n")},
+		{"mapper garbled", true, http.StatusOK, "nope", strings.Join(numbers, "\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.DialIn = &DialIn{Numbers: numbers}
+			var asked func() []string
+			if tt.mapper {
+				var srv *httptest.Server
+				srv, asked = conferenceMapper(t, tt.status, tt.body)
+				s.DialIn.MapperURL = srv.URL
+			}
+
+			result := processCommand(t, s, "<@UBOB>")
+			posted := slack.Calls("chat.postMessage")
+			if len(posted) != 1 {
+				t.Fatalf("chat.postMessage calls = %d, want the invite sent", len(posted))
+			}
+			if got := inviteFields(t, posted[0])["Dial-in"]; got != tt.want {
+				t.Errorf("dial-in = %q, want %q", got, tt.want)
+			}
+			if tt.mapper {
+				want := strings.ToLower(result.Room) + "@conference.acme.meet.example.com"
+				if got := asked(); len(got) != 1 || got[0] != want {
+					t.Errorf("mapper asked for %v, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNoDialIn(t *testing.T) {
+	for name, dialIn := range map[string]*DialIn{"unset": nil, "no numbers": {MapperURL: "https://meet.example.com/conferenceMapper"}} {
+		t.Run(name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.DialIn = dialIn
+
+			processCommand(t, s, "<@UBOB>")
+			if _, ok := inviteFields(t, slack.Calls("chat.postMessage")[0])["Dial-in"]; ok {
+				t.Error("invite has a dial-in field, want none")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package jitsi
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+)
+
+const (
+	// NameFieldHandle names conference users by their Slack handle.
+	NameFieldHandle = "handle"
+	// NameFieldRealName names conference users by their full name.
+	NameFieldRealName = "real_name"
+	// NameFieldDisplayName names conference users by their profile display name.
+	NameFieldDisplayName = "display_name"
+)
+
+// conferenceName returns the name a user is shown by in a conference. The
+// NameField is used when the user has set it, otherwise the first set of
+// real name, display name and handle is used.
+func (s *SlashCommandHandlers) conferenceName(user *slack.User) string {
+	fields := map[string]string{
+		NameFieldHandle:      user.Name,
+		NameFieldRealName:    user.Profile.RealName,
+		NameFieldDisplayName: user.Profile.DisplayName,
+	}
+	if name := fields[s.NameField]; name != "" {
+		return name
+	}
+	for _, field := range []string{NameFieldRealName, NameFieldDisplayName, NameFieldHandle} {
+		if fields[field] != "" {
+			return fields[field]
+		}
+	}
+	return ""
+}
+
+func validateNameField(field string) error {
+	switch field {
+	case "", NameFieldHandle, NameFieldRealName, NameFieldDisplayName:
+		return nil
+	}
+	return fmt.Errorf("unknown name field %q", field)
+}
@@ -0,0 +1,50 @@
+package jitsi
+
+import (
+	"testing"
+
+	"github.com/nlopes/slack"
+)
+
+func TestConferenceName(t *testing.T) {
+	full := &slack.User{Name: "bob", Profile: slack.UserProfile{RealName: "Bob Smith", DisplayName: "Bobby"}}
+	handleOnly := &slack.User{Name: "bob"}
+	noRealName := &slack.User{Name: "bob", Profile: slack.UserProfile{DisplayName: "Bobby"}}
+	tests := []struct {
+		name  string
+		field string
+		user  *slack.User
+		want  string
+	}{
+		{"default", "", full, "Bob Smith"},
+		{"handle", NameFieldHandle, full, "bob"},
+		{"real name", NameFieldRealName, full, "Bob Smith"},
+		{"display name", NameFieldDisplayName, full, "Bobby"},
+		{"real name unset", NameFieldRealName, noRealName, "Bobby"},
+		{"display name unset", NameFieldDisplayName, handleOnly, "bob"},
+		{"default without real name", "", noRealName, "Bobby"},
+		{"default with handle only", "", handleOnly, "bob"},
+		{"nothing set", NameFieldRealName, &slack.User{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SlashCommandHandlers{NameField: tt.field}
+			if got := s.conferenceName(tt.user); got != tt.want {
+				t.Errorf("conferenceName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConferenceNameInToken(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Users["UHOST"] = `{"ok":true,"user":{"id":"UHOST","name":"host","profile":{"real_name":"Host Person","display_name":"hosty"}}}`
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.NameField = NameFieldDisplayName
+
+	claims := tokenClaims(t, hostURL(t, processCommand(t, s, "<@UBOB>")))
+	if got := contextOf(t, claims).User.DisplayName; got != "hosty" {
+		t.Errorf("token name = %q, want the display name", got)
+	}
+}
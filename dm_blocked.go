@@ -0,0 +1,47 @@
+package jitsi
+
+import (
+	"fmt"
+	"sort"
+)
+
+const dmBlockedMsg = "⚠️ Invites couldn't be sent to %s since %s."
+
+// dmBlockedReasons describe the Slack errors returned when a user can't be
+// sent a DM by the app.
+var dmBlockedReasons = map[string]string{
+	"cannot_dm_bot":         "bots can't be sent a DM",
+	"messages_tab_disabled": "they've turned off messages from apps",
+	"user_disabled":         "their account is deactivated",
+	"restricted_action":     "the workspace doesn't allow apps to DM them",
+}
+
+// DMBlockedError is returned when an invitee can't be sent a DM with their
+// invite.
+type DMBlockedError struct {
+	UserID string
+	// Code is the Slack error the DM failed with.
+	Code string
+}
+
+func (e *DMBlockedError) Error() string {
+	return fmt.Sprintf("unable to dm %s: %s", e.UserID, e.Code)
+}
+
+// dmBlockedNotes describes why each group of invitees couldn't be sent a DM.
+func dmBlockedNotes(blocked []*DMBlockedError) []string {
+	byCode := map[string][]string{}
+	for _, err := range blocked {
+		byCode[err.Code] = append(byCode[err.Code], err.UserID)
+	}
+	var codes []string
+	for code := range byCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	var notes []string
+	for _, code := range codes {
+		notes = append(notes, fmt.Sprintf(dmBlockedMsg, mentions(byCode[code]), dmBlockedReasons[code]))
+	}
+	return notes
+}
@@ -0,0 +1,97 @@
+package jitsi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDMBlockedInvitees(t *testing.T) {
+	for code, reason := range dmBlockedReasons {
+		for _, method := range []string{"conversations.open", "chat.postMessage"} {
+			t.Run(method+" "+code, func(t *testing.T) {
+				slack := newFakeSlack(t)
+				slack.AddUser("UBOB", "bob")
+				slack.AddUser("UALICE", "alice")
+				slack.Handle(method, `{"ok":false,"error":"`+code+`"}`)
+				s := newTestHandlers(t, slack)
+
+				result := processCommand(t, s, "<@UBOB> <@UALICE>")
+				_, text := responseOf(t, result)
+				if want := fmt.Sprintf(dmBlockedMsg, "<@UBOB>, <@UALICE>", reason); !strings.Contains(text, want) {
+					t.Errorf("reply = %q, want %q", text, want)
+				}
+				if strings.Contains(text, "couldn't be delivered") || result.Room == "" {
+					t.Errorf("reply = %q, want the blocked invitees reported only as blocked", text)
+				}
+			})
+		}
+	}
+}
+
+func TestOtherDMErrorsFailInvites(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.Handle("conversations.open", `{"ok":false,"error":"channel_not_found"}`)
+	s := newTestHandlers(t, slack)
+
+	_, text := responseOf(t, processCommand(t, s, "<@UBOB>"))
+	if want := fmt.Sprintf(failedInvitesMsg, "<@UBOB>"); !strings.Contains(text, want) {
+		t.Errorf("reply = %q, want %q", text, want)
+	}
+	if strings.Contains(text, "since") {
+		t.Errorf("reply = %q, want no dm blocked reason", text)
+	}
+}
+
+func TestDMBlockedFallsBackToEphemeral(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.Handle("conversations.open", `{"ok":false,"error":"messages_tab_disabled"}`)
+	s := newTestHandlers(t, slack)
+	s.DMFallbackEphemeral = true
+
+	result := processCommand(t, s, "<@UBOB>")
+	posted := slack.Calls("chat.postEphemeral")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postEphemeral calls = %d, want the invite in the channel", len(posted))
+	}
+	if channel, user := posted[0].Form.Get("channel"), posted[0].Form.Get("user"); channel != "C1" || user != "UBOB" {
+		t.Errorf("ephemeral invite in %s for %s, want C1 for UBOB", channel, user)
+	}
+	if !strings.Contains(inviteURL(t, posted[0]), "/"+result.Room+"?jwt=") {
+		t.Errorf("ephemeral invite url = %s, want the meeting", inviteURL(t, posted[0]))
+	}
+	if _, text := responseOf(t, result); strings.Contains(text, "⚠️") {
+		t.Errorf("reply = %q, want the invite counted as delivered", text)
+	}
+}
+
+func TestDMBlockedEphemeralFallbackFails(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.Handle("conversations.open", `{"ok":false,"error":"messages_tab_disabled"}`)
+	slack.Handle("chat.postEphemeral", `{"ok":false,"error":"user_not_in_channel"}`)
+	s := newTestHandlers(t, slack)
+	s.DMFallbackEphemeral = true
+
+	_, text := responseOf(t, processCommand(t, s, "<@UBOB>"))
+	if want := fmt.Sprintf(dmBlockedMsg, "<@UBOB>", dmBlockedReasons["messages_tab_disabled"]); !strings.Contains(text, want) {
+		t.Errorf("reply = %q, want %q", text, want)
+	}
+}
+
+func TestDMBlockedNotes(t *testing.T) {
+	got := dmBlockedNotes([]*DMBlockedError{
+		{UserID: "UBOB", Code: "messages_tab_disabled"},
+		{UserID: "UBOT", Code: "cannot_dm_bot"},
+		{UserID: "UALICE", Code: "messages_tab_disabled"},
+	})
+	want := []string{
+		fmt.Sprintf(dmBlockedMsg, "<@UBOT>", dmBlockedReasons["cannot_dm_bot"]),
+		fmt.Sprintf(dmBlockedMsg, "<@UBOB>, <@UALICE>", dmBlockedReasons["messages_tab_disabled"]),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("dmBlockedNotes = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package jitsi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// dynamoItem is a dynamodb item as it's sent over the wire.
+type dynamoItem map[string]json.RawMessage
+
+// fakeDynamo emulates the dynamodb GetItem, PutItem, DeleteItem and
+// single attribute equality Query operations for tests. Items are kept per table in the order they're put.
+type fakeDynamo struct {
+	mu     sync.Mutex
+	tables map[string][]dynamoItem
+}
+
+// newFakeDynamo returns a fake and a client sending requests to it.
+func newFakeDynamo(t *testing.T) (*fakeDynamo, *dynamodb.DynamoDB) {
+	t.Helper()
+	f := &fakeDynamo{tables: map[string][]dynamoItem{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:    aws.String(srv.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("test", "test", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, dynamodb.New(sess)
+}
+
+// Items returns the items stored in a table.
+func (f *fakeDynamo) Items(table string) []dynamoItem {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]dynamoItem(nil), f.tables[table]...)
+}
+
+// matches reports whether an item has every attribute of key.
+func (item dynamoItem) matches(key dynamoItem) bool {
+	for name, value := range key {
+		if !bytes.Equal(item[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *fakeDynamo) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TableName                 string
+		Item                      dynamoItem
+		Key                       dynamoItem
+		KeyConditionExpression    string
+		ExpressionAttributeNames  map[string]string
+		ExpressionAttributeValues dynamoItem
+		Limit                     int
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(body, &req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	items := f.tables[req.TableName]
+	switch op := r.Header.Get("X-Amz-Target"); {
+	case strings.HasSuffix(op, ".PutItem"):
+		key := dynamoItem{KeyTeamID: req.Item[KeyTeamID]}
+		for _, name := range []string{KeyUserID, KeyChannel, KeyTemplate} {
+			if value, ok := req.Item[name]; ok {
+				key[name] = value
+			}
+		}
+		kept := items[:0]
+		for _, item := range items {
+			if !item.matches(key) {
+				kept = append(kept, item)
+			}
+		}
+		f.tables[req.TableName] = append(kept, req.Item)
+		w.Write([]byte(`{}`))
+	case strings.HasSuffix(op, ".GetItem"):
+		for _, item := range items {
+			if item.matches(req.Key) {
+				json.NewEncoder(w).Encode(map[string]dynamoItem{"Item": item})
+				return
+			}
+		}
+		w.Write([]byte(`{}`))
+	case strings.HasSuffix(op, ".Query"):
+		var name, value string
+		fmt.Sscanf(req.KeyConditionExpression, "%s = %s", &name, &value)
+		key := dynamoItem{req.ExpressionAttributeNames[name]: req.ExpressionAttributeValues[value]}
+		found := []dynamoItem{}
+		for _, item := range items {
+			if item.matches(key) && (req.Limit == 0 || len(found) < req.Limit) {
+				found = append(found, item)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Items": found, "Count": len(found)})
+	case strings.HasSuffix(op, ".DeleteItem"):
+		kept := items[:0]
+		for _, item := range items {
+			if !item.matches(req.Key) {
+				kept = append(kept, item)
+			}
+		}
+		f.tables[req.TableName] = kept
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"com.amazon.coral.validate#ValidationException","message":"unsupported operation"}`))
+	}
+}
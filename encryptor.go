@@ -0,0 +1,64 @@
+package jitsi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errNoEncryptor is returned when a secret would be stored without an
+// Encryptor to encrypt it.
+var errNoEncryptor = errors.New("an encryptor is needed to store secrets")
+
+// Encryptor encrypts secrets such as team app secrets before they're stored
+// and decrypts them when they're read.
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// AESEncryptor encrypts with AES-GCM. Every ciphertext starts with the
+// random nonce it was sealed with.
+type AESEncryptor struct {
+	aead cipher.AEAD
+}
+
+// NewAESEncryptor creates an AESEncryptor from a base64 encoded 16, 24 or
+// 32 byte key.
+func NewAESEncryptor(key string) (*AESEncryptor, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key isn't base64: %v", err)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESEncryptor{aead: aead}, nil
+}
+
+// Encrypt seals plaintext with a new random nonce.
+func (e *AESEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens a ciphertext made by Encrypt.
+func (e *AESEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < e.aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:e.aead.NonceSize()], ciphertext[e.aead.NonceSize():]
+	return e.aead.Open(nil, nonce, sealed, nil)
+}
@@ -0,0 +1,274 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+const (
+	eventTypeURLVerification = "url_verification"
+	eventTypeCallback        = "event_callback"
+	eventTypeReactionAdded   = "reaction_added"
+)
+
+type eventEnvelope struct {
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	TeamID    string          `json:"team_id"`
+	Event     json.RawMessage `json:"event"`
+}
+
+type reactionEvent struct {
+	Type     string `json:"type"`
+	User     string `json:"user"`
+	Reaction string `json:"reaction"`
+	ItemUser string `json:"item_user"`
+	Item     struct {
+		Channel string `json:"channel"`
+	} `json:"item"`
+}
+
+// Events handles Slack Events API requests. Reacting to a message with the
+// ReactionTrigger emoji starts a meeting and invites the reactor and the
+// message's author. Executing the start meeting workflow step creates a
+// meeting and outputs its url to the workflow. Opening the app's Home tab
+// shows a button that starts a meeting. Mentioning the app with 'start'
+// runs the rest of the text as a slash command.
+func (s *SlashCommandHandlers) Events(w http.ResponseWriter, r *http.Request) {
+	if !s.validRequest(w, r) {
+		return
+	}
+	s.handleEvents(w, r)
+}
+
+// handleEvents processes an Events API request that has already been
+// verified as originating from Slack.
+func (s *SlashCommandHandlers) handleEvents(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var envelope eventEnvelope
+	err = json.Unmarshal(body, &envelope)
+	if err != nil {
+		hlog.FromRequest(r).Error().
+			Err(err).
+			Msg("unable to decode event")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch envelope.Type {
+	case eventTypeURLVerification:
+		w.Header().Set("Content-type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(envelope.Challenge))
+		return
+	case eventTypeCallback:
+		var event reactionEvent
+		err = json.Unmarshal(envelope.Event, &event)
+		if err != nil {
+			hlog.FromRequest(r).Error().
+				Err(err).
+				Msg("unable to decode event")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if event.Type == eventTypeWorkflowStepExecute {
+			var step workflowStepExecuteEvent
+			err = json.Unmarshal(envelope.Event, &step)
+			if err != nil {
+				hlog.FromRequest(r).Error().
+					Err(err).
+					Msg("unable to decode event")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if step.CallbackID == workflowStepCallbackID {
+				ctx := hlog.FromRequest(r).WithContext(context.Background())
+				executeID := step.WorkflowStep.WorkflowStepExecuteID
+				if !s.async(ctx, func() { s.executeWorkflowStep(ctx, envelope.TeamID, step) }) {
+					s.eventBusy(ctx, envelope.TeamID, "workflows.stepFailed", map[string]interface{}{
+						"workflow_step_execute_id": executeID,
+						"error":                    map[string]string{"message": busyMsg},
+					})
+				}
+			}
+		}
+		if event.Type == eventTypeAppHomeOpened {
+			var opened appHomeOpenedEvent
+			err = json.Unmarshal(envelope.Event, &opened)
+			if err != nil {
+				hlog.FromRequest(r).Error().
+					Err(err).
+					Msg("unable to decode event")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if opened.Tab == appHomeTab {
+				ctx := hlog.FromRequest(r).WithContext(context.Background())
+				if !s.async(ctx, func() { s.publishHome(ctx, envelope.TeamID, opened) }) {
+					text, _ := json.Marshal(busyMsg)
+					s.eventBusy(ctx, envelope.TeamID, "views.publish", map[string]interface{}{
+						"user_id": opened.User,
+						"view":    json.RawMessage(fmt.Sprintf(homeBusyView, text)),
+					})
+				}
+			}
+		}
+		if event.Type == eventTypeAppMention {
+			var mention appMentionEvent
+			err = json.Unmarshal(envelope.Event, &mention)
+			if err != nil {
+				hlog.FromRequest(r).Error().
+					Err(err).
+					Msg("unable to decode event")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			ctx := hlog.FromRequest(r).WithContext(context.Background())
+			if !s.async(ctx, func() { s.mentionMeeting(ctx, envelope.TeamID, mention) }) {
+				s.eventBusy(ctx, envelope.TeamID, "chat.postEphemeral", map[string]interface{}{
+					"channel": mention.Channel,
+					"user":    mention.User,
+					"text":    busyMsg,
+				})
+			}
+		}
+		if s.triggersMeeting(event) {
+			// Slack expects events to be acknowledged within 3 seconds
+			// so the meeting is started after responding.
+			ctx := hlog.FromRequest(r).WithContext(context.Background())
+			if !s.async(ctx, func() { s.reactionMeeting(ctx, envelope.TeamID, event) }) && event.Item.Channel != "" {
+				s.eventBusy(ctx, envelope.TeamID, "chat.postEphemeral", map[string]interface{}{
+					"channel": event.Item.Channel,
+					"user":    event.User,
+					"text":    busyMsg,
+				})
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// triggersMeeting reports whether an event is a reaction with the trigger
+// emoji. Skin tone variants of the emoji also trigger a meeting.
+func (s *SlashCommandHandlers) triggersMeeting(event reactionEvent) bool {
+	if s.ReactionTrigger == "" || event.Type != eventTypeReactionAdded || event.User == "" {
+		return false
+	}
+	reaction := strings.SplitN(event.Reaction, "::", 2)[0]
+	return reaction == strings.Trim(s.ReactionTrigger, ":")
+}
+
+// eventBusy tells the user an event was dropped since the workers are
+// saturated. Events have no response url, so the reply is made with the
+// bot token by calling a Slack api method.
+func (s *SlashCommandHandlers) eventBusy(ctx context.Context, teamID, method string, body map[string]interface{}) {
+	log := zerolog.Ctx(ctx)
+	token, err := s.botToken(ctx, teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving token")
+		return
+	}
+	err = s.callSlackAPI(ctx, token, method, body)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("method", method).
+			Msg("responding that workers are busy")
+	}
+}
+
+// reactionMeeting starts a meeting for a reaction and DMs a link to the
+// reactor and the author of the message that was reacted to.
+func (s *SlashCommandHandlers) reactionMeeting(ctx context.Context, teamID string, event reactionEvent) {
+	log := zerolog.Ctx(ctx)
+	if s.MaintenanceMode {
+		return
+	}
+	// The capability is checked before any Slack call so teams that turned
+	// reaction meetings off don't spend rate limit on every trigger emoji.
+	serverCfg, err := s.teamServerConfig(ctx, teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("reaction meeting not started")
+		return
+	}
+	if !serverCfg.Capabilities.Enabled(capabilityReactionMeetings) {
+		log.Info().
+			Msg("reaction meetings aren't enabled")
+		return
+	}
+
+	token, err := s.botToken(ctx, teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving token")
+		return
+	}
+	features, err := s.teamFeatures(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving features")
+		return
+	}
+
+	slackClient := slack.New(token, slack.OptionHTTPClient(httpClientOrDefault(s.HTTPClient)))
+	allowed, err := s.canHost(ctx, slackClient, teamID, event.User)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("checking host policy")
+		return
+	}
+	if !allowed {
+		log.Info().
+			Str("user_id", event.User).
+			Msg("reaction from a user not allowed to host")
+		return
+	}
+	var team *slack.TeamInfo
+	err = s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		team, err = slackClient.GetTeamInfoContext(ctx)
+		return err
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving team info from slack")
+		return
+	}
+
+	room := s.roomName(RandomName())
+	invitees := []string{event.User}
+	if event.ItemUser != "" && event.ItemUser != event.User {
+		invitees = append(invitees, event.ItemUser)
+	}
+	for _, invitee := range invitees {
+		// Nobody is sent a moderator link, so there'd be nobody to admit
+		// invitees from a lobby.
+		err = s.inviteUser(ctx, slackClient, token, serverCfg, event.User, invitee, "", teamID, team.Domain, room, false, features, s.MaxOccupants)
+		if err != nil && err != errBotInvitee {
+			log.Error().
+				Err(err).
+				Msg("inviting user")
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestTriggersMeeting(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger string
+		event   reactionEvent
+		want    bool
+	}{
+		{"matching", "video_camera", reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "video_camera"}, true},
+		{"colons in trigger", ":video_camera:", reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "video_camera"}, true},
+		{"skin tone", "wave", reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "wave::skin-tone-2"}, true},
+		{"other emoji", "video_camera", reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "tada"}, false},
+		{"prefix of trigger", "video_camera", reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "video"}, false},
+		{"removed", "video_camera", reactionEvent{Type: "reaction_removed", User: "UBOB", Reaction: "video_camera"}, false},
+		{"no user", "video_camera", reactionEvent{Type: eventTypeReactionAdded, Reaction: "video_camera"}, false},
+		{"no trigger", "", reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "video_camera"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SlashCommandHandlers{ReactionTrigger: tt.trigger}
+			if got := s.triggersMeeting(tt.event); got != tt.want {
+				t.Errorf("triggersMeeting(%+v) = %v, want %v", tt.event, got, tt.want)
+			}
+		})
+	}
+}
+
+// reactionTo builds an event for UBOB reacting to a message by UALICE.
+func reactionTo(reaction string) string {
+	return `{"type":"event_callback","team_id":"T1","event":{"type":"reaction_added","user":"UBOB","reaction":"` + reaction + `","item_user":"UALICE","item":{"type":"message","channel":"C1"}}}`
+}
+
+func TestReactionStartsMeeting(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	s := newTestHandlers(t, slack)
+	s.ReactionTrigger = "video_camera"
+
+	w := httptest.NewRecorder()
+	s.Events(w, signedRequest(t, PathEvents, "application/json", reactionTo("video_camera")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	// The meeting is started after the event is acknowledged.
+	deadline := time.Now().Add(5 * time.Second)
+	posted := slack.Calls("chat.postMessage")
+	for len(posted) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		posted = slack.Calls("chat.postMessage")
+	}
+	if len(posted) != 2 {
+		t.Fatalf("chat.postMessage calls = %d, want an invite for the reactor and the message author", len(posted))
+	}
+	channels := map[string]bool{}
+	rooms := map[string]bool{}
+	for _, call := range posted {
+		channels[call.Form.Get("channel")] = true
+		invite := inviteURL(t, call)
+		if !strings.HasPrefix(invite, testConfHost+"/acme/") {
+			t.Errorf("invite url = %s, want a meeting on the team's server", invite)
+		}
+		rooms[strings.SplitN(invite, "?", 2)[0]] = true
+	}
+	if !channels["DUBOB"] || !channels["DUALICE"] {
+		t.Errorf("invites posted to %v, want the reactor and the message author", channels)
+	}
+	if len(rooms) != 1 {
+		t.Errorf("invites are for rooms %v, want one meeting", rooms)
+	}
+}
+
+func TestReactionMeetingDMsAuthorOnce(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+
+	s.reactionMeeting(context.Background(), testTeamID, reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "video_camera", ItemUser: "UBOB"})
+	if posted := slack.Calls("chat.postMessage"); len(posted) != 1 || posted[0].Form.Get("channel") != "DUBOB" {
+		t.Errorf("chat.postMessage calls = %+v, want one invite to bob", posted)
+	}
+}
+
+func TestReactionWithOtherEmojiIgnored(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.ReactionTrigger = "video_camera"
+	s.Workers = &WorkerPool{Size: 1, Queue: 1}
+
+	for _, reaction := range []string{"tada", "thumbsup"} {
+		w := httptest.NewRecorder()
+		s.Events(w, signedRequest(t, PathEvents, "application/json", reactionTo(reaction)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+	if queued := s.Workers.Stats().Queued; queued != 0 {
+		t.Errorf("queued = %d, want no meetings started", queued)
+	}
+	if posted := slack.Calls("chat.postMessage"); len(posted) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want none", len(posted))
+	}
+}
+
+func TestReactionMeetingChecksCapabilityFirst(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs ServerConfigStore
+		log     string
+	}{
+		{"disabled", stubServerConfigs{cfg: ServerConfig{Capabilities: Capabilities{capabilityReactionMeetings: false}}}, "reaction meetings aren't enabled"},
+		{"store error", stubServerConfigs{err: errors.New("table unavailable")}, "reaction meeting not started"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.ServerConfigs = tt.configs
+			s.HostPolicy = HostPolicy{Admins: true}
+			var logs bytes.Buffer
+			ctx := zerolog.New(&logs).WithContext(context.Background())
+
+			s.reactionMeeting(ctx, testTeamID, reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "video_camera", ItemUser: "UALICE"})
+			for _, method := range []string{"users.info", "team.info", "conversations.open", "chat.postMessage"} {
+				if calls := slack.Calls(method); len(calls) != 0 {
+					t.Errorf("%s calls = %d, want none", method, len(calls))
+				}
+			}
+			if !strings.Contains(logs.String(), tt.log) {
+				t.Errorf("logs = %s, want %q", logs.String(), tt.log)
+			}
+		})
+	}
+}
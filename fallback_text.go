@@ -0,0 +1,60 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	defaultRoomFallback = "Meeting started in {{.Room}}"
+	defaultHostFallback = "Invitations have been sent for your meeting in {{.Room}}."
+)
+
+// FallbackText holds the text/templates of the fallback text Slack shows in
+// notifications and clients that can't render a message's attachments.
+// They're rendered with the same Host, Server and Room as the InviteText.
+type FallbackText struct {
+	// Room is the fallback of meetings posted to a channel. It defaults to
+	// "Meeting started in {{.Room}}".
+	Room string
+	// Host is the fallback of the reply to a host who sent invites. It
+	// defaults to "Invitations have been sent for your meeting in {{.Room}}."
+	Host string
+	// Invite is the fallback of invites. It defaults to the invite text.
+	Invite string
+}
+
+// fallback renders a fallback text template, or def when it isn't set.
+// Templates are checked at startup so a render failure falls back to def
+// rather than failing the message.
+func fallback(text, def, hostID, confHost, room string) string {
+	if text != "" {
+		rendered, err := renderText(text, hostID, confHost, room)
+		if err == nil {
+			return rendered
+		}
+	}
+	rendered, _ := renderText(def, hostID, confHost, room)
+	return rendered
+}
+
+// jsonFallback renders a fallback text template as a JSON string for
+// message templates.
+func jsonFallback(text, def, hostID, confHost, room string) string {
+	encoded, _ := json.Marshal(fallback(text, def, hostID, confHost, room))
+	return string(encoded)
+}
+
+// validate checks that the fallback text templates render.
+func (f FallbackText) validate() error {
+	for name, text := range map[string]string{"room": f.Room, "host": f.Host, "invite": f.Invite} {
+		if text == "" {
+			continue
+		}
+		_, err := renderText(text, "U0000000", "https://meet.example.com", "ExampleRoom")
+		if err != nil {
+			return fmt.Errorf("invalid %s fallback text: %v", name, err)
+		}
+	}
+	return nil
+}
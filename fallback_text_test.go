@@ -0,0 +1,98 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// attachmentFallback returns the fallback of the first attachment of a
+// reply, or of an attachments list as invites are posted with.
+func attachmentFallback(t *testing.T, body string) string {
+	t.Helper()
+	var msg []struct {
+		Fallback string `json:"fallback"`
+	}
+	var reply struct {
+		Attachments json.RawMessage `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(body), &reply); err == nil && reply.Attachments != nil {
+		body = string(reply.Attachments)
+	}
+	if err := json.Unmarshal([]byte(body), &msg); err != nil || len(msg) == 0 {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	return msg[0].Fallback
+}
+
+func TestFallbackText(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallbacks FallbackText
+		room      string
+		host      string
+		invite    string
+	}{
+		{
+			"defaults",
+			FallbackText{},
+			"Meeting started in {{room}}",
+			"Invitations have been sent for your meeting in {{room}}.",
+			"<@UHOST> would like you to join a meeting.",
+		},
+		{
+			"custom",
+			FallbackText{Room: "Réunion {{.Room}}", Host: "Invites sent for {{.Room}} on {{.Server}}", Invite: "{{.Host}} is calling"},
+			"Réunion {{room}}",
+			"Invites sent for {{room}} on " + testConfHost,
+			"<@UHOST> is calling",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.Fallbacks = tt.fallbacks
+
+			channel := processCommand(t, s, "")
+			if got, want := attachmentFallback(t, channel.Body), replaceRoom(tt.room, channel.Room); got != want {
+				t.Errorf("room fallback = %q, want %q", got, want)
+			}
+			personal := processCommand(t, s, "<@UBOB>")
+			if got, want := attachmentFallback(t, personal.Body), replaceRoom(tt.host, personal.Room); got != want {
+				t.Errorf("host fallback = %q, want %q", got, want)
+			}
+			if got := attachmentFallback(t, slack.Calls("chat.postMessage")[0].Form.Get("attachments")); got != tt.invite {
+				t.Errorf("invite fallback = %q, want %q", got, tt.invite)
+			}
+		})
+	}
+}
+
+// replaceRoom fills the {{room}} placeholder of an expected text.
+func replaceRoom(text, room string) string {
+	return strings.Replace(text, "{{room}}", room, 1)
+}
+
+func TestFallbackTextInvalid(t *testing.T) {
+	for name, fallbacks := range map[string]FallbackText{
+		"room":   {Room: "{{.Room"},
+		"host":   {Host: "{{.Nope}}"},
+		"invite": {Invite: "{{end}}"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := newTestHandlers(t, newFakeSlack(t))
+			cfg.Fallbacks = fallbacks
+			if s, err := NewSlashCommandHandlers(*cfg); err == nil || s != nil {
+				t.Errorf("NewSlashCommandHandlers = %v, %v, want an error", s, err)
+			}
+		})
+	}
+}
+
+func TestFallbackFallsBackToDefault(t *testing.T) {
+	if got := fallback("{{.Nope}}", defaultRoomFallback, "UHOST", testConfHost, "BraveTiger"); got != "Meeting started in BraveTiger" {
+		t.Errorf("fallback = %q, want the default", got)
+	}
+}
@@ -0,0 +1,227 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// KeyFeatures is the dynamo key for storing a team's conference features.
+	KeyFeatures = "features"
+
+	featuresTemplate = `{"response_type":"ephemeral","text":"Conference features for this workspace.","attachments":[{"text":%s}]}`
+
+	featuresUnsupportedMsg = "Conference features are not supported by this installation."
+	featuresAdminMsg       = "Only workspace admins can change conference features."
+	featuresUnsetMsg       = "No conference features are set, the conference service defaults are used."
+)
+
+// knownFeatures are the conference features that can be set in tokens.
+var knownFeatures = map[string]bool{
+	"recording":         true,
+	"livestreaming":     true,
+	"transcription":     true,
+	"screen-sharing":    true,
+	"outbound-call":     true,
+	"sip-outbound-call": true,
+}
+
+// FeatureStore provides an interface for reading and writing the default
+// conference features of a team. Features that aren't set are left to the
+// conference service.
+type FeatureStore interface {
+	GetFeatures(teamID string) (map[string]bool, error)
+	StoreFeatures(teamID string, features map[string]bool) error
+}
+
+// MemoryFeatureStore stores team features in memory. They're lost on restart.
+type MemoryFeatureStore struct {
+	mu    sync.RWMutex
+	teams map[string]map[string]bool
+}
+
+// GetFeatures retrieves the features stored for a team.
+func (m *MemoryFeatureStore) GetFeatures(teamID string) (map[string]bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	features := map[string]bool{}
+	for name, enabled := range m.teams[teamID] {
+		features[name] = enabled
+	}
+	return features, nil
+}
+
+// StoreFeatures stores the features for a team.
+func (m *MemoryFeatureStore) StoreFeatures(teamID string, features map[string]bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.teams == nil {
+		m.teams = map[string]map[string]bool{}
+	}
+	stored := map[string]bool{}
+	for name, enabled := range features {
+		stored[name] = enabled
+	}
+	m.teams[teamID] = stored
+	return nil
+}
+
+// DynamoFeatureStore stores and retrieves team features from aws dynamodb.
+type DynamoFeatureStore struct {
+	TableName string
+	DB        *dynamodb.DynamoDB
+}
+
+// GetFeatures retrieves the features stored for a team.
+func (d *DynamoFeatureStore) GetFeatures(teamID string) (map[string]bool, error) {
+	result, err := d.DB.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			KeyTeamID: {
+				S: aws.String(teamID),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	features := map[string]bool{}
+	if stored, ok := result.Item[KeyFeatures]; ok {
+		for name, enabled := range stored.M {
+			if enabled.BOOL != nil {
+				features[name] = *enabled.BOOL
+			}
+		}
+	}
+	return features, nil
+}
+
+// StoreFeatures stores the features for a team.
+func (d *DynamoFeatureStore) StoreFeatures(teamID string, features map[string]bool) error {
+	stored := map[string]*dynamodb.AttributeValue{}
+	for name, enabled := range features {
+		stored[name] = &dynamodb.AttributeValue{BOOL: aws.Bool(enabled)}
+	}
+	_, err := d.DB.PutItem(&dynamodb.PutItemInput{
+		Item: map[string]*dynamodb.AttributeValue{
+			KeyTeamID: {
+				S: aws.String(teamID),
+			},
+			KeyFeatures: {
+				M: stored,
+			},
+		},
+		TableName: aws.String(d.TableName),
+	})
+	return err
+}
+
+// parseFeatures parses feature settings of the form name=on or name=off.
+func parseFeatures(text string) (map[string]bool, error) {
+	features := map[string]bool{}
+	for _, setting := range strings.Fields(text) {
+		parts := strings.SplitN(setting, "=", 2)
+		if len(parts) != 2 || !knownFeatures[parts[0]] {
+			return nil, fmt.Errorf("unknown feature %q, use one of %s", setting, strings.Join(featureNames(knownFeatures), ", "))
+		}
+		switch parts[1] {
+		case "on":
+			features[parts[0]] = true
+		case "off":
+			features[parts[0]] = false
+		default:
+			return nil, fmt.Errorf("feature %s must be set to on or off", parts[0])
+		}
+	}
+	return features, nil
+}
+
+func featureNames(features map[string]bool) []string {
+	var names []string
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// teamFeatures returns the conference features for a team's tokens.
+func (s *SlashCommandHandlers) teamFeatures(teamID string) (map[string]bool, error) {
+	if s.Features == nil {
+		return nil, nil
+	}
+	return s.Features.GetFeatures(teamID)
+}
+
+// features previews a team's conference features, or updates them for
+// workspace admins when settings are given.
+func (s *SlashCommandHandlers) features(ctx context.Context, client *slack.Client, teamID, userID, text string) (CommandResult, error) {
+	if s.Features == nil {
+		return ephemeral(featuresUnsupportedMsg), nil
+	}
+	log := zerolog.Ctx(ctx)
+
+	features, err := s.Features.GetFeatures(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving features")
+		return CommandResult{}, err
+	}
+
+	if strings.TrimSpace(text) != "" {
+		updates, err := parseFeatures(text)
+		if err != nil {
+			return ephemeral(err.Error()), nil
+		}
+		user, err := s.fetchUserInfo(ctx, client, userID)
+		if err != nil {
+			switch err.Error() {
+			case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+				return install(s.installURL()), nil
+			default:
+				log.Error().
+					Err(err).
+					Msg("retrieving user info from slack")
+				return CommandResult{}, err
+			}
+		}
+		if !user.IsAdmin && !user.IsOwner {
+			return ephemeral(featuresAdminMsg), nil
+		}
+		for name, enabled := range updates {
+			features[name] = enabled
+		}
+		err = s.Features.StoreFeatures(teamID, features)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("storing features")
+			return CommandResult{}, err
+		}
+	}
+
+	preview := featuresUnsetMsg
+	if len(features) > 0 {
+		var lines []string
+		for _, name := range featureNames(features) {
+			state := "off"
+			if features[name] {
+				state = "on"
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", name, state))
+		}
+		preview = strings.Join(lines, "\n")
+	}
+	msg, _ := json.Marshal(preview)
+	return CommandResult{Body: fmt.Sprintf(featuresTemplate, msg)}, nil
+}
@@ -0,0 +1,116 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateJWTFeatures(t *testing.T) {
+	claims := createTestJWT(t, testTokenGenerator(t), JWTInput{
+		TenantID:   "t1",
+		TenantName: "acme",
+		RoomClaim:  "room",
+		Features:   map[string]bool{"recording": true, "livestreaming": false},
+	})
+	want := map[string]string{"recording": "true", "livestreaming": "false"}
+	if got := contextOf(t, claims).Features; !reflect.DeepEqual(got, want) {
+		t.Errorf("features = %v, want %v", got, want)
+	}
+
+	claims = createTestJWT(t, testTokenGenerator(t), JWTInput{TenantID: "t1", TenantName: "acme", RoomClaim: "room"})
+	if raw, _ := json.Marshal(claims["context"]); strings.Contains(string(raw), "features") {
+		t.Errorf("context = %s, want no features when none are set", raw)
+	}
+}
+
+func TestParseFeatures(t *testing.T) {
+	got, err := parseFeatures("recording=on livestreaming=off")
+	if want := map[string]bool{"recording": true, "livestreaming": false}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFeatures = %v, %v, want %v", got, err, want)
+	}
+	for _, text := range []string{"teleport=on", "recording", "recording=yes"} {
+		if _, err := parseFeatures(text); err == nil {
+			t.Errorf("parseFeatures(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestSetFeatures(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("users.info", adminUserInfo)
+	features := &MemoryFeatureStore{}
+	s := newTestHandlers(t, slack)
+	s.Features = features
+
+	result := processCommand(t, s, "features recording=on livestreaming=off")
+	if !strings.Contains(result.Body, `livestreaming: off\nrecording: on`) {
+		t.Errorf("reply = %s, want a preview of the features", result.Body)
+	}
+	stored, _ := features.GetFeatures(testTeamID)
+	if want := map[string]bool{"recording": true, "livestreaming": false}; !reflect.DeepEqual(stored, want) {
+		t.Errorf("stored %v, want %v", stored, want)
+	}
+}
+
+func TestSetFeaturesRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		admin bool
+		text  string
+		want  string
+	}{
+		{"unknown feature", true, "features teleport=on", `unknown feature "teleport=on"`},
+		{"not admin", false, "features recording=on", featuresAdminMsg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			if tt.admin {
+				slack.Handle("users.info", adminUserInfo)
+			}
+			features := &MemoryFeatureStore{}
+			s := newTestHandlers(t, slack)
+			s.Features = features
+
+			if _, got := responseOf(t, processCommand(t, s, tt.text)); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+			if stored, _ := features.GetFeatures(testTeamID); len(stored) != 0 {
+				t.Errorf("stored %v, want nothing", stored)
+			}
+		})
+	}
+}
+
+func TestTeamFeaturesInInviteTokens(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	features := &MemoryFeatureStore{}
+	features.StoreFeatures(testTeamID, map[string]bool{"recording": true})
+	s := newTestHandlers(t, slack)
+	s.Features = features
+
+	result := processCommand(t, s, "<@UBOB>")
+	for _, meetingURL := range []string{hostURL(t, result), inviteURL(t, slack.Calls("chat.postMessage")[0])} {
+		if got := contextOf(t, tokenClaims(t, meetingURL)).Features; got["recording"] != "true" {
+			t.Errorf("features of %s = %v, want recording on", meetingURL, got)
+		}
+	}
+}
+
+func TestDynamoFeatureStore(t *testing.T) {
+	_, db := newFakeDynamo(t)
+	store := &DynamoFeatureStore{TableName: "features", DB: db}
+	if got, err := store.GetFeatures(testTeamID); err != nil || len(got) != 0 {
+		t.Fatalf("GetFeatures = %v, %v, want none stored", got, err)
+	}
+	want := map[string]bool{"recording": true, "livestreaming": false}
+	if err := store.StoreFeatures(testTeamID, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.GetFeatures(testTeamID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFeatures = %v, %v, want %v", got, err, want)
+	}
+}
@@ -0,0 +1,81 @@
+package jitsi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nlopes/slack"
+)
+
+// maxGroupInvitees is the most invitees a group DM holds alongside the host.
+const maxGroupInvitees = 7
+
+// groupInvite reports whether n invitees are sent a single group invite.
+func (s *SlashCommandHandlers) groupInvite(n int) bool {
+	return n > 1 && n <= s.GroupInviteLimit
+}
+
+// inviteGroup opens one group DM with the host and every invitee and posts
+// a single invite to it. The link carries a token for the room without a
+// user identity since it's shared by everyone in the DM, unless the team's
+// urls aren't authenticated. Invitees left out for being bots are returned.
+func (s *SlashCommandHandlers) inviteGroup(ctx context.Context, client *slack.Client, token string, serverCfg ServerConfig, hostID string, userIDs []string, teamID, teamName, room string, lobby bool, features map[string]bool, maxOccupants int) ([]string, error) {
+	users := []string{hostID}
+	var bots []string
+	for _, userID := range userIDs {
+		userInfo, err := s.userInfo(ctx, client, teamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		// Bots can't join a meeting so they aren't added to the group.
+		if userInfo.IsBot {
+			bots = append(bots, userID)
+			continue
+		}
+		users = append(users, userID)
+	}
+	if len(users) == 1 {
+		return bots, nil
+	}
+
+	confURL, err := s.conferenceURL(ctx, serverCfg, JWTInput{
+		TenantID:     strings.ToLower(teamID),
+		TenantName:   strings.ToLower(teamName),
+		RoomClaim:    room,
+		Lobby:        lobby,
+		Features:     features,
+		MaxOccupants: maxOccupants,
+		Lifetime:     serverCfg.MeetingDuration,
+		AppSecret:    serverCfg.AppSecret,
+	})
+	if err != nil {
+		return bots, err
+	}
+
+	var channel *slack.Channel
+	err = s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		channel, _, _, err = client.OpenConversationContext(
+			ctx,
+			&slack.OpenConversationParameters{
+				Users: users,
+			},
+		)
+		return err
+	})
+	if err != nil {
+		return bots, err
+	}
+
+	logMeetingURL(ctx, teamID, serverCfg.authenticatedURLs())
+	params, err := s.inviteMessage(ctx, hostID, serverCfg.ConferenceHost, strings.ToLower(teamName), room, s.shorten(ctx, confURL), serverCfg.MeetingDuration)
+	if err != nil {
+		return bots, err
+	}
+	ts, err := s.postMessage(ctx, token, channel.ID, params)
+	if !delivered(err) {
+		return bots, err
+	}
+	s.recordInvite(teamID, channel.ID, ts, serverCfg.MeetingDuration)
+	return bots, nil
+}
@@ -0,0 +1,96 @@
+package jitsi
+
+import (
+	"strings"
+	"testing"
+)
+
+const botUserInfo = `{"ok":true,"user":{"id":"UBOT","name":"bot","is_bot":true}}`
+
+func TestGroupInviteOpensOneDM(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	s := newTestHandlers(t, slack)
+	s.GroupInviteLimit = 3
+
+	result := processCommand(t, s, "<@UBOB> <@UALICE>")
+	opened := slack.Calls("conversations.open")
+	if len(opened) != 1 {
+		t.Fatalf("conversations.open calls = %d, want 1", len(opened))
+	}
+	if got := opened[0].Form.Get("users"); got != "UHOST,UBOB,UALICE" {
+		t.Errorf("group dm users = %s, want the host and both invitees", got)
+	}
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want a single invite", len(posted))
+	}
+	if got := posted[0].Form.Get("channel"); got != "DUHOST,UBOB,UALICE" {
+		t.Errorf("invite posted to %s, want the group dm", got)
+	}
+	invite := inviteURL(t, posted[0])
+	if !strings.HasPrefix(invite, testConfHost+"/acme/"+result.Room+"?jwt=") {
+		t.Errorf("invite url = %s, want a token for the room", invite)
+	}
+	// The shared link isn't tied to anyone in the DM.
+	if user := contextOf(t, tokenClaims(t, invite)).User; user.ID != "" {
+		t.Errorf("group invite token is for user %+v, want no user", user)
+	}
+}
+
+func TestGroupInviteFallsBackAboveLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+	}{
+		{"over limit", 2},
+		{"disabled", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			slack.AddUser("UALICE", "alice")
+			slack.AddUser("UCAROL", "carol")
+			s := newTestHandlers(t, slack)
+			s.GroupInviteLimit = tt.limit
+
+			processCommand(t, s, "<@UBOB> <@UALICE> <@UCAROL>")
+			if opened := slack.Calls("conversations.open"); len(opened) != 3 {
+				t.Fatalf("conversations.open calls = %d, want a dm per invitee", len(opened))
+			}
+			if posted := slack.Calls("chat.postMessage"); len(posted) != 3 {
+				t.Errorf("chat.postMessage calls = %d, want an invite per invitee", len(posted))
+			}
+		})
+	}
+}
+
+func TestGroupInviteLeavesOutBots(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.Users["UBOT"] = botUserInfo
+	s := newTestHandlers(t, slack)
+	s.GroupInviteLimit = 3
+
+	processCommand(t, s, "<@UBOB> <@UBOT>")
+	opened := slack.Calls("conversations.open")
+	if len(opened) != 1 || opened[0].Form.Get("users") != "UHOST,UBOB" {
+		t.Fatalf("conversations.open calls = %+v, want one with the host and bob", opened)
+	}
+}
+
+func TestGroupInviteReportsFailure(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	slack.Handle("conversations.open", `{"ok":false,"error":"cannot_dm_bot"}`)
+	s := newTestHandlers(t, slack)
+	s.GroupInviteLimit = 3
+
+	result := processCommand(t, s, "<@UBOB> <@UALICE>")
+	if want := mustJSON(t, "<@UBOB>, <@UALICE>"); !strings.Contains(result.Body, want) {
+		t.Errorf("reply = %s, want the failed invitees %s", result.Body, want)
+	}
+}
@@ -0,0 +1,78 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	guestTemplate = `{"response_type":"ephemeral","text":"Share this link with guests outside of Slack.","attachments":[{"fallback":"Guest link %[1]s","title":"Guest link","text":"%[1]s","color":"#3AA3E3","attachment_type":"default","fields":[{"title":"Room","value":"%[2]s","short":true}]}]}`
+
+	guestActionTemplate = `,{"name":"guest","text":"Guest link","type":"button","url":"%s"}`
+
+	defaultGuestName = "Guest"
+	guestUserID      = "guest"
+)
+
+// guestAction returns a button linking the plain room url to add after the
+// host's join button, or nothing unless HostGuestLinks is set. The plain url
+// has no token so the host can share it with guests.
+func (s *SlashCommandHandlers) guestAction(ctx context.Context, teamID, confHost, tenant, room string) string {
+	if !s.HostGuestLinks {
+		return ""
+	}
+	guestURL := roomURL(confHost, tenant, room)
+	logMeetingURL(ctx, teamID, false)
+	return fmt.Sprintf(guestActionTemplate, s.shorten(ctx, guestURL))
+}
+
+func (s *SlashCommandHandlers) guestName() string {
+	if s.GuestName == "" {
+		return defaultGuestName
+	}
+	return s.GuestName
+}
+
+// guest creates a room and a link to it that isn't tied to a Slack user.
+// The link is the plain room url unless GuestTokens is set and the team's
+// urls are authenticated, in which case it carries a token for a generic
+// guest identity.
+func (s *SlashCommandHandlers) guest(ctx context.Context, teamID, teamName string, features map[string]bool, maxOccupants int) (CommandResult, error) {
+	serverCfg, err := s.teamServerConfig(ctx, teamID)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	room := s.roomName(RandomName())
+	guestURL := roomURL(serverCfg.ConferenceHost, strings.ToLower(teamName), room)
+
+	authenticated := s.GuestTokens && serverCfg.authenticatedURLs()
+	if authenticated {
+		token, err := s.createJWT(ctx, JWTInput{
+			TenantID:     strings.ToLower(teamID),
+			TenantName:   strings.ToLower(teamName),
+			RoomClaim:    room,
+			UserID:       guestUserID,
+			UserName:     s.guestName(),
+			Features:     features,
+			MaxOccupants: maxOccupants,
+			Lifetime:     serverCfg.MeetingDuration,
+			AppSecret:    serverCfg.AppSecret,
+		})
+		if err != nil {
+			zerolog.Ctx(ctx).Error().
+				Err(err).
+				Msg("creating guest token")
+			return CommandResult{}, err
+		}
+		guestURL = fmt.Sprintf("%s?jwt=%s", guestURL, token)
+	}
+
+	logMeetingURL(ctx, teamID, authenticated)
+	return CommandResult{
+		Body: fmt.Sprintf(guestTemplate, s.shorten(ctx, guestURL), room),
+		Room: room,
+	}, nil
+}
@@ -4,34 +4,117 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
+	"time"
 
-	"github.com/nlopes/slack"
 	"github.com/rs/zerolog/hlog"
 )
 
 const (
-	roomTemplate   = `{"response_type":"in_channel","attachments":[{"fallback":"Meeting started %s","title":"Meeting started %s","color":"#3AA3E3","attachment_type":"default","actions":[{"name":"join","text":"Join","type":"button","url":"%s","style":"primary"}]}]}`
-	userTemplate   = `{"response_type":"ephemeral","attachments":[{"fallback":"Invitations have been sent for your meeting.","title":"Invitations have been sent for your meeting.","color":"#3AA3E3","attachment_type":"default","actions":[{"name":"join","text":"Join","type":"button","url":"%s","style":"primary"}]}]}`
-	helpMessage    = `{"response_type":"ephemeral","text":"How to use /jitsi...","attachments":[{"text":"To share a conference link with the channel, use '/jitsi'. Now everyone can join.\nTo share a conference link with users, use 'jitsi @bob @alice'. Now you can meet with Bob and Alice."}]}`
-	installMessage = `{"response_type":"ephemeral","text":"Please install the jitsi meet app to integrate with your slack workspace.","attachments":[{"text":"%s"}]}`
+	roomTemplate      = `{"response_type":"%[6]s","attachments":[{"fallback":%[5]s,"title":"%[4]sMeeting started %[1]s","text":"%[3]s","color":"#3AA3E3","attachment_type":"default","fields":[{"title":"Room","value":"%[2]s","short":true}],"actions":[{"name":"join","text":"Join","type":"button","url":"%[1]s","style":"primary"}]}]}`
+	userTemplate      = `{"response_type":"ephemeral","text":%[3]s,"attachments":[{"fallback":%[5]s,"title":"%[4]sInvitations have been sent for your meeting.","color":"#3AA3E3","attachment_type":"default","fields":[{"title":"Room","value":"%[2]s","short":true}],"actions":[{"name":"join","text":"Join","type":"button","url":"%[1]s","style":"primary"}%[6]s]}]}`
+	whoamiTemplate    = `{"response_type":"ephemeral","text":"Include these details in support requests.","attachments":[{"text":"team_id: %s\nuser_id: %s\nchannel_id: %s\nbot token installed: %s\nconference host: %s"}]}`
+	ephemeralTemplate = `{"response_type":"ephemeral","text":%s}`
+	installMessage    = `{"response_type":"ephemeral","text":"Please install the jitsi meet app to integrate with your slack workspace.","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"Please install the jitsi meet app to integrate with your slack workspace."}},{"type":"actions","elements":[{"type":"button","action_id":"install","text":{"type":"plain_text","text":"Add to Slack"},"style":"primary","url":"%s"}]}]}`
+
+	unreadableCommandMsg = "Sorry, your command couldn't be read. Please try again."
+	selfInviteMsg        = "You don't need to invite yourself, mention the people you'd like to meet with."
+	selfInviteIgnoredMsg = "You don't need to invite yourself, so your own mention was ignored."
+	botInviteMsg         = "Bots can't join meetings, mention the people you'd like to meet with."
+	botInviteesMsg       = "Bots can't join meetings and weren't invited: %s."
+	noActiveInviteesMsg  = "Nobody was invited since everyone is away: %s."
+	awayInviteesMsg      = "These people are away and weren't invited: %s."
+	publicPrivateMsg     = "A meeting can't be both --public and --private."
+	deliveredInvitesMsg  = "✅ Invited %s."
+	failedInvitesMsg     = "⚠️ Invites couldn't be delivered to %s."
+
+	defaultCommandName        = "/jitsi"
+	defaultMaintenanceMessage = "Video meetings are temporarily unavailable while maintenance is performed. Please try again later."
+
+	installFailedPage = `<!DOCTYPE html><html><head><title>Installation failed</title></head><body><h1>Jitsi Meet installation failed</h1><p>%s</p><p><a href="%s">Try installing again</a></p></body></html>`
+
+	installIncompletePage = `<!DOCTYPE html><html><head><title>Installation incomplete</title></head><body><h1>Jitsi Meet installed with missing permissions</h1><p>%s</p><p>Missing permissions: %s</p><p><a href="%s">Install again</a></p></body></html>`
+
+	installDeclinedMsg    = "The installation was cancelled before the app was authorized."
+	installBadRequestMsg  = "The installation request was incomplete or malformed."
+	installInternalMsg    = "Something went wrong while completing the installation."
+	installLinkExpiredMsg = "This install link has expired. Please ask for a new one."
+	installBadCodeMsg     = "Slack didn't accept the authorization for this installation, it may have expired or already been used."
+
+	installMissingScopesMsg = "The app was installed without some of the permissions it needs, so meeting invites may fail until it's installed again with them."
+
+	// error codes returned in the oauth failure envelope
+	errOAuthDeclined    = "access_denied"
+	errOAuthBadRequest  = "bad_request"
+	errOAuthInternal    = "internal_error"
+	errOAuthLinkExpired = "link_expired"
+	errOAuthBadCode     = "bad_code"
+
+	// warning code returned in the oauth warning envelope
+	warnOAuthMissingScopes = "missing_scopes"
 
 	// error strings from slack api
 	errInvalidAuth      = "invalid_auth"
 	errInactiveAccount  = "account_inactive"
 	errMissingAuthToken = "not_authed"
+	errNotInChannel     = "not_in_channel"
+	errInvalidCode      = "invalid_code"
+	errCodeAlreadyUsed  = "code_already_used"
+	errCodeExpired      = "code_expired"
+	errChannelNotFound  = "channel_not_found"
+	errUserNotInChannel = "user_not_in_channel"
+	errUserNotFound     = "user_not_found"
 )
 
-var atMentionRE = regexp.MustCompile(`<@([^>|]+)`)
+const (
+	subcommandHelp   = "help"
+	subcommandLobby  = "lobby"
+	subcommandWhoami = "whoami"
+	subcommandWho    = "who"
+	subcommandGuest  = "guest"
+
+	subcommandInviteChannel = "invite-channel"
+	subcommandChannelRoom   = "channel-room"
+	subcommandFeatures      = "features"
+	subcommandTokens        = "tokens"
+	subcommandTemplate      = "template"
+	subcommandSetDuration   = "set-duration"
+	subcommandAuth          = "auth"
+	subcommandExport        = "export"
+	subcommandImport        = "import"
+	subcommandCopyConfig    = "copy-config"
+	// inviteChannelConfirmed is the argument given to invite-channel once
+	// the caller has confirmed inviting a large channel.
+	inviteChannelConfirmed = "confirmed"
+)
+
+var subcommands = map[string]bool{
+	subcommandHelp:   true,
+	subcommandLobby:  true,
+	subcommandWhoami: true,
+	subcommandWho:    true,
+	subcommandGuest:  true,
+
+	subcommandInviteChannel: true,
+	subcommandChannelRoom:   true,
+	subcommandFeatures:      true,
+	subcommandTokens:        true,
+	subcommandTemplate:      true,
+	subcommandSetDuration:   true,
+	subcommandAuth:          true,
+	subcommandExport:        true,
+	subcommandImport:        true,
+	subcommandCopyConfig:    true,
+}
 
 // ConferenceTokenGenerator provides an interface for creating video conference
 // authenticated access via JWT.
 type ConferenceTokenGenerator interface {
-	CreateJWT(tenantID, tenantName, roomClaim, userID, userName, avatarURL string) (string, error)
+	CreateJWT(in JWTInput) (string, error)
 }
 
 // TokenReader provides an interface for reading access token data from
@@ -40,7 +123,10 @@ type TokenReader interface {
 	GetFirstBotTokenForTeam(teamID string) (string, error)
 }
 
-func handleRequestValidation(w http.ResponseWriter, r *http.Request, SlackSigningSecret string) bool {
+// handleRequestValidation accepts requests signed with SlackSigningSecret,
+// or with NextSlackSigningSecret while the secret is being rotated. Which
+// secret matched is logged so operators can tell when Slack has switched.
+func handleRequestValidation(w http.ResponseWriter, r *http.Request, SlackSigningSecret, NextSlackSigningSecret string) bool {
 	ts := r.Header.Get(RequestTimestampHeader)
 	sig := r.Header.Get(RequestSignatureHeader)
 	if ts == "" || sig == "" {
@@ -55,7 +141,16 @@ func handleRequestValidation(w http.ResponseWriter, r *http.Request, SlackSignin
 	}
 	defer r.Body.Close()
 
-	if !ValidRequest(SlackSigningSecret, string(body), ts, sig) {
+	switch {
+	case ValidRequest(SlackSigningSecret, string(body), ts, sig):
+		hlog.FromRequest(r).Debug().
+			Str("signing_secret", "current").
+			Msg("request validated")
+	case NextSlackSigningSecret != "" && ValidRequest(NextSlackSigningSecret, string(body), ts, sig):
+		hlog.FromRequest(r).Info().
+			Str("signing_secret", "next").
+			Msg("request validated")
+	default:
 		w.WriteHeader(http.StatusUnauthorized)
 		return false
 	}
@@ -64,17 +159,15 @@ func handleRequestValidation(w http.ResponseWriter, r *http.Request, SlackSignin
 	return true
 }
 
-func help(w http.ResponseWriter) {
-	w.Header().Set("Content-type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(helpMessage))
-}
-
-func install(w http.ResponseWriter, sharableURL string) {
-	installMsg := fmt.Sprintf(installMessage, sharableURL)
-	w.Header().Set("Content-type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(installMsg))
+// validRequest verifies a request as originating from Slack, unless
+// InsecureSkipVerification is set in a build that allows it.
+func (s *SlashCommandHandlers) validRequest(w http.ResponseWriter, r *http.Request) bool {
+	if s.InsecureSkipVerification && signingBypassAvailable {
+		hlog.FromRequest(r).Warn().
+			Msg("slack request signature verification skipped, never run this build in production")
+		return true
+	}
+	return handleRequestValidation(w, r, s.SlackSigningSecret, s.NextSlackSigningSecret)
 }
 
 // SlashCommandHandlers provides http handlers for Slack slash commands
@@ -85,178 +178,263 @@ type SlashCommandHandlers struct {
 	SlackSigningSecret string
 	TokenReader        TokenReader
 	SharableURL        string
+	// InstallURL is the oauth authorize url linked from the install button.
+	// SharableURL is linked when it's empty.
+	InstallURL string
+	// LobbyEnabled holds invitees in a lobby until the host admits them.
+	// It can also be requested per meeting with the lobby subcommand.
+	LobbyEnabled bool
+	// MaxOccupants limits how many participants can join meetings for
+	// deployments that honor a maxOccupants token claim. It can also be
+	// set per meeting with --max-occupants. Zero leaves it to the
+	// conference service.
+	MaxOccupants int
+	// MaxURLLength is the longest authenticated meeting url kept as is.
+	// Longer urls are made again without the avatar in the token. It
+	// defaults to 2000.
+	MaxURLLength int
+	// Teams reads the team domain stored at install time, which is used
+	// when a request doesn't carry one. Requests without a domain are left
+	// as they are when it's nil.
+	Teams TeamReader
+	// InviteIdentity overrides the bot's name and icon on invite messages.
+	InviteIdentity BotIdentity
+	// CommandName is the slash command the app is installed under and is
+	// used in usage text. It defaults to /jitsi.
+	CommandName string
+	// HTTPClient is used for Slack api calls. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// MaintenanceMode stops meetings from being created while the conference
+	// service is unavailable. Read only subcommands keep working.
+	MaintenanceMode bool
+	// MaintenanceMessage is shown to users during maintenance.
+	MaintenanceMessage string
+	// ParticipantReader lists participants of a conference. The who
+	// subcommand is unsupported when it's nil.
+	ParticipantReader ParticipantReader
+	// InviteAcks adds an "I'll join" button to invites that sends the host
+	// a summary of who will join. Invites have no button when it's nil.
+	InviteAcks *InviteAcks
+	// EndedInvites marks invites ended once their links expire. Invites are
+	// left as posted when it's nil.
+	EndedInvites *EndedInvites
+	// Workers runs the work done after responding to Slack so bursts of
+	// requests can't exhaust the service. Each piece of work gets its own
+	// goroutine when it's nil.
+	Workers *WorkerPool
+	// DialIn adds how to join by phone to invites. Invites only have a join
+	// button when it's nil.
+	DialIn *DialIn
+	// InsecureSkipVerification accepts requests without verifying they were
+	// signed by Slack, for driving the handlers in local development. It
+	// only takes effect in builds with the devsigning tag and fails
+	// validation in any other build.
+	InsecureSkipVerification bool
+	// AdminAPIToken authenticates requests for the install url and metrics
+	// as a bearer token. The admin endpoints are disabled when it's empty.
+	AdminAPIToken string
+	// DMFallbackEphemeral posts invites in the channel visible only to the
+	// invitee when they can't be sent a DM.
+	DMFallbackEphemeral bool
+	// PrivateMeetings shows a meeting without invitees only to the caller
+	// rather than posting it to the channel, unless --public is given.
+	PrivateMeetings bool
+	// ShowLinkExpiry adds when a link's token expires to invites and the
+	// host's link.
+	ShowLinkExpiry bool
+	// ChannelDebounce points people to the meeting just posted to a channel
+	// instead of posting another. Every command posts a meeting when it's
+	// nil.
+	ChannelDebounce *ChannelDebounce
+	// UserInfoCache caches Slack user info lookups. Nothing is cached when
+	// it's nil.
+	UserInfoCache UserInfoCache
+	// ServerPool selects a healthy host from redundant conference
+	// deployments. ConferenceHost is used when it's nil or every host in
+	// the pool is unhealthy.
+	ServerPool *ServerPool
+	// ConfirmChannelSize is the number of channel members at which posting
+	// a meeting link to the channel needs confirmation. Zero disables it.
+	ConfirmChannelSize int
+	// MaxChannelInvitees is the most channel members invite-channel
+	// invites. Larger channels are refused. Zero leaves it unlimited.
+	MaxChannelInvitees int
+	// SlackRetry limits each Slack api call and retries calls that time
+	// out, except calls posting a message. Its timeout must leave time to
+	// respond within Slack's 3 seconds. The zero value leaves calls limited
+	// only by the request.
+	SlackRetry RetryPolicy
+	// GuestTokens gives guest links a token for a generic guest identity
+	// instead of linking the plain room url.
+	GuestTokens bool
+	// HostGuestLinks adds a button with the plain room url to the host's
+	// confirmation so they can share it with guests outside of Slack.
+	HostGuestLinks bool
+	// GuestName is the display name of the guest identity. It defaults to Guest.
+	GuestName string
+	// RoomDenylist blocks custom room names such as the room configured for
+	// the start meeting workflow step. Denied rooms fall back to a random
+	// room. Any name is allowed when it's nil.
+	RoomDenylist *RoomDenylist
+	// ChannelRooms stores the standing room of each channel for the
+	// channel-room subcommand. The subcommand is unsupported when it's nil.
+	ChannelRooms ChannelRoomStore
+	// GroupInviteLimit is the most invitees that are sent a single invite
+	// in a group DM with the host rather than individual DMs. A group
+	// invite's link isn't tied to an invitee so they name themselves on
+	// joining. Zero disables group invites.
+	GroupInviteLimit int
+	// NameField is the Slack user field that names users in a conference,
+	// one of handle, real_name or display_name. Users without the field set
+	// fall back to the first set of real name, display name and handle.
+	NameField string
+	// Features stores the default conference features of each team, which
+	// are included in every token. The features subcommand is unsupported
+	// when it's nil.
+	Features FeatureStore
+	// EphemeralInvites sends invites as messages only the invitee can see
+	// in the channel the command was used in. Invitees are sent a DM when
+	// the app or the invitee isn't in the channel.
+	EphemeralInvites bool
+	// InviteText is a text/template for the text of invite messages. It's
+	// rendered with the Host mention, conference Server url and Room name.
+	// It defaults to "{{.Host}} would like you to join a meeting."
+	InviteText string
+	// NextSlackSigningSecret is also accepted while rotating the signing
+	// secret, so requests signed with the new secret validate before it
+	// replaces SlackSigningSecret.
+	NextSlackSigningSecret string
+	// Templates stores the meeting templates of each team for the template
+	// subcommand. The subcommand is unsupported when it's nil.
+	Templates TemplateStore
+	// ServerConfigs stores the conference server config of each team. Teams
+	// without one, or all teams when it's nil, use the operator defaults.
+	ServerConfigs ServerConfigStore
+	// Capabilities turns optional capabilities such as guest links on or
+	// off for every team. Team server configs override it and capabilities
+	// that aren't set anywhere are enabled.
+	Capabilities Capabilities
+	// HostPolicy restricts who can start meetings. Help and the other
+	// subcommands that don't start a meeting are available to everyone.
+	HostPolicy HostPolicy
+	// RoomPrefix and RoomSuffix are added to generated room names, i.e.
+	// acme- to keep a team's rooms apart on a server shared with others.
+	RoomPrefix string
+	RoomSuffix string
+	// Fallbacks customizes the notification text of meeting messages.
+	Fallbacks FallbackText
+	// TitleEmoji leads the titles of meeting messages so they stand out,
+	// either a shortcode like :video_camera: or a unicode emoji.
+	TitleEmoji string
+	// InstallLinks signs the InstallURL so install prompts expire. Links
+	// aren't signed when it's nil.
+	InstallLinks *InstallLinkSigner
+	// ReactionTrigger is the emoji, i.e. video_camera, that starts a
+	// meeting when a message is reacted to with it. Reactions are ignored
+	// when it's empty.
+	ReactionTrigger string
+	// TokenAdmin lists and removes a team's stored tokens for the tokens
+	// subcommand. The subcommand is unsupported when it's nil.
+	TokenAdmin TokenAdmin
+	// URLShortener shortens meeting urls before they're shown. Full urls
+	// are shown when it's nil.
+	URLShortener URLShortener
 }
 
-func (s *SlashCommandHandlers) inviteUser(client *slack.Client, hostID, userID, teamID, teamName, room string) error {
-	userInfo, err := client.GetUserInfo(userID)
-	if err != nil {
-		return err
+func (s *SlashCommandHandlers) conferenceHost() string {
+	if s.ServerPool == nil {
+		return s.ConferenceHost
 	}
-	userToken, err := s.TokenGenerator.CreateJWT(
-		strings.ToLower(teamID),
-		strings.ToLower(teamName),
-		room,
-		userInfo.ID,
-		userInfo.Name,
-		userInfo.Profile.Image192,
-	)
+	return s.ServerPool.Select(s.ConferenceHost)
+}
+
+func (s *SlashCommandHandlers) installURL() string {
+	if s.InstallURL == "" {
+		return s.SharableURL
+	}
+	if s.InstallLinks == nil {
+		return s.InstallURL
+	}
+	signed, err := s.InstallLinks.Sign(s.InstallURL, time.Now())
 	if err != nil {
-		return err
+		return s.InstallURL
 	}
+	return signed
+}
 
-	channel, _, _, err := client.OpenConversation(
-		&slack.OpenConversationParameters{
-			Users: []string{userID},
-		},
-	)
-	if err != nil {
-		return err
+func (s *SlashCommandHandlers) commandName() string {
+	if s.CommandName == "" {
+		return defaultCommandName
 	}
+	return s.CommandName
+}
 
-	confURL := fmt.Sprintf(
-		"%s/%s/%s?jwt=%s",
-		s.ConferenceHost,
-		strings.ToLower(teamName),
-		room,
-		userToken,
-	)
+// BotIdentity is the name and icon shown on messages posted by the bot.
+// Empty values leave Slack's defaults for the app in place.
+type BotIdentity struct {
+	Username  string
+	IconURL   string
+	IconEmoji string
+}
 
-	params := slack.PostMessageParameters{}
-	msg := fmt.Sprintf("<@%s> would like you to join a meeting.", hostID)
-	attachment := slack.Attachment{
-		Fallback: msg,
-		Title:    msg,
-		Color:    "#3AA3E3",
-		Actions: []slack.AttachmentAction{
-			slack.AttachmentAction{
-				Name:  "join",
-				Text:  "Join",
-				Type:  "button",
-				Style: "primary",
-				URL:   confURL,
-			},
-		},
+// Validate checks that the icon url, when set, is an absolute http(s) url.
+func (b BotIdentity) Validate() error {
+	if b.IconURL == "" {
+		return nil
 	}
-	params.Attachments = []slack.Attachment{attachment}
-	_, _, err = client.PostMessage(
-		channel.ID,
-		"",
-		params,
-	)
-	if err != nil {
-		return err
-	}
-	return nil
+	return validateURL("icon url", b.IconURL)
 }
 
 // Jitsi will create a conference and dispatch an invite message to both users.
 // It is a slash command for Slack.
 func (s *SlashCommandHandlers) Jitsi(w http.ResponseWriter, r *http.Request) {
-	if !handleRequestValidation(w, r, s.SlackSigningSecret) {
+	// Slack only posts commands, anything else is a browser or a probe.
+	if !methodAllowed(w, r, http.MethodPost) {
 		return
 	}
+	if !s.validRequest(w, r) {
+		return
+	}
+	s.handleCommand(w, r)
+}
+
+// handleCommand processes a slash command request that has already been
+// verified as originating from Slack.
+func (s *SlashCommandHandlers) handleCommand(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
 		hlog.FromRequest(r).Error().
 			Err(err).
 			Msg("unable to parse form data")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	callerID := r.PostFormValue("user_id")
-	teamID := r.PostFormValue("team_id")
-	teamName := r.PostFormValue("team_domain")
-	text := r.PostFormValue("text")
-
-	if strings.ToLower(text) == "help" {
-		help(w)
-		return
-	}
-
-	// Grab an access token after validating request and body
-	// so we can fail early if we don't have one.
-	token, err := s.TokenReader.GetFirstBotTokenForTeam(teamID)
-	if err != nil {
-		switch err.Error() {
-		case errMissingAuthToken:
-			install(w, s.SharableURL)
-		default:
-			hlog.FromRequest(r).Error().
-				Err(err).
-				Msg("retrieving token")
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-		return
-	}
-
-	room := RandomName()
-	matches := atMentionRE.FindAllStringSubmatch(text, -1)
-	if matches == nil {
-		meetingURL := fmt.Sprintf(
-			"%s/%s/%s",
-			s.ConferenceHost,
-			strings.ToLower(teamName),
-			room,
-		)
-
+		// Slack only shows the caller responses sent with an OK status.
 		w.Header().Set("Content-type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		resp := fmt.Sprintf(roomTemplate, meetingURL, meetingURL, meetingURL)
-		w.Write([]byte(resp))
+		w.Write([]byte(ephemeral(unreadableCommandMsg).Body))
 		return
 	}
 
-	slackClient := slack.New(token)
-	for _, match := range matches {
-		err = s.inviteUser(slackClient, callerID, match[1], teamID, teamName, room)
-		if err != nil {
-			switch err.Error() {
-			case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
-				install(w, s.SharableURL)
-				return
-			default:
-				hlog.FromRequest(r).Error().
-					Err(err).
-					Msg("inviting user")
-			}
-		}
+	// Slack verifies the endpoint's certificate with ssl_check requests
+	// which only need an OK response.
+	if r.PostFormValue("ssl_check") != "" {
+		w.WriteHeader(http.StatusOK)
+		return
 	}
 
-	callerInfo, err := slackClient.GetUserInfo(callerID)
+	result, err := s.ProcessCommand(r.Context(), CommandInput{
+		UserID:    r.PostFormValue("user_id"),
+		TeamID:    r.PostFormValue("team_id"),
+		TeamName:  r.PostFormValue("team_domain"),
+		ChannelID: r.PostFormValue("channel_id"),
+		Text:      r.PostFormValue("text"),
+	})
 	if err != nil {
-		switch err.Error() {
-		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
-			install(w, s.SharableURL)
-		default:
-			hlog.FromRequest(r).Error().
-				Err(err).
-				Msg("retrieving user info from slack")
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-		return
+		// Slack only shows the caller responses sent with an OK status.
+		result = commandFailed(r.PostFormValue("text"))
 	}
-	callerToken, err := s.TokenGenerator.CreateJWT(
-		strings.ToLower(teamID),
-		strings.ToLower(teamName),
-		room,
-		callerID,
-		callerInfo.Name,
-		callerInfo.Profile.Image192,
-	)
-
-	callerConfURL := fmt.Sprintf(
-		"%s/%s/%s?jwt=%s",
-		s.ConferenceHost,
-		strings.ToLower(teamName),
-		room,
-		callerToken,
-	)
 
-	// TODO: determine what's an error that gets exposed to the user.
 	w.Header().Set("Content-type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	resp := fmt.Sprintf(userTemplate, callerConfURL)
-	w.Write([]byte(resp))
+	w.Write([]byte(result.Body))
 }
 
 // TokenWriter provides an interface to write access token data to the
@@ -272,21 +450,135 @@ type SlackOAuthHandlers struct {
 	ClientSecret      string
 	AppID             string
 	TokenWriter       TokenWriter
+	// SharableURL is linked from failure responses so an install can be retried.
+	SharableURL string
+	// JSONErrors renders failure responses as a JSON envelope instead of HTML.
+	JSONErrors bool
+	// CanceledURL is redirected to when the installing user declines the
+	// install. The canceled install is described with a 400 when it's empty.
+	CanceledURL string
+	// HTTPClient is used for the oauth exchange. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// RequiredScopes are the scopes an install needs for invites to work.
+	// Installs granted fewer are kept but the installer is warned.
+	RequiredScopes []string
+	// InstallLinks verifies the state of signed install links so expired
+	// links are refused. Unsigned installs are accepted when it's nil.
+	InstallLinks *InstallLinkSigner
+}
+
+type oauthError struct {
+	Error    string `json:"error"`
+	Message  string `json:"message"`
+	RetryURL string `json:"retry_url,omitempty"`
+}
+
+// installFailed responds to the installing user with a description of the
+// failure and a link to retry the install.
+func (o *SlackOAuthHandlers) installFailed(w http.ResponseWriter, status int, code, msg string) {
+	if o.JSONErrors {
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(oauthError{
+			Error:    code,
+			Message:  msg,
+			RetryURL: o.SharableURL,
+		})
+		return
+	}
+
+	w.Header().Set("Content-type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(
+		w,
+		installFailedPage,
+		html.EscapeString(msg),
+		html.EscapeString(o.SharableURL),
+	)
+}
+
+type oauthWarning struct {
+	Warning       string   `json:"warning"`
+	Message       string   `json:"message"`
+	MissingScopes []string `json:"missing_scopes"`
+	RetryURL      string   `json:"retry_url,omitempty"`
+}
+
+// missingScopes returns the required scopes that aren't in the comma
+// separated granted scopes.
+func (o *SlackOAuthHandlers) missingScopes(granted string) []string {
+	grantedScopes := map[string]bool{}
+	for _, scope := range strings.Split(granted, ",") {
+		grantedScopes[strings.TrimSpace(scope)] = true
+	}
+	var missing []string
+	for _, scope := range o.RequiredScopes {
+		if !grantedScopes[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// installIncomplete warns the installing user that the install is missing
+// scopes and links to reinstall with them.
+func (o *SlackOAuthHandlers) installIncomplete(w http.ResponseWriter, missing []string) {
+	if o.JSONErrors {
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(oauthWarning{
+			Warning:       warnOAuthMissingScopes,
+			Message:       installMissingScopesMsg,
+			MissingScopes: missing,
+			RetryURL:      o.SharableURL,
+		})
+		return
+	}
+
+	w.Header().Set("Content-type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(
+		w,
+		installIncompletePage,
+		html.EscapeString(installMissingScopesMsg),
+		html.EscapeString(strings.Join(missing, ", ")),
+		html.EscapeString(o.SharableURL),
+	)
+}
+
+// AuthorizeURL creates the url that starts the Slack oauth v2 install flow
+// requesting the provided bot scopes.
+func AuthorizeURL(clientID string, scopes []string) string {
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("scope", strings.Join(scopes, ","))
+	return "https://slack.com/oauth/v2/authorize?" + params.Encode()
+}
+
+type authedUser struct {
+	ID          string `json:"id"`
+	AccessToken string `json:"access_token"`
 }
 
-type botToken struct {
-	BotUserID      string `json:"bot_user_id"`
-	BotAccessToken string `json:"bot_access_token"`
+type accessTeam struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
+// accessResponse is the oauth.v2.access response. AccessToken is the bot
+// token and Scope its scopes.
 type accessResponse struct {
-	OK          bool     `json:"ok"`
-	AccessToken string   `json:"access_token"`
-	Scope       string   `json:"scope"`
-	UserID      string   `json:"user_id"`
-	TeamName    string   `json:"team_name"`
-	TeamID      string   `json:"team_id"`
-	Bot         botToken `json:"bot"`
+	OK          bool       `json:"ok"`
+	Error       string     `json:"error"`
+	AccessToken string     `json:"access_token"`
+	Scope       string     `json:"scope"`
+	BotUserID   string     `json:"bot_user_id"`
+	Team        accessTeam `json:"team"`
+	AuthedUser  authedUser `json:"authed_user"`
+	// RefreshToken and ExpiresIn are only provided when token rotation
+	// is enabled for the app.
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 }
 
 // Auth validates OAuth access tokens.
@@ -296,29 +588,57 @@ func (o *SlackOAuthHandlers) Auth(w http.ResponseWriter, r *http.Request) {
 		hlog.FromRequest(r).Error().
 			Err(err).
 			Msg("parsing query params")
-		w.WriteHeader(http.StatusInternalServerError)
+		o.installFailed(w, http.StatusBadRequest, errOAuthBadRequest, installBadRequestMsg)
 		return
 	}
 
 	if params["error"] != nil {
+		// A user declining the install isn't a failure of the service.
+		if params.Get("error") == errOAuthDeclined {
+			hlog.FromRequest(r).Info().
+				Msg("user declined install")
+			if o.CanceledURL != "" {
+				http.Redirect(w, r, o.CanceledURL, http.StatusSeeOther)
+				return
+			}
+			o.installFailed(w, http.StatusBadRequest, errOAuthDeclined, installDeclinedMsg)
+			return
+		}
 		hlog.FromRequest(r).Error().
-			Err(err).
-			Msg("error response user declined install")
-		w.WriteHeader(http.StatusInternalServerError)
+			Str("error", params.Get("error")).
+			Msg("error response from slack oauth")
+		o.installFailed(w, http.StatusInternalServerError, errOAuthInternal, installInternalMsg)
 		return
 	}
 
+	if o.InstallLinks != nil {
+		err = o.InstallLinks.Verify(params.Get("state"), time.Now())
+		if err == errInstallLinkExpired {
+			hlog.FromRequest(r).Error().
+				Err(err).
+				Msg("install link expired")
+			o.installFailed(w, http.StatusBadRequest, errOAuthLinkExpired, installLinkExpiredMsg)
+			return
+		}
+		if err != nil {
+			hlog.FromRequest(r).Error().
+				Err(err).
+				Msg("install link not valid")
+			o.installFailed(w, http.StatusBadRequest, errOAuthBadRequest, installBadRequestMsg)
+			return
+		}
+	}
+
 	code := params["code"]
 	if len(code) != 1 {
 		hlog.FromRequest(r).Error().
-			Err(err).
 			Msg("code not provided")
-		w.WriteHeader(http.StatusInternalServerError)
+		o.installFailed(w, http.StatusBadRequest, errOAuthBadRequest, installBadRequestMsg)
 		return
 	}
 
-	// TODO: inject an http client with http logging.
-	resp, err := http.Get(fmt.Sprintf(
+	// TODO: add http logging to the injected client.
+	resp, err := httpClientOrDefault(o.HTTPClient).Get(fmt.Sprintf(
 		o.AccessURLTemplate,
 		o.ClientID,
 		o.ClientSecret,
@@ -328,39 +648,62 @@ func (o *SlackOAuthHandlers) Auth(w http.ResponseWriter, r *http.Request) {
 		hlog.FromRequest(r).Error().
 			Err(err).
 			Msg("oauth req error")
-		w.WriteHeader(http.StatusInternalServerError)
+		o.installFailed(w, http.StatusInternalServerError, errOAuthInternal, installInternalMsg)
 		return
 	}
+	defer resp.Body.Close()
 
 	var access accessResponse
 	if err := json.NewDecoder(resp.Body).Decode(&access); err != nil {
 		hlog.FromRequest(r).Error().
 			Err(err).
 			Msg("unable to decode slack access response")
-		w.WriteHeader(http.StatusInternalServerError)
+		o.installFailed(w, http.StatusInternalServerError, errOAuthInternal, installInternalMsg)
 		return
 	}
 
 	if !access.OK {
 		hlog.FromRequest(r).Error().
-			Err(err).
+			Str("error", access.Error).
 			Msg("access not ok")
-		w.WriteHeader(http.StatusInternalServerError)
+		switch access.Error {
+		case errInvalidCode, errCodeAlreadyUsed, errCodeExpired:
+			// The installing user can fix a stale code by trying again.
+			o.installFailed(w, http.StatusBadRequest, errOAuthBadCode, installBadCodeMsg)
+		default:
+			o.installFailed(w, http.StatusInternalServerError, errOAuthInternal, installInternalMsg)
+		}
 		return
 	}
 
-	err = o.TokenWriter.Store(&TokenData{
-		TeamID:      access.TeamID,
-		UserID:      access.UserID,
-		BotToken:    access.Bot.BotAccessToken,
-		BotUserID:   access.Bot.BotUserID,
-		AccessToken: access.AccessToken,
-	})
+	data := TokenData{
+		TeamID:      access.Team.ID,
+		UserID:      access.AuthedUser.ID,
+		BotToken:    access.AccessToken,
+		BotUserID:   access.BotUserID,
+		AccessToken: access.AuthedUser.AccessToken,
+		TeamName:    access.Team.Name,
+		TeamDomain:  o.teamDomain(r.Context(), access.AccessToken),
+	}
+	if access.RefreshToken != "" {
+		data.RefreshToken = access.RefreshToken
+		data.TokenExpiry = time.Now().Unix() + access.ExpiresIn
+	}
+	err = o.TokenWriter.Store(&data)
 	if err != nil {
 		hlog.FromRequest(r).Error().
 			Err(err).
 			Msg("unable to store token")
-		w.WriteHeader(http.StatusInternalServerError)
+		o.installFailed(w, http.StatusInternalServerError, errOAuthInternal, installInternalMsg)
+		return
+	}
+
+	if missing := o.missingScopes(access.Scope); len(missing) > 0 {
+		hlog.FromRequest(r).Warn().
+			Str("team_id", access.Team.ID).
+			Strs("missing_scopes", missing).
+			Msg("installed without required scopes")
+		o.installIncomplete(w, missing)
 		return
 	}
 
@@ -0,0 +1,304 @@
+package jitsi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+type failingTokenWriter struct{}
+
+func (failingTokenWriter) Store(*TokenData) error {
+	return errors.New("table unavailable")
+}
+
+func newTestOAuthHandlers(slack *fakeSlack, tokens TokenWriter) *SlackOAuthHandlers {
+	return &SlackOAuthHandlers{
+		AccessURLTemplate: "https://slack.com/api/oauth.v2.access?client_id=%s&client_secret=%s&code=%s",
+		ClientID:          "client",
+		ClientSecret:      "secret",
+		AppID:             "A1",
+		TokenWriter:       tokens,
+		SharableURL:       testInstallURL,
+		HTTPClient:        slack.Client(),
+	}
+}
+
+const testAccessResponse = `{"ok":true,"access_token":"xoxb-new","scope":"commands,chat:write","bot_user_id":"UBOT","team":{"id":"T1","name":"Acme"},"authed_user":{"id":"UHOST"}}`
+
+func TestAuthStoresInstall(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("oauth.v2.access", testAccessResponse)
+	tokens := &MemoryTokenStore{}
+	o := newTestOAuthHandlers(slack, tokens)
+
+	w := httptest.NewRecorder()
+	o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?code=abc", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "https://slack.com/app_redirect?app=A1" {
+		t.Fatalf("response = %d %s, want a redirect to the app", w.Code, w.Header().Get("Location"))
+	}
+	if code := slack.Calls("oauth.v2.access")[0].Form.Get("code"); code != "abc" {
+		t.Errorf("exchanged code %q, want abc", code)
+	}
+	data, err := tokens.GetFirstTokenDataForTeam("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.BotToken != "xoxb-new" || data.BotUserID != "UBOT" || data.TeamDomain != testTeamDomain {
+		t.Errorf("stored %+v, want the installed bot token", data)
+	}
+}
+
+func TestAuthFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		access string
+		tokens TokenWriter
+		status int
+		msg    string
+	}{
+		{"declined", "error=access_denied", "", &MemoryTokenStore{}, http.StatusBadRequest, installDeclinedMsg},
+		{"slack error", "error=server_error", "", &MemoryTokenStore{}, http.StatusInternalServerError, installInternalMsg},
+		{"no code", "", "", &MemoryTokenStore{}, http.StatusBadRequest, installBadRequestMsg},
+		{"bad code", "code=stale", `{"ok":false,"error":"invalid_code"}`, &MemoryTokenStore{}, http.StatusBadRequest, installBadCodeMsg},
+		{"used code", "code=used", `{"ok":false,"error":"code_already_used"}`, &MemoryTokenStore{}, http.StatusBadRequest, installBadCodeMsg},
+		{"bad client", "code=abc", `{"ok":false,"error":"invalid_client_id"}`, &MemoryTokenStore{}, http.StatusInternalServerError, installInternalMsg},
+		{"store failure", "code=abc", testAccessResponse, failingTokenWriter{}, http.StatusInternalServerError, installInternalMsg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			if tt.access != "" {
+				slack.Handle("oauth.v2.access", tt.access)
+			}
+			o := newTestOAuthHandlers(slack, tt.tokens)
+
+			w := httptest.NewRecorder()
+			o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?"+tt.query, nil))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, "Try installing again") || !strings.Contains(body, html.EscapeString(tt.msg)) {
+				t.Errorf("page = %s, want %q with a retry link", body, tt.msg)
+			}
+		})
+	}
+}
+
+func TestAuthDeclinedRedirectsToCanceledURL(t *testing.T) {
+	o := newTestOAuthHandlers(newFakeSlack(t), &MemoryTokenStore{})
+	o.CanceledURL = "https://example.com/canceled"
+
+	w := httptest.NewRecorder()
+	o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?error=access_denied", nil))
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != o.CanceledURL {
+		t.Errorf("response = %d %s, want a redirect to %s", w.Code, w.Header().Get("Location"), o.CanceledURL)
+	}
+}
+
+func TestAuthJSONErrors(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("oauth.v2.access", `{"ok":false,"error":"code_expired"}`)
+	o := newTestOAuthHandlers(slack, &MemoryTokenStore{})
+	o.JSONErrors = true
+
+	w := httptest.NewRecorder()
+	o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?code=old", nil))
+	var envelope oauthError
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+	want := oauthError{Error: errOAuthBadCode, Message: installBadCodeMsg, RetryURL: testInstallURL}
+	if w.Code != http.StatusBadRequest || envelope != want {
+		t.Errorf("response = %d %+v, want %d %+v", w.Code, envelope, http.StatusBadRequest, want)
+	}
+}
+
+func TestBotIdentityValidate(t *testing.T) {
+	for icon, valid := range map[string]bool{
+		"":                          true,
+		"https://example.com/a.png": true,
+		"example.com/a.png":         false,
+		"ftp://example.com/a.png":   false,
+		"javascript:alert(1)":       false,
+	} {
+		err := BotIdentity{IconURL: icon}.Validate()
+		if (err == nil) != valid {
+			t.Errorf("Validate with icon %q = %v, want valid %v", icon, err, valid)
+		}
+	}
+}
+
+func TestSlashCommandAnswersSSLCheck(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	form := url.Values{"ssl_check": {"1"}, "token": {"legacy"}}
+
+	w := httptest.NewRecorder()
+	s.Jitsi(w, signedRequest(t, PathSlashCommand, "application/x-www-form-urlencoded", form.Encode()))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("response = %d %q, want an empty 200 without a meeting", w.Code, w.Body)
+	}
+}
+
+func TestSlashCommandMalformedForm(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	w := httptest.NewRecorder()
+	s.Jitsi(w, signedRequest(t, PathSlashCommand, "application/x-www-form-urlencoded", "team_id=T1&text=%zz"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 so Slack shows the reply", w.Code)
+	}
+	if _, got := responseOf(t, CommandResult{Body: w.Body.String()}); got != unreadableCommandMsg {
+		t.Errorf("reply = %q, want %q", got, unreadableCommandMsg)
+	}
+	if posted := slack.Calls("chat.postMessage"); len(posted) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want no meeting", len(posted))
+	}
+}
+
+func TestSlashCommandRejectsOtherMethods(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodHead} {
+		t.Run(method, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			s := newTestHandlers(t, slack)
+			r := slashCommand(t, "")
+			r.Method = method
+
+			w := httptest.NewRecorder()
+			s.Jitsi(w, r)
+			if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+				t.Errorf("response = %d allowing %q, want 405 allowing POST", w.Code, w.Header().Get("Allow"))
+			}
+			if w.Body.Len() != 0 || len(slack.Calls("users.info")) != 0 {
+				t.Errorf("response = %q, want the command not run", w.Body)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	newTestHandlers(t, newFakeSlack(t)).Jitsi(w, slashCommand(t, ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), testConfHost) {
+		t.Errorf("post = %d %s, want a meeting", w.Code, w.Body)
+	}
+}
+
+func TestAuthRequiredScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  string
+		json     bool
+		redirect bool
+		missing  []string
+	}{
+		{"sufficient", "commands,chat:write,users:read,im:write", false, true, nil},
+		{"extra scopes", "commands, chat:write ,users:read,im:write,team:read", false, true, nil},
+		{"insufficient", "commands,chat:write", false, false, []string{"users:read", "im:write"}},
+		{"insufficient json", "chat:write", true, false, []string{"users:read", "im:write"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.Handle("oauth.v2.access", strings.Replace(testAccessResponse, `"scope":"commands,chat:write"`, `"scope":"`+tt.granted+`"`, 1))
+			tokens := &MemoryTokenStore{}
+			o := newTestOAuthHandlers(slack, tokens)
+			o.RequiredScopes = []string{"chat:write", "users:read", "im:write"}
+			o.JSONErrors = tt.json
+
+			w := httptest.NewRecorder()
+			o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?code=abc", nil))
+			if _, err := tokens.GetFirstTokenDataForTeam("T1"); err != nil {
+				t.Errorf("install wasn't stored: %v", err)
+			}
+			if tt.redirect {
+				if w.Code != http.StatusFound {
+					t.Errorf("response = %d %s, want a redirect to the app", w.Code, w.Body)
+				}
+				return
+			}
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 with a warning", w.Code)
+			}
+			if tt.json {
+				var warning oauthWarning
+				if err := json.Unmarshal(w.Body.Bytes(), &warning); err != nil {
+					t.Fatal(err)
+				}
+				if warning.Warning != warnOAuthMissingScopes || strings.Join(warning.MissingScopes, ",") != strings.Join(tt.missing, ",") || warning.RetryURL != testInstallURL {
+					t.Errorf("warning = %+v, want missing scopes %v", warning, tt.missing)
+				}
+				return
+			}
+			page := w.Body.String()
+			if !strings.Contains(page, html.EscapeString(strings.Join(tt.missing, ", "))) || !strings.Contains(page, html.EscapeString(testInstallURL)) {
+				t.Errorf("page = %s, want the missing scopes %v and a reinstall link", page, tt.missing)
+			}
+		})
+	}
+}
+
+func TestAuthDeclinedVersusErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		status  int
+		level   string
+		message string
+		msg     string
+	}{
+		{"access_denied", http.StatusBadRequest, "info", "user declined install", installDeclinedMsg},
+		{"invalid_scope", http.StatusInternalServerError, "error", "error response from slack oauth", installInternalMsg},
+		{"server_error", http.StatusInternalServerError, "error", "error response from slack oauth", installInternalMsg},
+		{"something_new", http.StatusInternalServerError, "error", "error response from slack oauth", installInternalMsg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			o := newTestOAuthHandlers(slack, &MemoryTokenStore{})
+			o.JSONErrors = true
+
+			var logs bytes.Buffer
+			r := httptest.NewRequest(http.MethodGet, "/slack/auth?error="+tt.code, nil)
+			r = r.WithContext(zerolog.New(&logs).WithContext(r.Context()))
+			w := httptest.NewRecorder()
+			o.Auth(w, r)
+			var envelope oauthError
+			if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("decoding %s: %v", w.Body, err)
+			}
+			if w.Code != tt.status || envelope.Message != tt.msg {
+				t.Errorf("response = %d %+v, want %d %q", w.Code, envelope, tt.status, tt.msg)
+			}
+			var line struct {
+				Level   string `json:"level"`
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(logs.Bytes(), &line); err != nil || line.Level != tt.level || line.Message != tt.message {
+				t.Errorf("logged %s, want %q at %s", logs.String(), tt.message, tt.level)
+			}
+			if calls := slack.Calls("oauth.v2.access"); len(calls) != 0 {
+				t.Errorf("oauth.v2.access calls = %d, want no exchange", len(calls))
+			}
+		})
+	}
+}
+
+func TestAuthErrorsDontRedirectToCanceledURL(t *testing.T) {
+	o := newTestOAuthHandlers(newFakeSlack(t), &MemoryTokenStore{})
+	o.CanceledURL = "https://example.com/canceled"
+
+	w := httptest.NewRecorder()
+	o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?error=server_error", nil))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Location") != "" {
+		t.Errorf("response = %d %s, want a 500 without a redirect", w.Code, w.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,372 @@
+package jitsi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+const (
+	testSigningSecret = "test-signing-secret"
+	testConfHost      = "https://meet.example.com"
+	testInstallURL    = "https://slack.com/oauth/v2/authorize?client_id=test"
+	testTeamID        = "T1"
+	testTeamDomain    = "acme"
+	testBotToken      = "xoxb-test"
+)
+
+// slackCall is a Slack api request received by a fakeSlack.
+type slackCall struct {
+	Method string
+	// Form holds form encoded parameters and Body json bodies.
+	Form url.Values
+	Body map[string]interface{}
+}
+
+// fakeSlack emulates the Slack api for tests. Requests the handlers make to
+// slack.com are sent to it by the client from Client. Methods answer with
+// the response set with HandleToken for the bot token they're called with,
+// or else the one set with Handle, unless they're rate limited with RateLimit,
+// users.info answers from Users, users.getPresence answers from Presence
+// and any other method answers ok.
+type fakeSlack struct {
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	calls     []slackCall
+	responses map[string]string
+	byToken   map[string]string
+	delays    map[string]time.Duration
+	limited   map[string]int
+	// Users are the users known to users.info, keyed by id.
+	Users map[string]string
+	// Presence is the presence users.getPresence gives each user, keyed by
+	// id.
+	Presence map[string]string
+}
+
+func newFakeSlack(t *testing.T) *fakeSlack {
+	t.Helper()
+	f := &fakeSlack{
+		responses: map[string]string{},
+		byToken:   map[string]string{},
+		delays:    map[string]time.Duration{},
+		limited:   map[string]int{},
+		Users:     map[string]string{},
+		Presence:  map[string]string{},
+	}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.srv.Close)
+	f.AddUser("UHOST", "host")
+	return f
+}
+
+// AddUser adds a user to users.info.
+func (f *fakeSlack) AddUser(id, name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Users[id] = fmt.Sprintf(`{"ok":true,"user":{"id":%q,"name":%q,"real_name":%q,"profile":{"display_name":%q,"real_name":%q,"image_192":"https://avatars.example.com/%s.png"}}}`, id, name, name, name, name, id)
+}
+
+// Handle sets the response body of a method.
+func (f *fakeSlack) Handle(method, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[method] = body
+}
+
+// HandleToken sets the response body of a method called with token.
+func (f *fakeSlack) HandleToken(method, token, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.byToken[method+" "+token] = body
+}
+
+// Delay slows down the responses of a method.
+func (f *fakeSlack) Delay(method string, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delays[method] = d
+}
+
+// RateLimit answers the next times calls of a method as rate limited,
+// asking for no wait before retrying.
+func (f *fakeSlack) RateLimit(method string, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.limited[method] = times
+}
+
+// Client returns an http client that sends slack.com requests to the fake.
+func (f *fakeSlack) Client() *http.Client {
+	target, _ := url.Parse(f.srv.URL)
+	return &http.Client{Transport: slackTransport{target: target}}
+}
+
+// Calls returns the requests made for a method in order. Requests to
+// response urls are recorded under their path.
+func (f *fakeSlack) Calls(method string) []slackCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []slackCall
+	for _, call := range f.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (f *fakeSlack) serve(w http.ResponseWriter, r *http.Request) {
+	call := slackCall{Method: strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/"), "/")}
+	body, _ := ioutil.ReadAll(r.Body)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		json.Unmarshal(body, &call.Body)
+	} else {
+		call.Form, _ = url.ParseQuery(string(body))
+		for k, v := range r.URL.Query() {
+			call.Form[k] = v
+		}
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if call.Form.Get("token") != "" {
+		token = call.Form.Get("token")
+	}
+	resp, ok := f.byToken[call.Method+" "+token]
+	if !ok {
+		resp, ok = f.responses[call.Method]
+	}
+	delay := f.delays[call.Method]
+	limited := f.limited[call.Method] > 0
+	if limited {
+		f.limited[call.Method]--
+	}
+	if !ok && call.Method == "users.info" {
+		resp, ok = f.Users[call.Form.Get("user")]
+		if !ok {
+			resp = `{"ok":false,"error":"user_not_found"}`
+		}
+	}
+	if presence, known := f.Presence[call.Form.Get("user")]; !ok && known && call.Method == "users.getPresence" {
+		resp, ok = `{"ok":true,"presence":"`+presence+`"}`, true
+	}
+	f.mu.Unlock()
+
+	time.Sleep(delay)
+	if limited {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	if !ok && resp == "" {
+		switch call.Method {
+		case "conversations.open":
+			resp = `{"ok":true,"channel":{"id":"D` + strings.Join(call.Form["users"], "") + `"}}`
+		case "team.info":
+			resp = `{"ok":true,"team":{"id":"` + testTeamID + `","domain":"` + testTeamDomain + `"}}`
+		default:
+			resp = `{"ok":true,"channel":"C1","ts":"1500000000.000100"}`
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(resp))
+}
+
+// slackTransport sends requests for slack.com and its response urls to
+// target.
+type slackTransport struct {
+	target *url.URL
+}
+
+func (t slackTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host == "slack.com" || r.URL.Host == "hooks.slack.com" {
+		r = r.Clone(r.Context())
+		r.URL.Scheme = t.target.Scheme
+		r.URL.Host = t.target.Host
+		r.Host = t.target.Host
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+var (
+	testKeyOnce sync.Once
+	testKey     string
+)
+
+// testSigningKey returns a data url of a PKCS8 RSA key for signing
+// conference tokens. It's generated once since it's slow.
+func testSigningKey(t *testing.T) string {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testKey = dataurl.New(der, "application/octet-stream").String()
+	})
+	return testKey
+}
+
+// newTestHandlers returns handlers wired to a fake Slack with a bot token
+// installed for the test team.
+func newTestHandlers(t *testing.T, slack *fakeSlack) *SlashCommandHandlers {
+	t.Helper()
+	tokens := &MemoryTokenStore{}
+	err := tokens.Store(&TokenData{
+		TeamID:     testTeamID,
+		UserID:     "UHOST",
+		BotToken:   testBotToken,
+		TeamDomain: testTeamDomain,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &SlashCommandHandlers{
+		ConferenceHost: testConfHost,
+		TokenGenerator: TokenGenerator{
+			Lifetime:   time.Hour,
+			PrivateKey: testSigningKey(t),
+			Issuer:     "test",
+			Audience:   "test",
+			Kid:        "test",
+		},
+		SlackSigningSecret: testSigningSecret,
+		TokenReader:        tokens,
+		InstallURL:         testInstallURL,
+		HTTPClient:         slack.Client(),
+	}
+}
+
+// signedRequest builds a request signed with the test signing secret as
+// Slack would sign it.
+func signedRequest(t *testing.T, path, contentType, body string) *http.Request {
+	t.Helper()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	fmt.Fprintf(mac, "%s:%s:%s", SignatureVersion, ts, body)
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set(RequestTimestampHeader, ts)
+	r.Header.Set(RequestSignatureHeader, SignatureVersion+"="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+// unsignedRequest builds a request without Slack's signature headers.
+func unsignedRequest(path, contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+// slashCommand builds a signed slash command request from the host in the
+// test team.
+func slashCommand(t *testing.T, text string) *http.Request {
+	t.Helper()
+	form := url.Values{
+		"team_id":     {testTeamID},
+		"team_domain": {testTeamDomain},
+		"user_id":     {"UHOST"},
+		"channel_id":  {"C1"},
+		"command":     {"/jitsi"},
+		"text":        {text},
+	}
+	return signedRequest(t, PathSlashCommand, "application/x-www-form-urlencoded", form.Encode())
+}
+
+func TestSlashCommandInvitesUser(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+
+	w := httptest.NewRecorder()
+	s.Jitsi(w, slashCommand(t, "<@UBOB|bob>"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var confirmation struct {
+		ResponseType string `json:"response_type"`
+		Attachments  []struct {
+			Title   string `json:"title"`
+			Actions []struct {
+				URL string `json:"url"`
+			} `json:"actions"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &confirmation); err != nil {
+		t.Fatalf("decoding confirmation %s: %v", w.Body, err)
+	}
+	if confirmation.ResponseType != "ephemeral" || len(confirmation.Attachments) != 1 {
+		t.Fatalf("confirmation = %s, want an ephemeral attachment", w.Body)
+	}
+	if got := confirmation.Attachments[0].Title; got != "Invitations have been sent for your meeting." {
+		t.Errorf("confirmation title = %q", got)
+	}
+	hostURL := confirmation.Attachments[0].Actions[0].URL
+	if !strings.HasPrefix(hostURL, testConfHost+"/"+testTeamDomain+"/") || !strings.Contains(hostURL, "?jwt=") {
+		t.Errorf("host url = %q, want an authenticated meeting url", hostURL)
+	}
+
+	opened := slack.Calls("conversations.open")
+	if len(opened) != 1 || opened[0].Form.Get("users") != "UBOB" {
+		t.Fatalf("conversations.open calls = %+v, want one for UBOB", opened)
+	}
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want 1", len(posted))
+	}
+	if got := posted[0].Form.Get("channel"); got != "DUBOB" {
+		t.Errorf("invite posted to %q, want the DM DUBOB", got)
+	}
+	var invite []struct {
+		Title   string `json:"title"`
+		Actions []struct {
+			URL string `json:"url"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(posted[0].Form.Get("attachments")), &invite); err != nil || len(invite) != 1 {
+		t.Fatalf("decoding invite %s: %v", posted[0].Form.Get("attachments"), err)
+	}
+	if got := invite[0].Title; got != "<@UHOST> would like you to join a meeting." {
+		t.Errorf("invite title = %q", got)
+	}
+	room := hostURL[:strings.Index(hostURL, "?")]
+	if got := invite[0].Actions[0].URL; !strings.HasPrefix(got, room+"?jwt=") || got == hostURL {
+		t.Errorf("invite url = %q, want a guest token for %s", got, room)
+	}
+}
+
+func TestSlashCommandRejectsUnsignedRequest(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	r := slashCommand(t, "")
+	r.Header.Set(RequestSignatureHeader, "v0=00")
+
+	w := httptest.NewRecorder()
+	s.Jitsi(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
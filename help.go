@@ -0,0 +1,81 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const helpTemplate = `{"response_type":"ephemeral","text":"How to use %s...","attachments":[{"text":%s}]}`
+
+// helpLine describes how to use part of the command. Lines for a subcommand
+// are only shown while it's enabled.
+type helpLine struct {
+	subcommand string
+	// text is formatted with the command name.
+	text string
+}
+
+var helpLines = []helpLine{
+	{"", "To share a conference link with the channel, use '%[1]s'. Now everyone can join."},
+	{"", "To share a conference link with users, use '%[1]s @bob @alice'. Now you can meet with Bob and Alice."},
+	{subcommandLobby, "To have invitees wait in a lobby until you admit them, use '%[1]s lobby @bob @alice'."},
+	{subcommandInviteChannel, "To invite everyone in the channel individually, use '%[1]s invite-channel'."},
+	{"", "To only invite people who are active, add '--active-only'."},
+	{"", "To limit how many people can join, add '--max-occupants=10'."},
+	{"", "To only show yourself the meeting link, add '--private', or '--public' to post it to the channel."},
+	{subcommandGuest, "To get a link for guests without Slack, use '%[1]s guest'."},
+	{subcommandChannelRoom, "To use the same room every time in a channel, use '%[1]s channel-room', or '%[1]s channel-room reset' to change it."},
+	{subcommandTemplate, "To save invitees for a recurring meeting, use '%[1]s template save standup @bob @alice', then start it with '%[1]s template run standup'."},
+	{subcommandWho, "To see who is in a meeting, use '%[1]s who <room>'."},
+	{subcommandFeatures, "To see conference features, use '%[1]s features', admins can change them with '%[1]s features recording=on livestreaming=off'."},
+	{subcommandSetDuration, "Admins can change how long meeting links are valid for with '%[1]s set-duration 45m'."},
+	{subcommandAuth, "Admins can turn tokens on meeting links on or off with '%[1]s auth on' or '%[1]s auth off'."},
+	{subcommandExport, "Admins can copy the workspace config to another installation with '%[1]s export', then '%[1]s import <config>' there."},
+	{subcommandCopyConfig, "Admins of two workspaces can copy the workspace config to the other with '%[1]s copy-config <workspace id>'."},
+	{subcommandWhoami, "To get details for a support request, use '%[1]s whoami'."},
+	{subcommandTokens, "Admins can list stored tokens with '%[1]s tokens' and revoke them with '%[1]s tokens revoke'."},
+}
+
+// subcommandEnabled reports whether a subcommand is supported by how the
+// handlers are configured.
+func (s *SlashCommandHandlers) subcommandEnabled(subcommand string) bool {
+	switch subcommand {
+	case subcommandChannelRoom:
+		return s.ChannelRooms != nil
+	case subcommandTemplate:
+		return s.Templates != nil
+	case subcommandWho:
+		return s.ParticipantReader != nil
+	case subcommandFeatures:
+		return s.Features != nil
+	case subcommandTokens:
+		return s.TokenAdmin != nil
+	case subcommandSetDuration, subcommandAuth, subcommandExport, subcommandImport, subcommandCopyConfig:
+		return s.ServerConfigs != nil
+	}
+	return true
+}
+
+// help describes how to use the command, leaving out subcommands that
+// aren't enabled or whose capability is turned off for the team.
+func (s *SlashCommandHandlers) help(ctx context.Context, teamID string) (CommandResult, error) {
+	cfg, err := s.teamServerConfig(ctx, teamID)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	commandName := s.commandName()
+	var lines []string
+	for _, line := range helpLines {
+		if !s.subcommandEnabled(line.subcommand) {
+			continue
+		}
+		if name, ok := subcommandCapabilities[line.subcommand]; ok && !cfg.Capabilities.Enabled(name) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(line.text, commandName))
+	}
+	text, _ := json.Marshal(strings.Join(lines, "\n"))
+	return CommandResult{Body: fmt.Sprintf(helpTemplate, commandName, text)}, nil
+}
@@ -0,0 +1,111 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// helpText decodes the title and lines of a help response.
+func helpText(t *testing.T, result CommandResult) (title, text string) {
+	t.Helper()
+	var help struct {
+		Text        string `json:"text"`
+		Attachments []struct {
+			Text string `json:"text"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &help); err != nil || len(help.Attachments) != 1 {
+		t.Fatalf("decoding help %s: %v", result.Body, err)
+	}
+	return help.Text, help.Attachments[0].Text
+}
+
+func TestHelpUsesCommandName(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.CommandName = "/video"
+
+	title, text := helpText(t, processCommand(t, s, "help"))
+	if title != "How to use /video..." {
+		t.Errorf("help title = %q, want it to name /video", title)
+	}
+	if strings.Contains(text, "/jitsi") || !strings.Contains(text, "'/video @bob @alice'") {
+		t.Errorf("help = %q, want every example to use /video", text)
+	}
+}
+
+func TestHelpDefaultsToJitsi(t *testing.T) {
+	title, text := helpText(t, processCommand(t, newTestHandlers(t, newFakeSlack(t)), "help"))
+	if title != "How to use /jitsi..." || !strings.Contains(text, "'/jitsi'") {
+		t.Errorf("help = %q %q, want it to use /jitsi", title, text)
+	}
+}
+
+func TestUsageUsesCommandName(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.CommandName = "/video"
+	s.Templates = &MemoryTemplateStore{}
+	s.ServerConfigs = &MemoryServerConfigStore{}
+
+	tests := map[string]string{
+		"template":             fmt.Sprintf(templateUsageMsg, "/video"),
+		"set-duration forever": fmt.Sprintf(setDurationUsageMsg, "/video"),
+	}
+	for text, want := range tests {
+		if _, got := responseOf(t, processCommand(t, s, text)); got != want {
+			t.Errorf("%q = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestHelpLeavesOutDisabledCapabilities(t *testing.T) {
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{Capabilities: Capabilities{capabilityGuest: false, capabilityTemplate: false}})
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerConfigs = configs
+	s.Templates = &MemoryTemplateStore{}
+	s.ChannelRooms = &MemoryChannelRoomStore{}
+	s.Capabilities = Capabilities{capabilityLobby: false}
+
+	_, text := helpText(t, processCommand(t, s, "help"))
+	for _, disabled := range []string{"'/jitsi guest'", "'/jitsi template save", "'/jitsi lobby @bob @alice'"} {
+		if strings.Contains(text, disabled) {
+			t.Errorf("help = %q, want %s left out", text, disabled)
+		}
+	}
+	for _, enabled := range []string{"'/jitsi channel-room'", "'/jitsi invite-channel'", "'/jitsi @bob @alice'"} {
+		if !strings.Contains(text, enabled) {
+			t.Errorf("help = %q, want %s", text, enabled)
+		}
+	}
+
+	// Other teams keep the capabilities the operator didn't turn off.
+	result, err := s.ProcessCommand(context.Background(), CommandInput{TeamID: "T2", TeamName: "globex", UserID: "UHOST", Text: "help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, text = helpText(t, result)
+	if !strings.Contains(text, "'/jitsi guest'") || !strings.Contains(text, "'/jitsi template save") || strings.Contains(text, "'/jitsi lobby") {
+		t.Errorf("help = %q, want guest and template links but no lobby", text)
+	}
+}
+
+func TestHelpLeavesOutUnconfiguredSubcommands(t *testing.T) {
+	_, text := helpText(t, processCommand(t, newTestHandlers(t, newFakeSlack(t)), "help"))
+	for _, unconfigured := range []string{"channel-room", "template", "who <room>", "features", "set-duration", "auth on", "export", "copy-config", "tokens"} {
+		if strings.Contains(text, unconfigured) {
+			t.Errorf("help = %q, want %s left out", text, unconfigured)
+		}
+	}
+}
+
+func TestHelpServerConfigFailure(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerConfigs = stubServerConfigs{err: errors.New("table unavailable")}
+	if _, err := s.ProcessCommand(context.Background(), CommandInput{TeamID: testTeamID, TeamName: testTeamDomain, UserID: "UHOST", Text: "help"}); err == nil {
+		t.Error("help succeeded, want the config error")
+	}
+}
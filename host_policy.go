@@ -0,0 +1,44 @@
+package jitsi
+
+import (
+	"context"
+
+	"github.com/nlopes/slack"
+)
+
+const notHostMsg = "You aren't allowed to start meetings in this workspace."
+
+// HostPolicy restricts who can start meetings. Anyone can start meetings
+// when it allows nobody.
+type HostPolicy struct {
+	// Users are the ids of the users allowed to start meetings.
+	Users []string
+	// Admins allows workspace admins and owners to start meetings.
+	Admins bool
+}
+
+func (p HostPolicy) restricted() bool {
+	return len(p.Users) > 0 || p.Admins
+}
+
+// canHost reports whether the HostPolicy allows a user to start meetings.
+// Slack user info is only retrieved when admins are allowed and the user
+// isn't listed.
+func (s *SlashCommandHandlers) canHost(ctx context.Context, client *slack.Client, teamID, userID string) (bool, error) {
+	if !s.HostPolicy.restricted() {
+		return true, nil
+	}
+	for _, allowed := range s.HostPolicy.Users {
+		if userID == allowed {
+			return true, nil
+		}
+	}
+	if !s.HostPolicy.Admins {
+		return false, nil
+	}
+	user, err := s.fetchUserInfo(ctx, client, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.IsAdmin || user.IsOwner, nil
+}
@@ -0,0 +1,83 @@
+package jitsi
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHostPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   HostPolicy
+		admin    bool
+		allowed  bool
+		lookedUp bool
+	}{
+		{"unrestricted", HostPolicy{}, false, true, false},
+		{"listed", HostPolicy{Users: []string{"UHOST"}, Admins: true}, false, true, false},
+		{"not listed", HostPolicy{Users: []string{"UALICE"}}, false, false, false},
+		{"admin", HostPolicy{Admins: true}, true, true, true},
+		{"not admin", HostPolicy{Users: []string{"UALICE"}, Admins: true}, false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			if tt.admin {
+				slack.Handle("users.info", adminUserInfo)
+			}
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.HostPolicy = tt.policy
+
+			// Guest links don't otherwise look the caller up.
+			for _, text := range []string{"guest", "", "<@UBOB>", "lobby <@UBOB>"} {
+				result := processCommand(t, s, text)
+				_, reply := responseOf(t, result)
+				if refused := reply == notHostMsg; refused == tt.allowed {
+					t.Errorf("%q = %s, want allowed %v", text, result.Body, tt.allowed)
+				}
+				if text != "guest" {
+					continue
+				}
+				if got := lookedUp(slack, "UHOST"); got != tt.lookedUp {
+					t.Errorf("looked up the caller = %v, want %v", got, tt.lookedUp)
+				}
+			}
+		})
+	}
+}
+
+func TestHostPolicyAllowsReadOnlySubcommands(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.HostPolicy = HostPolicy{Users: []string{"UALICE"}}
+
+	for _, text := range []string{"help", "whoami"} {
+		if _, reply := responseOf(t, processCommand(t, s, text)); reply == notHostMsg {
+			t.Errorf("%s refused, want it allowed for anyone", text)
+		}
+	}
+}
+
+func TestHostPolicyRefusedInvitesNobody(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.HostPolicy = HostPolicy{Users: []string{"UALICE"}}
+
+	processCommand(t, s, "<@UBOB>")
+	if calls := slack.Calls("chat.postMessage"); len(calls) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want no invites", len(calls))
+	}
+}
+
+func TestHostPolicyReactionMeetings(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.HostPolicy = HostPolicy{Users: []string{"UALICE"}}
+
+	s.reactionMeeting(context.Background(), testTeamID, reactionEvent{Type: eventTypeReactionAdded, User: "UBOB", Reaction: "video_camera"})
+	if calls := slack.Calls("chat.postMessage"); len(calls) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want no meeting for a user not allowed to host", len(calls))
+	}
+}
@@ -0,0 +1,37 @@
+package jitsi
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// NewHTTPClient creates an http client for outbound requests to Slack and
+// conference services. When proxyURL is set all requests are sent through
+// it, with any credentials in the url used for proxy auth. Otherwise the
+// standard proxy environment variables are respected.
+func NewHTTPClient(proxyURL string, timeout time.Duration) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+// httpClientOrDefault returns c or the default http client when c is nil.
+func httpClientOrDefault(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
@@ -0,0 +1,112 @@
+package jitsi
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubProxy records the requests sent through it. Plain http requests are
+// answered with body and tunnels are refused.
+type stubProxy struct {
+	srv  *httptest.Server
+	body string
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func newStubProxy(t *testing.T, body string) *stubProxy {
+	t.Helper()
+	p := &stubProxy{body: body}
+	p.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.requests = append(p.requests, r)
+		p.mu.Unlock()
+		if r.Method == http.MethodConnect {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(p.body))
+	}))
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+// URL returns the proxy url with credentials for proxy auth.
+func (p *stubProxy) URL() string {
+	u, _ := url.Parse(p.srv.URL)
+	u.User = url.UserPassword("proxyuser", "proxypass")
+	return u.String()
+}
+
+func (p *stubProxy) Requests() []*http.Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*http.Request(nil), p.requests...)
+}
+
+func TestHTTPClientUsesProxy(t *testing.T) {
+	proxy := newStubProxy(t, testAccessResponse)
+	client, err := NewHTTPClient(proxy.URL(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &SlackOAuthHandlers{
+		AccessURLTemplate: "http://slack.test/api/oauth.v2.access?client_id=%s&client_secret=%s&code=%s",
+		ClientID:          "client",
+		ClientSecret:      "secret",
+		AppID:             "A1",
+		TokenWriter:       &MemoryTokenStore{},
+		HTTPClient:        client,
+	}
+
+	w := httptest.NewRecorder()
+	o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?code=abc", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, want the install to complete through the proxy", w.Code)
+	}
+
+	requests := proxy.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("proxy got %d requests, want the oauth exchange and the team lookup", len(requests))
+	}
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("proxyuser:proxypass"))
+	for _, r := range requests {
+		if got := r.Header.Get("Proxy-Authorization"); got != wantAuth {
+			t.Errorf("%s %s sent proxy auth %q, want %q", r.Method, r.Host, got, wantAuth)
+		}
+	}
+	if exchange := requests[0]; exchange.URL.Host != "slack.test" || exchange.URL.Query().Get("code") != "abc" {
+		t.Errorf("oauth exchange = %s, want slack.test with the code", exchange.URL)
+	}
+	// The Slack client tunnels its https requests through the proxy.
+	if tunnel := requests[1]; tunnel.Method != http.MethodConnect || tunnel.Host != "slack.com:443" {
+		t.Errorf("slack api request = %s %s, want a tunnel to slack.com", tunnel.Method, tunnel.Host)
+	}
+}
+
+func TestHTTPClientRejectsBadProxyURL(t *testing.T) {
+	if _, err := NewHTTPClient("http://[::1", time.Second); err == nil {
+		t.Error("NewHTTPClient accepted a malformed proxy url")
+	}
+}
+
+func TestHTTPClientTimeout(t *testing.T) {
+	client, err := NewHTTPClient("", 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer srv.Close()
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Error("request outlived the client timeout")
+	}
+}
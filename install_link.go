@@ -0,0 +1,71 @@
+package jitsi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInstallLinkInvalid = errors.New("install link state is invalid")
+	errInstallLinkExpired = errors.New("install link has expired")
+)
+
+// InstallLinkSigner signs install links with an expiry so they can't be
+// shared indefinitely. The signature is carried through the oauth flow in
+// the state parameter, which Slack returns to the oauth redirect.
+type InstallLinkSigner struct {
+	// Secret is the key install links are signed with.
+	Secret string
+	// Validity is how long a signed install link can be used for.
+	Validity time.Duration
+}
+
+func (l *InstallLinkSigner) mac(expiry string) string {
+	hasher := hmac.New(sha256.New, []byte(l.Secret))
+	hasher.Write([]byte(expiry))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Sign adds a state to rawURL that expires after the validity window.
+func (l *InstallLinkSigner) Sign(rawURL string, now time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	expiry := strconv.FormatInt(now.Add(l.Validity).Unix(), 10)
+	params := u.Query()
+	params.Set("state", fmt.Sprintf("%s.%s", expiry, l.mac(expiry)))
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// Verify checks that state was signed by Sign and hasn't expired.
+func (l *InstallLinkSigner) Verify(state string, now time.Time) error {
+	parts := strings.SplitN(state, ".", 2)
+	if len(parts) != 2 {
+		return errInstallLinkInvalid
+	}
+	theirMAC, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return errInstallLinkInvalid
+	}
+	ourMAC, _ := hex.DecodeString(l.mac(parts[0]))
+	if !hmac.Equal(ourMAC, theirMAC) {
+		return errInstallLinkInvalid
+	}
+	expiry, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return errInstallLinkInvalid
+	}
+	if now.After(time.Unix(expiry, 0)) {
+		return errInstallLinkExpired
+	}
+	return nil
+}
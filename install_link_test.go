@@ -0,0 +1,126 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedState signs the test install url and returns its state.
+func signedState(t *testing.T, l *InstallLinkSigner, now time.Time) string {
+	t.Helper()
+	signed, err := l.Sign(testInstallURL, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("state")
+}
+
+func TestInstallLinkSigner(t *testing.T) {
+	l := &InstallLinkSigner{Secret: "secret", Validity: time.Hour}
+	now := time.Unix(1700000000, 0)
+	state := signedState(t, l, now)
+	expiry := strings.SplitN(state, ".", 2)[0]
+	tests := []struct {
+		name  string
+		state string
+		now   time.Time
+		want  error
+	}{
+		{"valid", state, now, nil},
+		{"valid until expiry", state, now.Add(time.Hour - time.Second), nil},
+		{"expired", state, now.Add(time.Hour + time.Second), errInstallLinkExpired},
+		{"tampered expiry", "9999999999" + strings.TrimPrefix(state, expiry), now, errInstallLinkInvalid},
+		{"tampered signature", state[:len(state)-2] + "00", now, errInstallLinkInvalid},
+		{"other secret", signedState(t, &InstallLinkSigner{Secret: "other", Validity: time.Hour}, now), now, errInstallLinkInvalid},
+		{"not hex", expiry + ".zz", now, errInstallLinkInvalid},
+		{"missing", "", now, errInstallLinkInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.Verify(tt.state, tt.now); got != tt.want {
+				t.Errorf("Verify(%q) = %v, want %v", tt.state, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallLinkSignerKeepsQuery(t *testing.T) {
+	l := &InstallLinkSigner{Secret: "secret", Validity: time.Hour}
+	signed, err := l.Sign(testInstallURL, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := url.Parse(testInstallURL)
+	got, _ := url.Parse(signed)
+	for key := range want.Query() {
+		if got.Query().Get(key) != want.Query().Get(key) {
+			t.Errorf("signed url %s lost %s from %s", signed, key, testInstallURL)
+		}
+	}
+}
+
+func TestAuthInstallLinks(t *testing.T) {
+	l := &InstallLinkSigner{Secret: "secret", Validity: time.Hour}
+	now := time.Now()
+	tests := []struct {
+		name   string
+		state  string
+		status int
+		code   string
+		stored bool
+	}{
+		{"valid", signedState(t, l, now), http.StatusFound, "", true},
+		{"expired", signedState(t, l, now.Add(-2*time.Hour)), http.StatusBadRequest, errOAuthLinkExpired, false},
+		{"tampered", signedState(t, &InstallLinkSigner{Secret: "other", Validity: time.Hour}, now), http.StatusBadRequest, errOAuthBadRequest, false},
+		{"unsigned", "", http.StatusBadRequest, errOAuthBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.Handle("oauth.v2.access", testAccessResponse)
+			tokens := &MemoryTokenStore{}
+			o := newTestOAuthHandlers(slack, tokens)
+			o.InstallLinks = l
+			o.JSONErrors = true
+
+			w := httptest.NewRecorder()
+			o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?code=abc&state="+url.QueryEscape(tt.state), nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.code != "" {
+				var envelope oauthError
+				if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil || envelope.Error != tt.code {
+					t.Errorf("response = %s, want error %s", w.Body, tt.code)
+				}
+				if calls := slack.Calls("oauth.v2.access"); len(calls) != 0 {
+					t.Errorf("oauth.v2.access calls = %d, want the code left unexchanged", len(calls))
+				}
+			}
+			if _, err := tokens.GetFirstTokenDataForTeam("T1"); (err == nil) != tt.stored {
+				t.Errorf("install stored = %v, want %v", err == nil, tt.stored)
+			}
+		})
+	}
+}
+
+func TestAuthExpiredInstallLinkPage(t *testing.T) {
+	l := &InstallLinkSigner{Secret: "secret", Validity: time.Hour}
+	o := newTestOAuthHandlers(newFakeSlack(t), &MemoryTokenStore{})
+	o.InstallLinks = l
+
+	w := httptest.NewRecorder()
+	o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?code=abc&state="+url.QueryEscape(signedState(t, l, time.Now().Add(-2*time.Hour))), nil))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "This install link has expired.") {
+		t.Errorf("response = %d %s, want the expired link page", w.Code, w.Body)
+	}
+}
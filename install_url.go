@@ -0,0 +1,45 @@
+package jitsi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type installURLResponse struct {
+	URL         string `json:"url"`
+	SharableURL string `json:"sharable_url"`
+}
+
+// InstallLink responds with the url installs are started from, signed when
+// InstallLinks is set, for support and onboarding tools. Requests must carry
+// the AdminAPIToken as a bearer token, and the endpoint isn't found when no
+// token is configured.
+func (s *SlashCommandHandlers) InstallLink(w http.ResponseWriter, r *http.Request) {
+	if !s.adminRequest(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(installURLResponse{
+		URL:         s.installURL(),
+		SharableURL: s.SharableURL,
+	})
+}
+
+// adminRequest checks that a request for an admin endpoint carries the
+// AdminAPIToken as a bearer token. Admin endpoints aren't found when no
+// token is configured.
+func (s *SlashCommandHandlers) adminRequest(w http.ResponseWriter, r *http.Request) bool {
+	if s.AdminAPIToken == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminAPIToken)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testAuthorizeURL = "https://slack.com/oauth/v2/authorize?client_id=123.456&scope=commands,chat:write,users:read"
+
+// installLinkResponse requests the install link with token.
+func installLinkResponse(t *testing.T, s *SlashCommandHandlers, token string) (*httptest.ResponseRecorder, installURLResponse) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, PathInstallURL, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.InstallLink(w, r)
+	var resp installURLResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decoding %s: %v", w.Body, err)
+		}
+	}
+	return w, resp
+}
+
+func TestInstallLink(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.AdminAPIToken = "admin"
+	s.InstallURL = testAuthorizeURL
+	s.SharableURL = "https://slack.com/apps/A1"
+	s.InstallLinks = &InstallLinkSigner{Secret: "secret", Validity: time.Hour}
+
+	w, resp := installLinkResponse(t, s, "admin")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("response = %d %v, want json", w.Code, w.Header())
+	}
+	u, err := url.Parse(resp.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("client_id") != "123.456" || query.Get("scope") != "commands,chat:write,users:read" {
+		t.Errorf("install url = %s, want the client id and scopes", resp.URL)
+	}
+	if err := s.InstallLinks.Verify(query.Get("state"), time.Now()); err != nil {
+		t.Errorf("install url state %q: %v, want it signed", query.Get("state"), err)
+	}
+	if resp.SharableURL != "https://slack.com/apps/A1" {
+		t.Errorf("sharable url = %q, want the configured one", resp.SharableURL)
+	}
+}
+
+func TestInstallLinkUnsigned(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.AdminAPIToken = "admin"
+	s.InstallURL = testAuthorizeURL
+
+	if _, resp := installLinkResponse(t, s, "admin"); resp.URL != testAuthorizeURL {
+		t.Errorf("install url = %s, want %s", resp.URL, testAuthorizeURL)
+	}
+
+	s.InstallURL = ""
+	s.SharableURL = "https://slack.com/apps/A1"
+	if _, resp := installLinkResponse(t, s, "admin"); resp.URL != s.SharableURL {
+		t.Errorf("install url = %s, want the sharable url", resp.URL)
+	}
+}
+
+func TestInstallLinkNeedsAdminToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		token      string
+		status     int
+	}{
+		{"no token configured", "", "admin", http.StatusNotFound},
+		{"no token", "admin", "", http.StatusUnauthorized},
+		{"wrong token", "admin", "nope", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHandlers(t, newFakeSlack(t))
+			s.AdminAPIToken = tt.configured
+			if w, _ := installLinkResponse(t, s, tt.token); w.Code != tt.status || w.Body.Len() != 0 {
+				t.Errorf("response = %d %q, want an empty %d", w.Code, w.Body, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+const (
+	confirmBroadcastTemplate     = `{"response_type":"ephemeral","text":"This channel has a lot of members. Post the meeting link to everyone?","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"This channel has a lot of members. Post the meeting link to everyone?"}},{"type":"actions","elements":[{"type":"button","action_id":"%s","text":{"type":"plain_text","text":"Post to channel"},"style":"primary","value":"%s"}]}]}`
+	confirmInviteChannelTemplate = `{"response_type":"ephemeral","text":"This will send a meeting invite to each of the %[1]d members of this channel. Continue?","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"This will send a meeting invite to each of the %[1]d members of this channel. Continue?"}},{"type":"actions","elements":[{"type":"button","action_id":"%[2]s","text":{"type":"plain_text","text":"Invite everyone"},"style":"primary","value":"%[3]s"}]}]}`
+
+	inviteChannelEmptyMsg    = "There's nobody else in this channel to invite."
+	inviteChannelTooLargeMsg = "This channel has %d members to invite, more than the %d invite-channel is allowed to invite. Mention the people to invite instead."
+
+	actionPostMeeting   = "post_meeting"
+	actionInviteChannel = "invite_channel"
+)
+
+// confirmedRoomTemplate is the channel roomTemplate, replacing the
+// confirmation prompt it answers.
+var confirmedRoomTemplate = strings.Replace(roomTemplate, `"response_type":"%[6]s"`, `"response_type":"in_channel","delete_original":true`, 1)
+
+type interactionAction struct {
+	ActionID string `json:"action_id"`
+	// Name identifies the actions of message attachments, which don't
+	// have an action id.
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type interactionID struct {
+	ID     string `json:"id"`
+	Domain string `json:"domain"`
+}
+
+type interactionPayload struct {
+	Type        string              `json:"type"`
+	ResponseURL string              `json:"response_url"`
+	TriggerID   string              `json:"trigger_id"`
+	CallbackID  string              `json:"callback_id"`
+	Actions     []interactionAction `json:"actions"`
+	User        interactionID       `json:"user"`
+	Team        interactionID       `json:"team"`
+	Channel     interactionID       `json:"channel"`
+	View        struct {
+		CallbackID string `json:"callback_id"`
+		State      struct {
+			Values map[string]map[string]struct {
+				Value string `json:"value"`
+			} `json:"values"`
+		} `json:"state"`
+	} `json:"view"`
+	WorkflowStep struct {
+		WorkflowStepEditID string `json:"workflow_step_edit_id"`
+	} `json:"workflow_step"`
+}
+
+// largeChannel reports whether a channel has at least ConfirmChannelSize
+// members. Failures are logged and treated as a small channel so the link
+// is still posted.
+func (s *SlashCommandHandlers) largeChannel(ctx context.Context, client *slack.Client, channelID string) bool {
+	if s.ConfirmChannelSize <= 0 || channelID == "" {
+		return false
+	}
+	var members []string
+	var cursor string
+	err := s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		members, cursor, err = client.GetUsersInConversationContext(
+			ctx,
+			&slack.GetUsersInConversationParameters{
+				ChannelID: channelID,
+				Limit:     s.ConfirmChannelSize,
+			},
+		)
+		return err
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("counting channel members")
+		return false
+	}
+	return len(members) >= s.ConfirmChannelSize || cursor != ""
+}
+
+func confirmInviteChannel(members int, activeOnly bool) CommandResult {
+	value := subcommandInviteChannel
+	if activeOnly {
+		value += " " + flagActiveOnly
+	}
+	return CommandResult{
+		Body: fmt.Sprintf(confirmInviteChannelTemplate, members, actionInviteChannel, value),
+	}
+}
+
+// confirmBroadcast asks to post a meeting to a large channel. The button
+// value carries the start time and meeting url as "<unix seconds> <url>" so
+// the posted message shows when the meeting started rather than when it
+// was confirmed.
+func confirmBroadcast(meetingURL string, started time.Time) CommandResult {
+	value := fmt.Sprintf("%d %s", started.Unix(), meetingURL)
+	return CommandResult{Body: fmt.Sprintf(confirmBroadcastTemplate, actionPostMeeting, value)}
+}
+
+// confirmedBroadcast returns the meeting url and start time from a post
+// meeting button value. Values without a start time are treated as
+// starting now.
+func confirmedBroadcast(value string) (string, time.Time) {
+	fields := strings.SplitN(value, " ", 2)
+	if len(fields) == 2 {
+		unix, err := strconv.ParseInt(fields[0], 10, 64)
+		if err == nil {
+			return fields[1], time.Unix(unix, 0)
+		}
+	}
+	return value, time.Now()
+}
+
+// Interaction handles Slack interactive component requests such as the
+// confirmation to post a meeting link to a large channel.
+func (s *SlashCommandHandlers) Interaction(w http.ResponseWriter, r *http.Request) {
+	if !s.validRequest(w, r) {
+		return
+	}
+	s.handleInteraction(w, r)
+}
+
+// handleInteraction processes an interaction request that has already been
+// verified as originating from Slack.
+func (s *SlashCommandHandlers) handleInteraction(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		hlog.FromRequest(r).Error().
+			Err(err).
+			Msg("unable to parse form data")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var payload interactionPayload
+	err = json.Unmarshal([]byte(r.PostFormValue("payload")), &payload)
+	if err != nil {
+		hlog.FromRequest(r).Error().
+			Err(err).
+			Msg("unable to decode interaction payload")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case payload.Type == interactionWorkflowStepEdit && payload.CallbackID == workflowStepCallbackID:
+		err = s.editWorkflowStep(r.Context(), payload)
+		if err != nil {
+			hlog.FromRequest(r).Error().
+				Err(err).
+				Msg("opening workflow step configuration")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	case payload.Type == interactionViewSubmission && payload.View.CallbackID == workflowStepCallbackID:
+		if errs, ok := s.workflowStepErrors(payload); ok {
+			w.Header().Set("Content-type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(errs))
+			return
+		}
+		err = s.saveWorkflowStep(r.Context(), payload)
+		if err != nil {
+			hlog.FromRequest(r).Error().
+				Err(err).
+				Msg("saving workflow step configuration")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	for _, action := range payload.Actions {
+		actionID := action.ActionID
+		if actionID == "" {
+			actionID = action.Name
+		}
+		switch actionID {
+		case actionPostMeeting:
+			meetingURL, started := confirmedBroadcast(action.Value)
+			debounce := s.ChannelDebounce != nil && payload.Channel.ID != ""
+			if debounce {
+				if recentURL, ok := s.ChannelDebounce.Recent(payload.Team.ID, payload.Channel.ID); ok {
+					text, _ := json.Marshal(fmt.Sprintf(recentMeetingMsg, recentURL, path.Base(recentURL)))
+					err = s.respond(payload.ResponseURL, fmt.Sprintf(recentMeetingReplaceTemplate, text))
+					if err != nil {
+						hlog.FromRequest(r).Error().
+							Err(err).
+							Msg("responding with recent meeting")
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					continue
+				}
+			}
+			serverCfg, err := s.teamServerConfig(r.Context(), payload.Team.ID)
+			if err != nil {
+				hlog.FromRequest(r).Error().
+					Err(err).
+					Msg("retrieving server config")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			room := path.Base(meetingURL)
+			fallbackText := jsonFallback(s.Fallbacks.Room, defaultRoomFallback, payload.User.ID, serverCfg.ConferenceHost, room)
+			msg := fmt.Sprintf(confirmedRoomTemplate, meetingURL, room, startedText(started), s.titlePrefix(), fallbackText)
+			err = s.respond(payload.ResponseURL, msg)
+			if err != nil {
+				hlog.FromRequest(r).Error().
+					Err(err).
+					Msg("posting meeting to channel")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if debounce {
+				s.ChannelDebounce.Record(payload.Team.ID, payload.Channel.ID, meetingURL)
+			}
+		case actionInviteChannel:
+			// Inviting a large channel outlasts the interaction response
+			// deadline so the result is sent to the response url instead.
+			ctx := hlog.FromRequest(r).WithContext(context.Background())
+			activeOnly := strings.Contains(action.Value, flagActiveOnly)
+			if !s.async(ctx, func() { s.inviteChannel(ctx, payload, activeOnly) }) {
+				s.respondBusy(ctx, payload)
+			}
+		case actionRetryCommand:
+			ctx := hlog.FromRequest(r).WithContext(context.Background())
+			text := action.Value
+			if !s.async(ctx, func() { s.runCommand(ctx, payload, text) }) {
+				s.respondBusy(ctx, payload)
+			}
+		case actionHomeStartMeeting:
+			ctx := hlog.FromRequest(r).WithContext(context.Background())
+			if !s.async(ctx, func() { s.homeMeeting(ctx, payload) }) {
+				s.respondBusy(ctx, payload)
+			}
+		case actionAcknowledgeInvite:
+			err = s.acknowledgeInvite(r.Context(), payload, action.Value)
+			if err != nil {
+				hlog.FromRequest(r).Error().
+					Err(err).
+					Msg("acknowledging invite")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// inviteChannel invites the members of the channel a confirmation was
+// accepted in and replaces the confirmation with the result.
+func (s *SlashCommandHandlers) inviteChannel(ctx context.Context, payload interactionPayload, activeOnly bool) {
+	text := subcommandInviteChannel + " " + inviteChannelConfirmed
+	if activeOnly {
+		text += " " + flagActiveOnly
+	}
+	s.runCommand(ctx, payload, text)
+}
+
+// runCommand runs command text for the user of an interaction and replaces
+// the message the interaction came from with the result. A failure is
+// replaced with a button to try again.
+func (s *SlashCommandHandlers) runCommand(ctx context.Context, payload interactionPayload, text string) {
+	log := zerolog.Ctx(ctx)
+	result, err := s.ProcessCommand(ctx, CommandInput{
+		TeamID:    payload.Team.ID,
+		TeamName:  payload.Team.Domain,
+		UserID:    payload.User.ID,
+		ChannelID: payload.Channel.ID,
+		Text:      text,
+	})
+	if err != nil {
+		result = commandFailed(text)
+	}
+
+	var msg map[string]interface{}
+	err = json.Unmarshal([]byte(result.Body), &msg)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("decoding command result")
+		return
+	}
+	msg["replace_original"] = true
+	body, _ := json.Marshal(msg)
+	err = s.respond(payload.ResponseURL, string(body))
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("responding with command result")
+	}
+}
+
+// respondBusy tells the user an interaction was dropped since the workers
+// are saturated.
+func (s *SlashCommandHandlers) respondBusy(ctx context.Context, payload interactionPayload) {
+	if payload.ResponseURL == "" {
+		return
+	}
+	err := s.respond(payload.ResponseURL, ephemeral(busyMsg).Body)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("responding that workers are busy")
+	}
+}
+
+// respond sends a message to a Slack response url.
+func (s *SlashCommandHandlers) respond(responseURL, msg string) error {
+	resp, err := httpClientOrDefault(s.HTTPClient).Post(
+		responseURL,
+		"application/json",
+		bytes.NewBufferString(msg),
+	)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// interact posts a signed interaction payload to the handlers.
+func interact(t *testing.T, s *SlashCommandHandlers, payload string) *httptest.ResponseRecorder {
+	t.Helper()
+	form := url.Values{"payload": {payload}}
+	w := httptest.NewRecorder()
+	s.Interaction(w, signedRequest(t, PathInteraction, "application/x-www-form-urlencoded", form.Encode()))
+	return w
+}
+
+func postMeetingPayload(t *testing.T, value string) string {
+	t.Helper()
+	return `{
+		"type": "block_actions",
+		"response_url": "https://hooks.slack.com/actions/T1/1/abc",
+		"user": {"id": "UHOST"},
+		"team": {"id": "T1", "domain": "acme"},
+		"channel": {"id": "C1"},
+		"actions": [{"action_id": "post_meeting", "value": "` + mustJSON(t, value) + `"}]
+	}`
+}
+
+func TestLargeChannelConfirmsBroadcast(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("conversations.members", `{"ok":true,"members":["UHOST","UBOB","UALICE"],"response_metadata":{"next_cursor":""}}`)
+	s := newTestHandlers(t, slack)
+	s.ConfirmChannelSize = 3
+
+	result := processCommand(t, s, "")
+	var prompt struct {
+		ResponseType string `json:"response_type"`
+		Blocks       []struct {
+			Elements []struct {
+				ActionID string `json:"action_id"`
+				Value    string `json:"value"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &prompt); err != nil {
+		t.Fatal(err)
+	}
+	if prompt.ResponseType != "ephemeral" || len(prompt.Blocks) != 2 || len(prompt.Blocks[1].Elements) != 1 {
+		t.Fatalf("reply = %s, want an ephemeral confirmation", result.Body)
+	}
+	button := prompt.Blocks[1].Elements[0]
+	if button.ActionID != actionPostMeeting || !strings.Contains(button.Value, testConfHost+"/") {
+		t.Errorf("button = %+v, want a post meeting button with the meeting url", button)
+	}
+}
+
+func TestConfirmedBroadcastPostsMeeting(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	if w := interact(t, s, postMeetingPayload(t, "1700000000 "+testConfHost+"/acme/BraveTiger")); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	responses := slack.Calls("actions/T1/1/abc")
+	if len(responses) != 1 {
+		t.Fatalf("responses = %d, want 1", len(responses))
+	}
+	body := responses[0].Body
+	if body["response_type"] != "in_channel" || body["delete_original"] != true {
+		t.Errorf("response = %v, want an in channel post replacing the prompt", body)
+	}
+	if !strings.Contains(string(mustMarshal(t, body)), testConfHost+"/acme/BraveTiger") {
+		t.Errorf("response = %v, want the meeting url", body)
+	}
+}
+
+func TestConfirmedBroadcastUsesTeamHost(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.Fallbacks.Room = "Meeting on {{.Server}}"
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{ConferenceHost: "https://team.example.com"})
+	s.ServerConfigs = configs
+
+	interact(t, s, postMeetingPayload(t, "https://team.example.com/acme/BraveTiger"))
+	responses := slack.Calls("actions/T1/1/abc")
+	if len(responses) != 1 {
+		t.Fatalf("responses = %d, want 1", len(responses))
+	}
+	if got := string(mustMarshal(t, responses[0].Body)); !strings.Contains(got, "Meeting on https://team.example.com") {
+		t.Errorf("response = %s, want the fallback to name the team's host", got)
+	}
+}
+
+func TestConfirmedBroadcastKeepsStartTime(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	interact(t, s, postMeetingPayload(t, "1700000000 "+testConfHost+"/acme/BraveTiger"))
+	responses := slack.Calls("actions/T1/1/abc")
+	if len(responses) != 1 {
+		t.Fatalf("responses = %d, want 1", len(responses))
+	}
+	if got, want := string(mustMarshal(t, responses[0].Body)), mustJSON(t, startedText(time.Unix(1700000000, 0))); !strings.Contains(got, want) {
+		t.Errorf("response = %s, want the start time %s", got, want)
+	}
+}
+
+func TestConfirmedBroadcastValue(t *testing.T) {
+	started := time.Unix(1700000000, 0)
+	meetingURL, got := confirmedBroadcast(postMeetingValue(t, confirmBroadcast(testConfHost+"/acme/BraveTiger", started)))
+	if meetingURL != testConfHost+"/acme/BraveTiger" || !got.Equal(started) {
+		t.Errorf("confirmedBroadcast = %s, %v, want the url started at %v", meetingURL, got, started)
+	}
+
+	// Buttons made before the start time was carried start now.
+	before := time.Now()
+	meetingURL, got = confirmedBroadcast(testConfHost + "/acme/BraveTiger")
+	if meetingURL != testConfHost+"/acme/BraveTiger" || got.Before(before) {
+		t.Errorf("confirmedBroadcast = %s, %v, want the url started now", meetingURL, got)
+	}
+}
+
+// postMeetingValue returns the value of the post meeting button of a prompt.
+func postMeetingValue(t *testing.T, result CommandResult) string {
+	t.Helper()
+	var prompt struct {
+		Blocks []struct {
+			Elements []struct {
+				Value string `json:"value"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &prompt); err != nil || len(prompt.Blocks) != 2 || len(prompt.Blocks[1].Elements) != 1 {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	return prompt.Blocks[1].Elements[0].Value
+}
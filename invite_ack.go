@@ -0,0 +1,164 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	actionAcknowledgeInvite = "acknowledge_invite"
+
+	inviteAckAcceptedTemplate = `{"response_type":"ephemeral","replace_original":false,"text":%s}`
+	inviteAckAcceptedMsg      = "Thanks, <@%s> knows you'll join."
+	inviteAckSummaryMsg       = "These people said they'll join %s: %s."
+
+	// inviteAckRetention is how long acknowledgments of a meeting are
+	// kept, after which acknowledging starts a new summary.
+	inviteAckRetention = 24 * time.Hour
+)
+
+type inviteAckSummary struct {
+	channel string
+	ts      string
+	users   []string
+	created time.Time
+	// posting is closed once the acknowledgment posting the summary is
+	// done, it's nil while nobody is posting.
+	posting chan struct{}
+}
+
+// InviteAcks tracks which invitees said they'll join each meeting so the
+// host can be sent a summary that's updated as invitees acknowledge. They're
+// kept in memory for a day.
+type InviteAcks struct {
+	mu       sync.Mutex
+	meetings map[string]*inviteAckSummary
+}
+
+// acknowledge records that a user will join a host's meeting and returns
+// everyone who has, with the coordinates of the host's summary message if
+// one has been posted. Otherwise post is true and the caller must post the
+// summary and report it with posted. Acknowledgments while a summary is
+// being posted wait for it so only one summary is posted.
+func (a *InviteAcks) acknowledge(ctx context.Context, teamID, hostID, room, userID string) (users []string, channel, ts string, post bool, err error) {
+	a.mu.Lock()
+	now := time.Now()
+	if a.meetings == nil {
+		a.meetings = map[string]*inviteAckSummary{}
+	}
+	for key, summary := range a.meetings {
+		if now.Sub(summary.created) > inviteAckRetention {
+			delete(a.meetings, key)
+		}
+	}
+	key := strings.Join([]string{teamID, hostID, room}, "/")
+	summary, ok := a.meetings[key]
+	if !ok {
+		summary = &inviteAckSummary{created: now}
+		a.meetings[key] = summary
+	}
+	acknowledged := false
+	for _, user := range summary.users {
+		acknowledged = acknowledged || user == userID
+	}
+	if !acknowledged {
+		summary.users = append(summary.users, userID)
+	}
+	for summary.posting != nil {
+		posting := summary.posting
+		a.mu.Unlock()
+		select {
+		case <-posting:
+		case <-ctx.Done():
+			return nil, "", "", false, ctx.Err()
+		}
+		a.mu.Lock()
+	}
+	if summary.ts == "" {
+		summary.posting = make(chan struct{})
+		post = true
+	}
+	users, channel, ts = append([]string(nil), summary.users...), summary.channel, summary.ts
+	a.mu.Unlock()
+	return users, channel, ts, post, nil
+}
+
+// posted records the coordinates of the summary message posted for a
+// host's meeting so it's updated rather than posted again, and releases
+// acknowledgments waiting for it. An empty ts means posting failed so the
+// next acknowledgment posts instead.
+func (a *InviteAcks) posted(teamID, hostID, room, channel, ts string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := strings.Join([]string{teamID, hostID, room}, "/")
+	summary, ok := a.meetings[key]
+	if !ok {
+		return
+	}
+	if summary.ts == "" {
+		summary.channel, summary.ts = channel, ts
+	}
+	if summary.posting != nil {
+		close(summary.posting)
+		summary.posting = nil
+	}
+}
+
+func inviteAckValue(hostID, room string) string {
+	return hostID + " " + room
+}
+
+// acknowledgeInvite records that an invitee will join a meeting, sends or
+// updates the host's summary of who will, and lets the invitee know the
+// host was told.
+func (s *SlashCommandHandlers) acknowledgeInvite(ctx context.Context, payload interactionPayload, value string) error {
+	log := zerolog.Ctx(ctx)
+	fields := strings.Fields(value)
+	if s.InviteAcks == nil || len(fields) != 2 {
+		return nil
+	}
+	hostID, room := fields[0], fields[1]
+	token, err := s.botToken(ctx, payload.Team.ID)
+	if err != nil {
+		return err
+	}
+
+	users, channel, ts, post, err := s.InviteAcks.acknowledge(ctx, payload.Team.ID, hostID, room, payload.User.ID)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf(inviteAckSummaryMsg, room, mentions(users))
+	if !post {
+		_, err = s.callSlackAPIWith(ctx, s.SlackRetry, token, "chat.update", map[string]interface{}{
+			"channel": channel,
+			"ts":      ts,
+			"text":    text,
+		})
+	} else {
+		// The summary is a DM from the app to the host.
+		var posted slackAPIResponse
+		posted, err = s.callSlackAPIWith(ctx, s.SlackRetry, token, "chat.postMessage", map[string]interface{}{
+			"channel": hostID,
+			"text":    text,
+		})
+		if err != nil {
+			posted = slackAPIResponse{}
+		}
+		s.InviteAcks.posted(payload.Team.ID, hostID, room, posted.Channel, posted.TS)
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("updating invite acknowledgment summary")
+		return err
+	}
+
+	accepted, _ := json.Marshal(fmt.Sprintf(inviteAckAcceptedMsg, hostID))
+	return s.respond(payload.ResponseURL, fmt.Sprintf(inviteAckAcceptedTemplate, accepted))
+}
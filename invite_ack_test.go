@@ -0,0 +1,88 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func ackPayload(userID string) interactionPayload {
+	return interactionPayload{
+		ResponseURL: "https://hooks.slack.com/actions/T1/" + userID,
+		User:        interactionID{ID: userID},
+		Team:        interactionID{ID: testTeamID},
+	}
+}
+
+func TestConcurrentAcknowledgmentsPostOneSummary(t *testing.T) {
+	slack := newFakeSlack(t)
+	// A slow post leaves time for the other acknowledgments to arrive.
+	slack.Delay("chat.postMessage", 50*time.Millisecond)
+	s := newTestHandlers(t, slack)
+	s.InviteAcks = &InviteAcks{}
+
+	var users []string
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		userID := fmt.Sprintf("U%d", i)
+		users = append(users, userID)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.acknowledgeInvite(context.Background(), ackPayload(userID), inviteAckValue("UHOST", "Room"))
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	posts := slack.Calls("chat.postMessage")
+	if len(posts) != 1 {
+		t.Fatalf("posted %d summaries, want 1", len(posts))
+	}
+	if got := posts[0].Body["channel"]; got != "UHOST" {
+		t.Errorf("summary posted to %v, want the host", got)
+	}
+	updates := slack.Calls("chat.update")
+	if len(updates) != len(users)-1 {
+		t.Fatalf("updated the summary %d times, want %d", len(updates), len(users)-1)
+	}
+	// Acknowledgments that waited on the post update it with everyone, so
+	// the largest update names every user.
+	longest := ""
+	for _, update := range updates {
+		if text, _ := update.Body["text"].(string); len(text) > len(longest) {
+			longest = text
+		}
+	}
+	for _, user := range users {
+		if !strings.Contains(longest, "<@"+user+">") {
+			t.Errorf("summary %q doesn't name %s", longest, user)
+		}
+	}
+}
+
+func TestAcknowledgmentPostsAgainAfterFailure(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("chat.postMessage", `{"ok":false,"error":"channel_not_found"}`)
+	s := newTestHandlers(t, slack)
+	s.InviteAcks = &InviteAcks{}
+
+	if err := s.acknowledgeInvite(context.Background(), ackPayload("U1"), inviteAckValue("UHOST", "Room")); err == nil {
+		t.Fatal("acknowledgment succeeded, want the post error")
+	}
+	slack.Handle("chat.postMessage", `{"ok":true,"channel":"DHOST","ts":"1.1"}`)
+	if err := s.acknowledgeInvite(context.Background(), ackPayload("U2"), inviteAckValue("UHOST", "Room")); err != nil {
+		t.Fatal(err)
+	}
+	if posts := slack.Calls("chat.postMessage"); len(posts) != 2 {
+		t.Errorf("posted %d times, want a second post after the failure", len(posts))
+	}
+	if updates := slack.Calls("chat.update"); len(updates) != 0 {
+		t.Errorf("updated %d times, want none", len(updates))
+	}
+}
@@ -0,0 +1,112 @@
+package jitsi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const inviteEndedMsg = "This meeting has ended, its link has expired."
+
+type postedInvite struct {
+	teamID  string
+	channel string
+	ts      string
+	expires time.Time
+}
+
+// EndedInvites tracks the invites posted as DMs until their links expire so
+// they can be updated to say the meeting ended rather than keep a join
+// button for a dead link. They're kept in memory, so invites posted before
+// a restart are left as posted.
+type EndedInvites struct {
+	mu      sync.Mutex
+	invites []postedInvite
+}
+
+func (e *EndedInvites) record(invite postedInvite) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.invites = append(e.invites, invite)
+}
+
+// expired removes and returns the invites whose links expired by now.
+func (e *EndedInvites) expired(now time.Time) []postedInvite {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var expired []postedInvite
+	kept := e.invites[:0]
+	for _, invite := range e.invites {
+		if now.Before(invite.expires) {
+			kept = append(kept, invite)
+		} else {
+			expired = append(expired, invite)
+		}
+	}
+	e.invites = kept
+	return expired
+}
+
+// recordInvite tracks a posted invite when EndedInvites is set and its
+// link's expiry is known, i.e. from a team's meeting duration or a token
+// generator that knows its tokens' expiry.
+func (s *SlashCommandHandlers) recordInvite(teamID, channel, ts string, lifetime time.Duration) {
+	if s.EndedInvites == nil || ts == "" {
+		return
+	}
+	now := time.Now()
+	expires := now.Add(lifetime)
+	if lifetime <= 0 {
+		expirer, ok := s.TokenGenerator.(tokenExpirer)
+		if !ok {
+			return
+		}
+		expires = expirer.Expiry(now)
+	}
+	s.EndedInvites.record(postedInvite{teamID: teamID, channel: channel, ts: ts, expires: expires})
+}
+
+// MarkEndedInvites updates invites whose links expired every interval until
+// stop is closed.
+func (s *SlashCommandHandlers) MarkEndedInvites(log zerolog.Logger, interval time.Duration, stop <-chan struct{}) {
+	ctx := log.WithContext(context.Background())
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+		s.markEndedInvites(ctx, time.Now())
+	}
+}
+
+// markEndedInvites replaces the invites whose links expired by now with a
+// message saying the meeting ended. Invites that can't be updated, i.e.
+// since the team uninstalled the app, are dropped.
+func (s *SlashCommandHandlers) markEndedInvites(ctx context.Context, now time.Time) {
+	log := zerolog.Ctx(ctx)
+	for _, invite := range s.EndedInvites.expired(now) {
+		token, err := s.botToken(ctx, invite.teamID)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("team_id", invite.teamID).
+				Msg("retrieving token")
+			continue
+		}
+		_, err = s.callSlackAPIWith(ctx, s.SlackRetry, token, "chat.update", map[string]interface{}{
+			"channel":     invite.channel,
+			"ts":          invite.ts,
+			"text":        inviteEndedMsg,
+			"attachments": []interface{}{},
+		})
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("channel", invite.channel).
+				Msg("marking invite ended")
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package jitsi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEndedInvitesMarkedOnceExpired(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.EndedInvites = &EndedInvites{}
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{MeetingDuration: 30 * time.Minute})
+	s.ServerConfigs = configs
+
+	processCommand(t, s, "<@UBOB>")
+	ctx := context.Background()
+	s.markEndedInvites(ctx, time.Now().Add(20*time.Minute))
+	if calls := slack.Calls("chat.update"); len(calls) != 0 {
+		t.Fatalf("chat.update calls = %d before the link expired, want 0", len(calls))
+	}
+
+	s.markEndedInvites(ctx, time.Now().Add(31*time.Minute))
+	calls := slack.Calls("chat.update")
+	if len(calls) != 1 {
+		t.Fatalf("chat.update calls = %d, want 1", len(calls))
+	}
+	body := calls[0].Body
+	if body["channel"] != "DUBOB" || body["ts"] != "1500000000.000100" || body["text"] != inviteEndedMsg {
+		t.Errorf("chat.update = %v, want the invite to DUBOB marked ended", body)
+	}
+	if attachments, ok := body["attachments"].([]interface{}); !ok || len(attachments) != 0 {
+		t.Errorf("attachments = %v, want the join button removed", body["attachments"])
+	}
+
+	s.markEndedInvites(ctx, time.Now().Add(time.Hour))
+	if calls := slack.Calls("chat.update"); len(calls) != 1 {
+		t.Errorf("chat.update calls = %d, want an invite marked ended once", len(calls))
+	}
+}
+
+func TestEndedInvitesUseTokenExpiry(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.EndedInvites = &EndedInvites{}
+
+	processCommand(t, s, "<@UBOB>")
+	s.markEndedInvites(context.Background(), time.Now().Add(61*time.Minute))
+	if calls := slack.Calls("chat.update"); len(calls) != 1 {
+		t.Errorf("chat.update calls = %d, want the invite marked ended after the token lifetime", len(calls))
+	}
+}
+
+func TestEphemeralInvitesNotTracked(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.EndedInvites = &EndedInvites{}
+	s.EphemeralInvites = true
+
+	processCommand(t, s, "<@UBOB>")
+	if expired := s.EndedInvites.expired(time.Now().Add(24 * time.Hour)); len(expired) != 0 {
+		t.Errorf("tracked %+v, want ephemeral invites left alone", expired)
+	}
+}
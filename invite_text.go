@@ -0,0 +1,65 @@
+package jitsi
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const defaultInviteText = "{{.Host}} would like you to join a meeting."
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// inviteTextData is the data an invite text template is rendered with.
+// Every field is escaped for Slack before rendering.
+type inviteTextData struct {
+	// Host mentions the user who sent the invite.
+	Host string
+	// Server is the conference host url.
+	Server string
+	// Room is the name of the conference room.
+	Room string
+}
+
+func (s *SlashCommandHandlers) inviteTextTemplate() string {
+	if s.InviteText == "" {
+		return defaultInviteText
+	}
+	return s.InviteText
+}
+
+// inviteText renders the text of an invite message.
+func (s *SlashCommandHandlers) inviteText(hostID, confHost, room string) (string, error) {
+	return renderText(s.inviteTextTemplate(), hostID, confHost, room)
+}
+
+// renderText renders a message text template with the escaped host
+// mention, conference host url and room.
+func renderText(textTemplate, hostID, confHost, room string) (string, error) {
+	tmpl, err := template.New("text").Option("missingkey=error").Parse(textTemplate)
+	if err != nil {
+		return "", err
+	}
+	var text bytes.Buffer
+	err = tmpl.Execute(&text, inviteTextData{
+		Host:   fmt.Sprintf("<@%s>", slackEscaper.Replace(hostID)),
+		Server: slackEscaper.Replace(confHost),
+		Room:   slackEscaper.Replace(room),
+	})
+	if err != nil {
+		return "", err
+	}
+	// Templates are rendered with user supplied text, so the result is
+	// kept within what Slack accepts.
+	return truncate(text.String(), maxTextLength), nil
+}
+
+// validateInviteText checks that the invite text template renders.
+func (s *SlashCommandHandlers) validateInviteText() error {
+	_, err := s.inviteText("U0000000", "https://meet.example.com", "ExampleRoom")
+	if err != nil {
+		return fmt.Errorf("invalid invite text: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// inviteTitle returns the title of a posted invite.
+func inviteTitle(t *testing.T, call slackCall) string {
+	t.Helper()
+	var invite []struct {
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal([]byte(call.Form.Get("attachments")), &invite); err != nil || len(invite) == 0 {
+		t.Fatalf("decoding invite %s: %v", call.Form.Get("attachments"), err)
+	}
+	return invite[0].Title
+}
+
+func TestInviteText(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		groupLimit int
+		want       string
+	}{
+		{"default", "", 0, "<@UHOST> would like you to join a meeting."},
+		{"custom", "{{.Host}} invites you to {{.Room}} on {{.Server}}", 0, "<@UHOST> invites you to {{room}} on " + testConfHost},
+		{"custom group", "{{.Host}} invites you to {{.Room}}", 2, "<@UHOST> invites you to {{room}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			slack.AddUser("UALICE", "alice")
+			s := newTestHandlers(t, slack)
+			s.InviteText = tt.text
+			s.GroupInviteLimit = tt.groupLimit
+
+			result := processCommand(t, s, "<@UBOB> <@UALICE>")
+			want := strings.Replace(tt.want, "{{room}}", result.Room, 1)
+			for _, call := range slack.Calls("chat.postMessage") {
+				if got := inviteTitle(t, call); got != want {
+					t.Errorf("invite title = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTextEscapes(t *testing.T) {
+	got, err := renderText("{{.Host}} {{.Server}} {{.Room}}", "U1>", "https://meet.example.com/?a=1&b=2", "<!channel>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<@U1&gt;> https://meet.example.com/?a=1&amp;b=2 &lt;!channel&gt;"; got != want {
+		t.Errorf("renderText = %q, want %q", got, want)
+	}
+}
+
+func TestRenderTextTruncates(t *testing.T) {
+	got, err := renderText(strings.Repeat("x", maxTextLength+10), "U1", testConfHost, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := utf8.RuneCountInString(got); n > maxTextLength {
+		t.Errorf("rendered %d characters, want at most %d", n, maxTextLength)
+	}
+}
@@ -0,0 +1,55 @@
+package jitsi
+
+import (
+	"fmt"
+	"time"
+)
+
+const linkExpiryMsg = "This link expires in %s, <!date^%d^{date_short_pretty} at {time}|%s>."
+
+// tokenExpirer is implemented by token generators that know when the
+// tokens they create expire.
+type tokenExpirer interface {
+	Expiry(now time.Time) time.Time
+}
+
+// linkExpiry describes when a link with a token created at now expires,
+// with a Slack date token so each viewer sees the time in their own
+// timezone. Links are valid for lifetime when it's set, i.e. with a team's
+// meeting duration, and always described. Otherwise it's empty unless
+// ShowLinkExpiry is set and the token generator knows its tokens' expiry.
+func (s *SlashCommandHandlers) linkExpiry(now time.Time, lifetime time.Duration) string {
+	expiry := now.Add(lifetime)
+	if lifetime <= 0 {
+		expirer, ok := s.TokenGenerator.(tokenExpirer)
+		if !s.ShowLinkExpiry || !ok {
+			return ""
+		}
+		expiry = expirer.Expiry(now)
+	}
+	return fmt.Sprintf(
+		linkExpiryMsg,
+		expiresIn(expiry.Sub(now)),
+		expiry.Unix(),
+		expiry.UTC().Format("Jan 2 at 3:04 PM UTC"),
+	)
+}
+
+// expiresIn roughly describes a duration in its largest sensible unit.
+func expiresIn(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return plural(int(d.Round(time.Minute)/time.Minute), "min")
+	case d < 48*time.Hour:
+		return plural(int(d.Round(time.Hour)/time.Hour), "hour")
+	default:
+		return plural(int(d.Round(24*time.Hour)/(24*time.Hour)), "day")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 || unit == "min" {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,121 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inviteText returns the text of the invite attachment posted by call.
+func inviteText(t *testing.T, call slackCall) string {
+	t.Helper()
+	var invite []struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(call.Form.Get("attachments")), &invite); err != nil || len(invite) == 0 {
+		t.Fatalf("decoding invite %s: %v", call.Form.Get("attachments"), err)
+	}
+	return invite[0].Text
+}
+
+func TestLinkExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		show     bool
+		lifetime time.Duration
+		want     string
+	}{
+		{"off", false, 0, ""},
+		{"token lifetime", true, 0, "This link expires in 1 hour, <!date^1700003600^{date_short_pretty} at {time}|Nov 14 at 11:13 PM UTC>."},
+		{"team lifetime", true, 45 * time.Minute, "This link expires in 45 min, <!date^1700002700^{date_short_pretty} at {time}|Nov 14 at 10:58 PM UTC>."},
+		{"team lifetime when off", false, 45 * time.Minute, "This link expires in 45 min, <!date^1700002700^{date_short_pretty} at {time}|Nov 14 at 10:58 PM UTC>."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHandlers(t, newFakeSlack(t))
+			s.ShowLinkExpiry = tt.show
+			if got := s.linkExpiry(now, tt.lifetime); got != tt.want {
+				t.Errorf("linkExpiry = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkExpiryNeedsKnownExpiry(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ShowLinkExpiry = true
+	s.TokenGenerator = &recordingTokenGenerator{}
+	if got := s.linkExpiry(time.Now(), 0); got != "" {
+		t.Errorf("linkExpiry = %q, want nothing from a generator without an expiry", got)
+	}
+}
+
+func TestExpiresIn(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Minute, "1 min"},
+		{45 * time.Minute, "45 min"},
+		{59*time.Minute + 20*time.Second, "59 min"},
+		{time.Hour, "1 hour"},
+		{90 * time.Minute, "2 hours"},
+		{8 * time.Hour, "8 hours"},
+		{47 * time.Hour, "47 hours"},
+		{72 * time.Hour, "3 days"},
+	}
+	for _, tt := range tests {
+		if got := expiresIn(tt.d); got != tt.want {
+			t.Errorf("expiresIn(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestLinksShowExpiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		want     string
+	}{
+		{"token lifetime", 0, "This link expires in 1 hour, "},
+		{"meeting duration", 45 * time.Minute, "This link expires in 45 min, "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			configs := &MemoryServerConfigStore{}
+			configs.StoreServerConfig(testTeamID, ServerConfig{MeetingDuration: tt.duration})
+			s := newTestHandlers(t, slack)
+			s.ServerConfigs = configs
+			s.ShowLinkExpiry = true
+
+			result := processCommand(t, s, "<@UBOB>")
+			if !strings.Contains(result.Body, tt.want) {
+				t.Errorf("reply = %s, want the host's link to say %q", result.Body, tt.want)
+			}
+			posted := slack.Calls("chat.postMessage")
+			if len(posted) != 1 {
+				t.Fatalf("chat.postMessage calls = %d, want an invite", len(posted))
+			}
+			if got := inviteText(t, posted[0]); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("invite text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinksHideExpiryByDefault(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+
+	if result := processCommand(t, s, "<@UBOB>"); strings.Contains(result.Body, "expires in") {
+		t.Errorf("reply = %s, want no expiry", result.Body)
+	}
+	if got := inviteText(t, slack.Calls("chat.postMessage")[0]); got != "" {
+		t.Errorf("invite text = %q, want no expiry", got)
+	}
+}
@@ -0,0 +1,49 @@
+package jitsi
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	slackTokenRE  = regexp.MustCompile(`xox[a-zA-Z.]*-[0-9A-Za-z-]+`)
+	secretParamRE = regexp.MustCompile(`((?:code|client_secret|refresh_token|token|jwt)=)[^&"\s\\]+`)
+)
+
+// RedactingWriter scrubs Slack tokens, sensitive query parameters and any
+// configured secret values from log lines before writing them to W.
+type RedactingWriter struct {
+	W       io.Writer
+	Secrets []string
+}
+
+// NewRedactingWriter creates a RedactingWriter for w that also scrubs the
+// provided secrets. Empty secrets are ignored.
+func NewRedactingWriter(w io.Writer, secrets ...string) *RedactingWriter {
+	rw := &RedactingWriter{W: w}
+	for _, secret := range secrets {
+		if secret != "" {
+			rw.Secrets = append(rw.Secrets, secret)
+		}
+	}
+	return rw
+}
+
+// Write redacts p and writes it to the underlying writer. The length of p is
+// returned on success so callers aren't confused by the redacted length.
+func (rw *RedactingWriter) Write(p []byte) (int, error) {
+	out := slackTokenRE.ReplaceAll(p, []byte(redacted))
+	out = secretParamRE.ReplaceAll(out, []byte("${1}"+redacted))
+	for _, secret := range rw.Secrets {
+		out = bytes.Replace(out, []byte(secret), []byte(redacted), -1)
+	}
+
+	_, err := rw.W.Write(out)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,38 @@
+package jitsi
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	// flagMaxOccupants limits how many participants can join a meeting,
+	// i.e. '--max-occupants=10'.
+	flagMaxOccupants = "--max-occupants"
+	// maxOccupantsLimit is the largest max participant count accepted.
+	maxOccupantsLimit = 1000
+)
+
+// validateMaxOccupants checks a max participant count. Zero leaves the
+// count to the conference service.
+func validateMaxOccupants(n int) error {
+	if n < 0 || n > maxOccupantsLimit {
+		return fmt.Errorf("max occupants must be between 1 and %d", maxOccupantsLimit)
+	}
+	return nil
+}
+
+// maxOccupants returns the max participant count for a meeting, which is
+// the --max-occupants option of the command if given, otherwise
+// MaxOccupants.
+func (s *SlashCommandHandlers) maxOccupants(cmd Command) (int, error) {
+	value, ok := cmd.Options[flagMaxOccupants]
+	if !ok {
+		return s.MaxOccupants, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n == 0 || validateMaxOccupants(n) != nil {
+		return 0, fmt.Errorf("%s must be a whole number between 1 and %d", flagMaxOccupants, maxOccupantsLimit)
+	}
+	return n, nil
+}
@@ -0,0 +1,87 @@
+package jitsi
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMaxOccupantsClaim(t *testing.T) {
+	tests := []struct {
+		max   int
+		valid bool
+	}{
+		{0, true},
+		{1, true},
+		{10, true},
+		{maxOccupantsLimit, true},
+		{-1, false},
+		{maxOccupantsLimit + 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.max), func(t *testing.T) {
+			g := testTokenGenerator(t)
+			token, err := g.CreateJWT(JWTInput{TenantName: "acme", RoomClaim: "BraveTiger", UserID: "UHOST", MaxOccupants: tt.max})
+			if !tt.valid {
+				if err == nil {
+					t.Errorf("CreateJWT = %s, want max occupants %d refused", token, tt.max)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims := tokenClaims(t, testConfHost+"/acme/BraveTiger?jwt="+token)
+			if got := contextOf(t, claims).MaxOccupants; got != tt.max {
+				t.Errorf("maxOccupants claim = %d, want %d", got, tt.max)
+			}
+		})
+	}
+}
+
+func TestMaxOccupantsOption(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator int
+		text     string
+		want     int
+	}{
+		{"none", 0, "<@UBOB>", 0},
+		{"operator default", 20, "<@UBOB>", 20},
+		{"option", 0, "<@UBOB> --max-occupants=5", 5},
+		{"option over default", 20, "<@UBOB> --max-occupants=5", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.MaxOccupants = tt.operator
+
+			result := processCommand(t, s, tt.text)
+			if got := contextOf(t, tokenClaims(t, hostURL(t, result))).MaxOccupants; got != tt.want {
+				t.Errorf("host maxOccupants claim = %d, want %d", got, tt.want)
+			}
+			invite := inviteURL(t, slack.Calls("chat.postMessage")[0])
+			if got := contextOf(t, tokenClaims(t, invite)).MaxOccupants; got != tt.want {
+				t.Errorf("invite maxOccupants claim = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxOccupantsOptionBounds(t *testing.T) {
+	want := fmt.Sprintf("%s must be a whole number between 1 and %d", flagMaxOccupants, maxOccupantsLimit)
+	for _, value := range []string{"0", "-3", "1001", "ten", "", "2.5"} {
+		slack := newFakeSlack(t)
+		slack.AddUser("UBOB", "bob")
+		s := newTestHandlers(t, slack)
+
+		result := processCommand(t, s, "<@UBOB> --max-occupants="+value)
+		if _, got := responseOf(t, result); got != want {
+			t.Errorf("--max-occupants=%s = %q, want %q", value, got, want)
+		}
+		if posted := slack.Calls("chat.postMessage"); len(posted) != 0 {
+			t.Errorf("--max-occupants=%s sent %d invites, want none", value, len(posted))
+		}
+	}
+}
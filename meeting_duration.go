@@ -0,0 +1,94 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const (
+	minMeetingDuration = 5 * time.Minute
+	maxMeetingDuration = 8 * time.Hour
+
+	setDurationUnsupportedMsg = "Meeting durations are not supported by this installation."
+	setDurationAdminMsg       = "Only workspace admins can change the meeting duration."
+	setDurationUsageMsg       = "Please provide a duration from 5m to 8h i.e. '%s set-duration 45m'."
+	setDurationUnsetMsg       = "No meeting duration is set, links are valid for the installation default."
+	setDurationMsg            = "Meeting links are valid for %s."
+)
+
+// parseMeetingDuration parses a meeting duration such as 45m or 1h30m
+// within the allowed bounds.
+func parseMeetingDuration(text string) (time.Duration, bool) {
+	d, err := time.ParseDuration(strings.TrimSpace(text))
+	if err != nil || d < minMeetingDuration || d > maxMeetingDuration {
+		return 0, false
+	}
+	return d, true
+}
+
+// durationText formats a meeting duration as it's given, i.e. 1h30m.
+func durationText(d time.Duration) string {
+	text := strings.TrimSuffix(d.String(), "0s")
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// setDuration shows a team's meeting duration, or updates it for workspace
+// admins when a duration is given.
+func (s *SlashCommandHandlers) setDuration(ctx context.Context, client *slack.Client, teamID, userID, text string) (CommandResult, error) {
+	if s.ServerConfigs == nil {
+		return ephemeral(setDurationUnsupportedMsg), nil
+	}
+	log := zerolog.Ctx(ctx)
+
+	cfg, err := s.serverConfig(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving server config")
+		return CommandResult{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		if cfg.MeetingDuration == 0 {
+			return ephemeral(setDurationUnsetMsg), nil
+		}
+		return ephemeral(fmt.Sprintf(setDurationMsg, durationText(cfg.MeetingDuration))), nil
+	}
+
+	duration, ok := parseMeetingDuration(text)
+	if !ok {
+		return ephemeral(fmt.Sprintf(setDurationUsageMsg, s.commandName())), nil
+	}
+	user, err := s.fetchUserInfo(ctx, client, userID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving user info from slack")
+			return CommandResult{}, err
+		}
+	}
+	if !user.IsAdmin && !user.IsOwner {
+		return ephemeral(setDurationAdminMsg), nil
+	}
+
+	cfg.MeetingDuration = duration
+	err = s.ServerConfigs.StoreServerConfig(teamID, cfg)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("storing server config")
+		return CommandResult{}, err
+	}
+	return ephemeral(fmt.Sprintf(setDurationMsg, durationText(duration))), nil
+}
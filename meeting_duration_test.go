@@ -0,0 +1,127 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+const adminUserInfo = `{"ok":true,"user":{"id":"UHOST","name":"host","is_admin":true,"profile":{"display_name":"host"}}}`
+
+// tokenExpiry returns the exp claim of the token in a meeting url.
+func tokenExpiry(t *testing.T, meetingURL string) time.Time {
+	t.Helper()
+	u, err := url.Parse(meetingURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(u.Query().Get("jwt"), claims); err != nil {
+		t.Fatalf("parsing token of %s: %v", meetingURL, err)
+	}
+	return time.Unix(int64(claims["exp"].(float64)), 0)
+}
+
+func TestSetDuration(t *testing.T) {
+	tests := []struct {
+		name  string
+		admin bool
+		text  string
+		want  string
+		saved time.Duration
+	}{
+		{"unset", true, "", setDurationUnsetMsg, 0},
+		{"admin", true, "45m", fmt.Sprintf(setDurationMsg, "45m"), 45 * time.Minute},
+		{"hours", true, "1h30m", fmt.Sprintf(setDurationMsg, "1h30m"), 90 * time.Minute},
+		{"too short", true, "4m", fmt.Sprintf(setDurationUsageMsg, "/jitsi"), 0},
+		{"too long", true, "9h", fmt.Sprintf(setDurationUsageMsg, "/jitsi"), 0},
+		{"not a duration", true, "soon", fmt.Sprintf(setDurationUsageMsg, "/jitsi"), 0},
+		{"not admin", false, "45m", setDurationAdminMsg, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			if tt.admin {
+				slack.Handle("users.info", adminUserInfo)
+			}
+			configs := &MemoryServerConfigStore{}
+			s := newTestHandlers(t, slack)
+			s.ServerConfigs = configs
+
+			_, got := responseOf(t, processCommand(t, s, strings.TrimSpace("set-duration "+tt.text)))
+			if got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+			cfg, _ := configs.GetServerConfig(testTeamID)
+			if cfg.MeetingDuration != tt.saved {
+				t.Errorf("stored duration = %v, want %v", cfg.MeetingDuration, tt.saved)
+			}
+		})
+	}
+}
+
+func TestSetDurationShowsStoredDuration(t *testing.T) {
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{MeetingDuration: 2 * time.Hour})
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerConfigs = configs
+
+	_, got := responseOf(t, processCommand(t, s, "set-duration"))
+	if want := fmt.Sprintf(setDurationMsg, "2h"); got != want {
+		t.Errorf("reply = %q, want %q", got, want)
+	}
+}
+
+func TestMeetingDurationSetsLinkExpiry(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	configs := &MemoryServerConfigStore{}
+	configs.StoreServerConfig(testTeamID, ServerConfig{MeetingDuration: 45 * time.Minute})
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = configs
+
+	result := processCommand(t, s, "<@UBOB>")
+	var confirmation struct {
+		Text        string `json:"text"`
+		Attachments []struct {
+			Actions []struct {
+				URL string `json:"url"`
+			} `json:"actions"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &confirmation); err != nil {
+		t.Fatal(err)
+	}
+	hostExpiry := tokenExpiry(t, confirmation.Attachments[0].Actions[0].URL)
+	if d := time.Until(hostExpiry); d < 44*time.Minute || d > 45*time.Minute {
+		t.Errorf("host link expires in %v, want 45m", d)
+	}
+	if !strings.Contains(confirmation.Text, "expires in 45 min") {
+		t.Errorf("confirmation %q doesn't show the expiry", confirmation.Text)
+	}
+
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want 1", len(posted))
+	}
+	var invite []struct {
+		Text    string `json:"text"`
+		Actions []struct {
+			URL string `json:"url"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(posted[0].Form.Get("attachments")), &invite); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(tokenExpiry(t, invite[0].Actions[0].URL)); d < 44*time.Minute || d > 45*time.Minute {
+		t.Errorf("invite link expires in %v, want 45m", d)
+	}
+	if !strings.Contains(invite[0].Text, "expires in 45 min") {
+		t.Errorf("invite %q doesn't show the expiry", invite[0].Text)
+	}
+}
@@ -0,0 +1,171 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// KeyTemplate is the dynamo key for storing the team id and name of a
+	// meeting template. This is the primary.
+	KeyTemplate = "template"
+	// KeyTemplateText is the dynamo key for storing the command text a
+	// meeting template runs.
+	KeyTemplateText = "text"
+
+	templateSave = "save"
+	templateRun  = "run"
+
+	templateUnsupportedMsg = "Meeting templates are not supported by this installation."
+	templateUsageMsg       = "Save a template with '%[1]s template save <name> @bob @alice' and start it with '%[1]s template run <name>'."
+	templateNameMsg        = "Template names can only use lowercase letters, numbers, - and _, and can be up to 32 characters long."
+	templateInviteesMsg    = "A template needs users to invite, i.e. '%s template save %s @bob @alice'."
+	templateSavedMsg       = "Saved the %s template. Start it with '%s template run %s'."
+	templateMissingMsg     = "There's no template named %s. Save one with '%s template save %s @bob @alice'."
+)
+
+var templateNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// TemplateStore provides an interface for reading and writing the meeting
+// templates of a team. A template is the command text it runs, such as
+// "lobby @bob @alice". An empty text is returned for templates that don't
+// exist.
+type TemplateStore interface {
+	GetTemplate(teamID, name string) (string, error)
+	StoreTemplate(teamID, name, text string) error
+}
+
+func templateKey(teamID, name string) string {
+	return teamID + "/" + name
+}
+
+// MemoryTemplateStore stores meeting templates in memory. They're lost on
+// restart.
+type MemoryTemplateStore struct {
+	mu        sync.RWMutex
+	templates map[string]string
+}
+
+// GetTemplate retrieves the text stored for a template.
+func (m *MemoryTemplateStore) GetTemplate(teamID, name string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.templates[templateKey(teamID, name)], nil
+}
+
+// StoreTemplate stores the text for a template.
+func (m *MemoryTemplateStore) StoreTemplate(teamID, name, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.templates == nil {
+		m.templates = map[string]string{}
+	}
+	m.templates[templateKey(teamID, name)] = text
+	return nil
+}
+
+// DynamoTemplateStore stores and retrieves meeting templates from aws
+// dynamodb.
+type DynamoTemplateStore struct {
+	TableName string
+	DB        *dynamodb.DynamoDB
+}
+
+// GetTemplate retrieves the text stored for a template.
+func (d *DynamoTemplateStore) GetTemplate(teamID, name string) (string, error) {
+	result, err := d.DB.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			KeyTemplate: {
+				S: aws.String(templateKey(teamID, name)),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	text, ok := result.Item[KeyTemplateText]
+	if !ok || text.S == nil {
+		return "", nil
+	}
+	return *text.S, nil
+}
+
+// StoreTemplate stores the text for a template.
+func (d *DynamoTemplateStore) StoreTemplate(teamID, name, text string) error {
+	_, err := d.DB.PutItem(&dynamodb.PutItemInput{
+		Item: map[string]*dynamodb.AttributeValue{
+			KeyTemplate: {
+				S: aws.String(templateKey(teamID, name)),
+			},
+			KeyTemplateText: {
+				S: aws.String(text),
+			},
+		},
+		TableName: aws.String(d.TableName),
+	})
+	return err
+}
+
+// template saves the invitees and options of a meeting under a name, or
+// starts a meeting from a saved template as if its text had been given to
+// the command.
+func (s *SlashCommandHandlers) template(ctx context.Context, in CommandInput, text string) (CommandResult, error) {
+	if s.Templates == nil {
+		return ephemeral(templateUnsupportedMsg), nil
+	}
+	log := zerolog.Ctx(ctx)
+	usage := ephemeral(fmt.Sprintf(templateUsageMsg, s.commandName()))
+
+	args := strings.Fields(text)
+	if len(args) < 2 {
+		return usage, nil
+	}
+	action, name := args[0], strings.ToLower(args[1])
+	if !templateNameRE.MatchString(name) {
+		return ephemeral(templateNameMsg), nil
+	}
+
+	switch action {
+	case templateSave:
+		saved := strings.Join(args[2:], " ")
+		cmd, err := ParseCommand(saved)
+		if err != nil {
+			return ephemeral(err.Error()), nil
+		}
+		// Templates only start meetings, so only the lobby subcommand
+		// can be saved.
+		if (cmd.Subcommand != "" && cmd.Subcommand != subcommandLobby) || len(cmd.Mentions) == 0 {
+			return ephemeral(fmt.Sprintf(templateInviteesMsg, s.commandName(), name)), nil
+		}
+		err = s.Templates.StoreTemplate(in.TeamID, name, saved)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("storing template")
+			return CommandResult{}, err
+		}
+		return ephemeral(fmt.Sprintf(templateSavedMsg, name, s.commandName(), name)), nil
+	case templateRun:
+		saved, err := s.Templates.GetTemplate(in.TeamID, name)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("retrieving template")
+			return CommandResult{}, err
+		}
+		if saved == "" {
+			return ephemeral(fmt.Sprintf(templateMissingMsg, name, s.commandName(), name)), nil
+		}
+		in.Text = saved
+		return s.ProcessCommand(ctx, in)
+	}
+	return usage, nil
+}
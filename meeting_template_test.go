@@ -0,0 +1,121 @@
+package jitsi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTemplateSaveRun(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.AddUser("UALICE", "alice")
+	templates := &MemoryTemplateStore{}
+	s := newTestHandlers(t, slack)
+	s.Templates = templates
+
+	if _, got := responseOf(t, processCommand(t, s, "template save Standup <@UBOB> <@UALICE>")); got != fmt.Sprintf(templateSavedMsg, "standup", "/jitsi", "standup") {
+		t.Fatalf("save = %q, want the template saved", got)
+	}
+	if calls := slack.Calls("chat.postMessage"); len(calls) != 0 {
+		t.Fatalf("chat.postMessage calls = %d, want no invites when saving", len(calls))
+	}
+	if saved, _ := templates.GetTemplate(testTeamID, "standup"); saved != "<@UBOB> <@UALICE>" {
+		t.Errorf("saved %q, want the invitees", saved)
+	}
+
+	result := processCommand(t, s, "template run standup")
+	if result.Room == "" {
+		t.Fatalf("run = %s, want a meeting", result.Body)
+	}
+	invited := map[string]bool{}
+	for _, call := range slack.Calls("chat.postMessage") {
+		invited[call.Form.Get("channel")] = true
+	}
+	if len(invited) != 2 || !invited["DUBOB"] || !invited["DUALICE"] {
+		t.Errorf("invites posted to %v, want the saved invitees", invited)
+	}
+}
+
+func TestTemplateRunsSavedOptions(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.Templates = &MemoryTemplateStore{}
+
+	processCommand(t, s, "template save review lobby <@UBOB>")
+	result := processCommand(t, s, "template run review")
+	// A lobby meeting makes the host a moderator.
+	if user := contextOf(t, tokenClaims(t, hostURL(t, result))).User; !user.Moderator {
+		t.Errorf("host = %+v, want a moderator for the saved lobby", user)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"usage", "template", fmt.Sprintf(templateUsageMsg, "/jitsi")},
+		{"no name", "template run", fmt.Sprintf(templateUsageMsg, "/jitsi")},
+		{"unknown action", "template delete standup", fmt.Sprintf(templateUsageMsg, "/jitsi")},
+		{"bad name", "template save stand.up <@UBOB>", templateNameMsg},
+		{"long name", "template save " + strings.Repeat("a", 33) + " <@UBOB>", templateNameMsg},
+		{"no invitees", "template save standup", fmt.Sprintf(templateInviteesMsg, "/jitsi", "standup")},
+		{"not a meeting", "template save standup guest <@UBOB>", fmt.Sprintf(templateInviteesMsg, "/jitsi", "standup")},
+		{"unknown flag", "template save standup <@UBOB> --typo", "--typo isn't a known option"},
+		{"missing", "template run retro", fmt.Sprintf(templateMissingMsg, "retro", "/jitsi", "retro")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			templates := &MemoryTemplateStore{}
+			s := newTestHandlers(t, slack)
+			s.Templates = templates
+
+			if _, got := responseOf(t, processCommand(t, s, tt.text)); got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+			if saved, _ := templates.GetTemplate(testTeamID, "standup"); saved != "" {
+				t.Errorf("saved %q, want nothing saved", saved)
+			}
+		})
+	}
+}
+
+func TestTemplatesPerTeam(t *testing.T) {
+	templates := &MemoryTemplateStore{}
+	templates.StoreTemplate("T2", "standup", "<@UBOB>")
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.Templates = templates
+
+	if _, got := responseOf(t, processCommand(t, s, "template run standup")); got != fmt.Sprintf(templateMissingMsg, "standup", "/jitsi", "standup") {
+		t.Errorf("run = %q, want another team's template missing", got)
+	}
+}
+
+func TestTemplateUnsupported(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	if _, got := responseOf(t, processCommand(t, s, "template run standup")); got != templateUnsupportedMsg {
+		t.Errorf("reply = %q, want %q", got, templateUnsupportedMsg)
+	}
+}
+
+func TestDynamoTemplateStore(t *testing.T) {
+	_, db := newFakeDynamo(t)
+	store := &DynamoTemplateStore{TableName: "templates", DB: db}
+	if got, err := store.GetTemplate(testTeamID, "standup"); got != "" || err != nil {
+		t.Fatalf("missing template = %q, %v, want none", got, err)
+	}
+	if err := store.StoreTemplate(testTeamID, "standup", "lobby <@UBOB>"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.GetTemplate(testTeamID, "standup"); got != "lobby <@UBOB>" || err != nil {
+		t.Errorf("template = %q, %v, want the saved text", got, err)
+	}
+	if got, _ := store.GetTemplate("T2", "standup"); got != "" {
+		t.Errorf("other team's template = %q, want none", got)
+	}
+}
@@ -0,0 +1,76 @@
+package jitsi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// The memory stores are shared by concurrent requests, run these with -race.
+
+func TestMemoryTokenStoreConcurrency(t *testing.T) {
+	store := &MemoryTokenStore{}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		teamID := fmt.Sprintf("T%d", i%4)
+		token := fmt.Sprintf("xoxb-%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := store.Store(&TokenData{TeamID: teamID, BotToken: token}); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := store.GetFirstBotTokenForTeam(teamID)
+			if err != nil && err.Error() != errMissingAuthToken {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 4; i++ {
+		if _, err := store.GetFirstBotTokenForTeam(fmt.Sprintf("T%d", i)); err != nil {
+			t.Errorf("team T%d: %v", i, err)
+		}
+	}
+	if _, err := store.GetFirstBotTokenForTeam("T9"); err == nil || err.Error() != errMissingAuthToken {
+		t.Errorf("unknown team err = %v, want %s", err, errMissingAuthToken)
+	}
+}
+
+func TestMemoryServerConfigStoreConcurrency(t *testing.T) {
+	store := &MemoryServerConfigStore{}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		teamID := fmt.Sprintf("T%d", i%4)
+		cfg := ServerConfig{ConferenceHost: fmt.Sprintf("https://meet%d.example.com", i)}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := store.StoreServerConfig(teamID, cfg); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := store.GetServerConfig(teamID)
+			if err != nil && !errors.Is(err, ErrServerConfigNotFound) {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 4; i++ {
+		if cfg, err := store.GetServerConfig(fmt.Sprintf("T%d", i)); err != nil || cfg.ConferenceHost == "" {
+			t.Errorf("team T%d = %+v, %v, want a stored config", i, cfg, err)
+		}
+	}
+	if _, err := store.GetServerConfig("T9"); !errors.Is(err, ErrServerConfigNotFound) {
+		t.Errorf("unknown team err = %v, want %v", err, ErrServerConfigNotFound)
+	}
+}
@@ -0,0 +1,45 @@
+package jitsi
+
+import (
+	"errors"
+	"sync"
+)
+
+// MemoryTokenStore stores access tokens in memory for local development and
+// examples that shouldn't need dynamodb. Tokens are lost on restart and
+// aren't shared between instances, so it isn't suitable for production.
+type MemoryTokenStore struct {
+	mu    sync.RWMutex
+	teams map[string]TokenData
+}
+
+// GetFirstBotTokenForTeam retrieves the bot token stored with the provided team id.
+func (m *MemoryTokenStore) GetFirstBotTokenForTeam(teamID string) (string, error) {
+	d, err := m.GetFirstTokenDataForTeam(teamID)
+	if err != nil {
+		return "", err
+	}
+	return d.BotToken, nil
+}
+
+// GetFirstTokenDataForTeam retrieves the token data stored with the provided team id.
+func (m *MemoryTokenStore) GetFirstTokenDataForTeam(teamID string) (*TokenData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.teams[teamID]
+	if !ok {
+		return nil, errors.New(errMissingAuthToken)
+	}
+	return &d, nil
+}
+
+// Store will store access token data, replacing any stored for the team.
+func (m *MemoryTokenStore) Store(data *TokenData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.teams == nil {
+		m.teams = map[string]TokenData{}
+	}
+	m.teams[data.TeamID] = *data
+	return nil
+}
@@ -0,0 +1,27 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type metricsResponse struct {
+	// Workers is only reported when work runs on a WorkerPool.
+	Workers *WorkerStats `json:"workers,omitempty"`
+}
+
+// Metrics responds with service metrics such as the worker queue depth for
+// monitoring. Requests must carry the AdminAPIToken as a bearer token.
+func (s *SlashCommandHandlers) Metrics(w http.ResponseWriter, r *http.Request) {
+	if !s.adminRequest(w, r) {
+		return
+	}
+
+	var metrics metricsResponse
+	if s.Workers != nil {
+		stats := s.Workers.Stats()
+		metrics.Workers = &stats
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
@@ -0,0 +1,78 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	whoUnsupportedMsg = "Listing meeting participants isn't supported by this conference service."
+	whoUsageMsg       = "Please provide the name of a room i.e. '%s who MyRoomName'."
+	whoEmptyMsg       = "Nobody is in %s right now."
+	whoErrorMsg       = "Unable to list the participants in %s right now. Please try again later."
+)
+
+// Participant is a user currently in a conference.
+type Participant struct {
+	Name string
+}
+
+// ParticipantReader provides an interface for listing the participants of a
+// conference in a tenant. Support depends on the conference deployment so
+// it's optional.
+type ParticipantReader interface {
+	Participants(tenant, room string) ([]Participant, error)
+}
+
+// who responds with the participants currently in room of the caller's
+// tenant. The room is named as in meeting links, with or without the
+// configured prefix and suffix.
+func (s *SlashCommandHandlers) who(ctx context.Context, tenant, room string) CommandResult {
+	if s.ParticipantReader == nil {
+		return ephemeral(whoUnsupportedMsg)
+	}
+
+	fields := strings.Fields(room)
+	if len(fields) != 1 {
+		return ephemeral(fmt.Sprintf(whoUsageMsg, s.commandName()))
+	}
+	room = s.affixedRoomName(fields[0])
+	tenant = strings.ToLower(tenant)
+
+	// Room and participant names are user provided so they're escaped to
+	// keep them from being read as mentions or links.
+	shown := slackEscaper.Replace(room)
+	participants, err := s.ParticipantReader.Participants(tenant, room)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Str("tenant", tenant).
+			Str("room", room).
+			Msg("listing participants")
+		return ephemeral(fmt.Sprintf(whoErrorMsg, shown))
+	}
+	if len(participants) == 0 {
+		return ephemeral(fmt.Sprintf(whoEmptyMsg, shown))
+	}
+
+	names := make([]string, 0, len(participants))
+	for _, p := range participants {
+		names = append(names, slackEscaper.Replace(p.Name))
+	}
+	return ephemeral(fmt.Sprintf("In %s: %s", shown, strings.Join(names, ", ")))
+}
+
+// affixedRoomName adds the configured prefix and suffix to a room name
+// unless it already has them.
+func (s *SlashCommandHandlers) affixedRoomName(room string) string {
+	if !strings.HasPrefix(room, s.RoomPrefix) {
+		room = s.RoomPrefix + room
+	}
+	if !strings.HasSuffix(room, s.RoomSuffix) {
+		room += s.RoomSuffix
+	}
+	return room
+}
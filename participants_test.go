@@ -0,0 +1,43 @@
+package jitsi
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeParticipants lists participants from a map keyed by tenant/room.
+type fakeParticipants map[string][]Participant
+
+func (f fakeParticipants) Participants(tenant, room string) ([]Participant, error) {
+	return f[tenant+"/"+room], nil
+}
+
+func TestWho(t *testing.T) {
+	s := &SlashCommandHandlers{
+		RoomPrefix: "pre-",
+		RoomSuffix: "-suf",
+		ParticipantReader: fakeParticipants{
+			"acme/pre-Room-suf":  {{Name: "Bob"}, {Name: "<!channel> & <@U1>"}},
+			"other/pre-Room-suf": {{Name: "Mallory"}},
+		},
+	}
+	tests := []struct {
+		name   string
+		tenant string
+		room   string
+		want   string
+	}{
+		{"bare room", "Acme", "Room", "In pre-Room-suf: Bob, &lt;!channel&gt; &amp; &lt;@U1&gt;"},
+		{"affixed room", "acme", "pre-Room-suf", "In pre-Room-suf: Bob, &lt;!channel&gt; &amp; &lt;@U1&gt;"},
+		{"other tenant", "empty", "Room", "Nobody is in pre-Room-suf right now."},
+		{"escaped room", "acme", "<@U1>", "Nobody is in pre-&lt;@U1&gt;-suf right now."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := responseOf(t, s.who(context.Background(), tt.tenant, tt.room))
+			if got != tt.want {
+				t.Errorf("who = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package jitsi
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ProbeTLSPolicy is the tls policy conference hosts must meet when probed
+// for health. A host that doesn't meet the policy fails its check.
+type ProbeTLSPolicy struct {
+	// MinVersion is the minimum tls version i.e. "1.2". Go's default is used
+	// when empty.
+	MinVersion string
+	// Pins are hex encoded sha256 fingerprints of accepted leaf
+	// certificates. Any trusted certificate is accepted when empty.
+	Pins []string
+}
+
+// Client returns a copy of client that enforces the policy.
+func (p ProbeTLSPolicy) Client(client *http.Client) (*http.Client, error) {
+	client = httpClientOrDefault(client)
+	transport, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		transport, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return nil, errors.New("probe tls policy requires an http transport")
+	}
+
+	cfg := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		cfg = transport.TLSClientConfig.Clone()
+	}
+	if p.MinVersion != "" {
+		version, ok := tlsVersions[p.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown tls version %q", p.MinVersion)
+		}
+		cfg.MinVersion = version
+	}
+	if len(p.Pins) > 0 {
+		pins := map[string]bool{}
+		for _, pin := range p.Pins {
+			pin = strings.ToLower(strings.Replace(pin, ":", "", -1))
+			if _, err := hex.DecodeString(pin); err != nil || len(pin) != sha256.Size*2 {
+				return nil, fmt.Errorf("invalid certificate pin %q", pin)
+			}
+			pins[pin] = true
+		}
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no peer certificate")
+			}
+			sum := sha256.Sum256(rawCerts[0])
+			if !pins[hex.EncodeToString(sum[:])] {
+				return errors.New("peer certificate is not pinned")
+			}
+			return nil
+		}
+	}
+
+	probeTransport := transport.Clone()
+	probeTransport.TLSClientConfig = cfg
+	probeClient := *client
+	probeClient.Transport = probeTransport
+	return &probeClient, nil
+}
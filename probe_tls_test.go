@@ -0,0 +1,82 @@
+package jitsi
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTLSHost(t *testing.T, maxVersion uint16) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{MaxVersion: maxVersion}
+	// Failed handshakes are expected, so they aren't logged.
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func certPin(srv *httptest.Server) string {
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestProbeTLSPolicy(t *testing.T) {
+	srv := newTLSHost(t, tls.VersionTLS12)
+	pin := certPin(srv)
+	colonPin := strings.ToUpper(pin[:2]) + ":" + pin[2:]
+
+	tests := []struct {
+		name    string
+		policy  ProbeTLSPolicy
+		healthy bool
+	}{
+		{"no policy", ProbeTLSPolicy{}, true},
+		{"min version met", ProbeTLSPolicy{MinVersion: "1.2"}, true},
+		{"min version unmet", ProbeTLSPolicy{MinVersion: "1.3"}, false},
+		{"pinned", ProbeTLSPolicy{Pins: []string{pin}}, true},
+		{"pinned with colons", ProbeTLSPolicy{Pins: []string{colonPin}}, true},
+		{"not pinned", ProbeTLSPolicy{Pins: []string{strings.Repeat("ab", sha256.Size)}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.policy.Client(srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := checkHost(client, srv.URL); got != tt.healthy {
+				t.Errorf("checkHost = %v, want %v", got, tt.healthy)
+			}
+		})
+	}
+}
+
+func TestProbeTLSPolicyKeepsClient(t *testing.T) {
+	srv := newTLSHost(t, tls.VersionTLS12)
+	client := srv.Client()
+	if _, err := (ProbeTLSPolicy{MinVersion: "1.3"}).Client(client); err != nil {
+		t.Fatal(err)
+	}
+	if !checkHost(client, srv.URL) {
+		t.Error("the policy changed the client it was given")
+	}
+}
+
+func TestProbeTLSPolicyRejectsBadConfig(t *testing.T) {
+	for name, policy := range map[string]ProbeTLSPolicy{
+		"unknown version": {MinVersion: "2.0"},
+		"short pin":       {Pins: []string{"abcd"}},
+		"non hex pin":     {Pins: []string{strings.Repeat("zz", sha256.Size)}},
+	} {
+		if _, err := policy.Client(nil); err == nil {
+			t.Errorf("%s: Client succeeded, want an error", name)
+		}
+	}
+}
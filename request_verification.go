@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -36,15 +37,21 @@ func ValidRequest(slackSigningSecret, requestBody, timestamp, slackSignature str
 	// using : as a delimiter
 	sigBaseString := fmt.Sprintf("%s:%s:%s", SignatureVersion, timestamp, requestBody)
 
+	// Only signatures of the version we compute are accepted.
+	prefix := SignatureVersion + "="
+	if !strings.HasPrefix(slackSignature, prefix) {
+		return false
+	}
+	theirMAC, err := hex.DecodeString(strings.TrimPrefix(slackSignature, prefix))
+	if err != nil {
+		return false
+	}
+
 	// Attempt to replicate the signature for ourselves.
 	hasher := hmac.New(sha256.New, []byte(slackSigningSecret))
 	hasher.Write([]byte(sigBaseString))
-	mySignature := fmt.Sprintf(
-		"%s=%s",
-		SignatureVersion,
-		hex.EncodeToString(hasher.Sum(nil)),
-	)
 
-	// Compare our signature with Slack's.
-	return mySignature == slackSignature
+	// Compare our signature with Slack's in constant time so the
+	// comparison doesn't leak how much of a forged signature matched.
+	return hmac.Equal(hasher.Sum(nil), theirMAC)
 }
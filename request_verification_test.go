@@ -0,0 +1,124 @@
+package jitsi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// slackSignature signs a request body the way Slack does.
+func slackSignature(secret, version, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s:%s:%s", SignatureVersion, timestamp, body)
+	return version + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidRequest(t *testing.T) {
+	const body = "token=x&team_id=T1&text=hello"
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	valid := slackSignature(testSigningSecret, SignatureVersion, now, body)
+	swapped := "0"
+	if valid[len(valid)-1] == '0' {
+		swapped = "1"
+	}
+	lastByte := valid[:len(valid)-1] + swapped
+	stale := strconv.FormatInt(time.Now().Add(-6*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		want      bool
+	}{
+		{"valid", now, valid, true},
+		{"other secret", now, slackSignature("other-secret", SignatureVersion, now, body), false},
+		{"last byte differs", now, lastByte, false},
+		{"wrong version", now, slackSignature(testSigningSecret, "v1", now, body), false},
+		{"no version", now, valid[len(SignatureVersion+"="):], false},
+		{"not hex", now, SignatureVersion + "=not-hex", false},
+		{"truncated", now, valid[:len(valid)-2], false},
+		{"empty", now, "", false},
+		{"stale", stale, slackSignature(testSigningSecret, SignatureVersion, stale, body), false},
+		{"bad timestamp", "yesterday", valid, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidRequest(testSigningSecret, body, tt.timestamp, tt.signature); got != tt.want {
+				t.Errorf("ValidRequest(%s) = %v, want %v", tt.signature, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigningSecretRotation(t *testing.T) {
+	const body = "token=x&team_id=T1&text=hello"
+	tests := []struct {
+		name   string
+		secret string
+		next   string
+		status int
+		logged string
+	}{
+		{"current", testSigningSecret, "next-secret", http.StatusOK, `"signing_secret":"current"`},
+		{"next", "next-secret", "next-secret", http.StatusOK, `"signing_secret":"next"`},
+		{"neither", "other-secret", "next-secret", http.StatusUnauthorized, ""},
+		{"no next secret", "next-secret", "", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := strconv.FormatInt(time.Now().Unix(), 10)
+			r := httptest.NewRequest(http.MethodPost, PathSlashCommand, strings.NewReader(body))
+			r.Header.Set(RequestTimestampHeader, now)
+			r.Header.Set(RequestSignatureHeader, slackSignature(tt.secret, SignatureVersion, now, body))
+			var logs bytes.Buffer
+			r = r.WithContext(zerolog.New(&logs).Level(zerolog.DebugLevel).WithContext(r.Context()))
+			w := httptest.NewRecorder()
+
+			valid := handleRequestValidation(w, r, testSigningSecret, tt.next)
+			if valid != (tt.status == http.StatusOK) || (!valid && w.Code != tt.status) {
+				t.Fatalf("valid = %v with status %d, want status %d", valid, w.Code, tt.status)
+			}
+			if !valid {
+				return
+			}
+			if !strings.Contains(logs.String(), tt.logged) {
+				t.Errorf("logs = %s, want %s", logs.String(), tt.logged)
+			}
+			// The body is left for the handler to read.
+			if got, _ := ioutil.ReadAll(r.Body); string(got) != body {
+				t.Errorf("body = %q, want %q", got, body)
+			}
+		})
+	}
+}
+
+func TestSigningSecretRotationHandlers(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.SlackSigningSecret = "old-secret"
+	s.NextSlackSigningSecret = testSigningSecret
+
+	// slashCommand signs with testSigningSecret, the next secret here.
+	w := httptest.NewRecorder()
+	s.Jitsi(w, slashCommand(t, "help"))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want a request signed with the next secret accepted", w.Code)
+	}
+
+	s.NextSlackSigningSecret = ""
+	w = httptest.NewRecorder()
+	s.Jitsi(w, slashCommand(t, "help"))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want the request refused once the next secret is unset", w.Code)
+	}
+}
@@ -0,0 +1,21 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	commandFailedTemplate = `{"response_type":"ephemeral","text":"%[1]s","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"%[1]s"}},{"type":"actions","elements":[{"type":"button","action_id":"%[2]s","text":{"type":"plain_text","text":"Try again"},"value":%[3]s}]}]}`
+
+	commandFailedMsg = "Sorry, something went wrong with your command."
+
+	actionRetryCommand = "retry_command"
+)
+
+// commandFailed tells the user their command failed with a button that
+// runs the command text again.
+func commandFailed(text string) CommandResult {
+	value, _ := json.Marshal(text)
+	return CommandResult{Body: fmt.Sprintf(commandFailedTemplate, commandFailedMsg, actionRetryCommand, value)}
+}
@@ -0,0 +1,139 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type retryPrompt struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+	Blocks       []struct {
+		Type string `json:"type"`
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+		Elements []struct {
+			ActionID string `json:"action_id"`
+			Text     struct {
+				Text string `json:"text"`
+			} `json:"text"`
+			Value string `json:"value"`
+		} `json:"elements"`
+	} `json:"blocks"`
+}
+
+// retryPromptOf decodes a failed command's reply, failing unless it has a
+// single retry button.
+func retryPromptOf(t *testing.T, body []byte) retryPrompt {
+	t.Helper()
+	var prompt retryPrompt
+	if err := json.Unmarshal(body, &prompt); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	if len(prompt.Blocks) != 2 || len(prompt.Blocks[1].Elements) != 1 {
+		t.Fatalf("reply = %s, want a message and a retry button", body)
+	}
+	return prompt
+}
+
+// retryPayload is an interaction clicking the retry button for text.
+func retryPayload(t *testing.T, text string) string {
+	t.Helper()
+	return `{
+		"type": "block_actions",
+		"response_url": "https://hooks.slack.com/actions/T1/1/abc",
+		"user": {"id": "UHOST"},
+		"team": {"id": "T1", "domain": "acme"},
+		"channel": {"id": "C1"},
+		"actions": [{"action_id": "` + actionRetryCommand + `", "value": "` + mustJSON(t, text) + `"}]
+	}`
+}
+
+func TestCommandFailed(t *testing.T) {
+	text := `<@UBOB> "planning"` + "\n" + `\o/`
+	prompt := retryPromptOf(t, []byte(commandFailed(text).Body))
+	if prompt.ResponseType != "ephemeral" || prompt.Text != commandFailedMsg {
+		t.Errorf("reply = %+v, want an ephemeral %q", prompt, commandFailedMsg)
+	}
+	if prompt.Blocks[0].Type != "section" || prompt.Blocks[0].Text.Text != commandFailedMsg {
+		t.Errorf("first block = %+v, want a section with %q", prompt.Blocks[0], commandFailedMsg)
+	}
+	button := prompt.Blocks[1].Elements[0]
+	if prompt.Blocks[1].Type != "actions" || button.ActionID != actionRetryCommand || button.Text.Text != "Try again" {
+		t.Errorf("button = %+v, want a Try again retry button", button)
+	}
+	if button.Value != text {
+		t.Errorf("button value = %q, want the command text %q", button.Value, text)
+	}
+}
+
+func TestFailedSlashCommandOffersRetry(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerConfigs = stubServerConfigs{err: errors.New("table unavailable")}
+
+	w := httptest.NewRecorder()
+	s.Jitsi(w, slashCommand(t, "<@UBOB> --max-occupants=5"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 so Slack shows the reply", w.Code)
+	}
+	if got := retryPromptOf(t, w.Body.Bytes()).Blocks[1].Elements[0].Value; got != "<@UBOB> --max-occupants=5" {
+		t.Errorf("retry value = %q, want the command text", got)
+	}
+}
+
+func TestRetryRerunsCommand(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+
+	if w := interact(t, s, retryPayload(t, "<@UBOB>")); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	responses := waitForCalls(t, slack, "actions/T1/1/abc")
+	if len(responses) != 1 {
+		t.Fatalf("responses = %d, want the result", len(responses))
+	}
+	body := responses[0].Body
+	if body["replace_original"] != true || !strings.Contains(string(mustMarshal(t, body)), "Invitations have been sent") {
+		t.Errorf("response = %v, want the retry prompt replaced with the result", body)
+	}
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 || posted[0].Form.Get("channel") != "DUBOB" {
+		t.Errorf("chat.postMessage calls = %+v, want the invite sent to DUBOB", posted)
+	}
+}
+
+func TestRetryFailingAgainOffersRetry(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = stubServerConfigs{err: errors.New("table unavailable")}
+
+	interact(t, s, retryPayload(t, "help"))
+	responses := waitForCalls(t, slack, "actions/T1/1/abc")
+	if len(responses) != 1 || responses[0].Body["replace_original"] != true {
+		t.Fatalf("responses = %+v, want the prompt replaced", responses)
+	}
+	if got := retryPromptOf(t, mustMarshal(t, responses[0].Body)).Blocks[1].Elements[0].Value; got != "help" {
+		t.Errorf("retry value = %q, want help", got)
+	}
+}
+
+func TestSocketModeFailedCommandOffersRetry(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = stubServerConfigs{err: errors.New("table unavailable")}
+
+	serveEnvelope(t, s, envelopeSlashCommands, `{"team_id":"T1","team_domain":"acme","user_id":"UHOST","channel_id":"C1","text":"help","response_url":"https://hooks.slack.com/commands/T1/1/abc"}`)
+	responses := waitForCalls(t, slack, "commands/T1/1/abc")
+	if len(responses) != 1 {
+		t.Fatalf("responses = %d, want the retry prompt", len(responses))
+	}
+	if got := retryPromptOf(t, mustMarshal(t, responses[0].Body)).Blocks[1].Elements[0].Value; got != "help" {
+		t.Errorf("retry value = %q, want help", got)
+	}
+}
@@ -0,0 +1,80 @@
+package jitsi
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures the timeout, retries and backoff of outbound calls.
+type RetryPolicy struct {
+	// MaxRetries is how many times a failed call is retried.
+	MaxRetries int
+	// BaseBackoff is the wait before the first retry. It doubles for each
+	// retry after that up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Timeout limits each call. Zero leaves calls limited only by their
+	// context.
+	Timeout time.Duration
+	// Jitter randomizes each backoff by up to this fraction of it, i.e. 0.2
+	// waits between 80% and 120% of the backoff.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a policy suited to calls made while a user waits.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:  1,
+	BaseBackoff: time.Second,
+	MaxBackoff:  time.Minute,
+	Timeout:     time.Second,
+	Jitter:      0.2,
+}
+
+// Backoff returns the wait before retrying after the given number of
+// previous retries.
+func (p RetryPolicy) Backoff(retries int) time.Duration {
+	backoff := p.BaseBackoff
+	for i := 0; i < retries && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		backoff += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(backoff))
+	}
+	return backoff
+}
+
+// Do calls fn with a context limited to the policy's timeout, retrying while
+// retryable reports the error can be retried and ctx is still live.
+// retryable may also return how long the error asks to wait before
+// retrying, otherwise the policy's backoff is used. Errors asking for a
+// wait longer than MaxBackoff aren't retried.
+func (p RetryPolicy) Do(ctx context.Context, retryable func(error) (bool, time.Duration), fn func(ctx context.Context) error) error {
+	for retries := 0; ; retries++ {
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.Timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, p.Timeout)
+		}
+		err := fn(callCtx)
+		cancel()
+		if err == nil || retries >= p.MaxRetries {
+			return err
+		}
+		retry, wait := retryable(err)
+		if !retry || (p.MaxBackoff > 0 && wait > p.MaxBackoff) {
+			return err
+		}
+		if wait == 0 {
+			wait = p.Backoff(retries)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
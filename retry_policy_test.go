@@ -0,0 +1,99 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retries, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := p.Backoff(retries); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", retries, got, want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: time.Second, MaxBackoff: time.Minute, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		if got := p.Backoff(1); got < 1600*time.Millisecond || got > 2400*time.Millisecond {
+			t.Fatalf("Backoff(1) = %v, want 2s within 20%%", got)
+		}
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	errRetry := errors.New("retry me")
+	errFatal := errors.New("don't retry")
+	always := func(error) (bool, time.Duration) { return true, 0 }
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		retryable func(error) (bool, time.Duration)
+		errs      []error
+		want      error
+		calls     int
+	}{
+		{"succeeds", RetryPolicy{MaxRetries: 2}, always, []error{nil}, nil, 1},
+		{"retried", RetryPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond}, always, []error{errRetry, nil}, nil, 2},
+		{"out of retries", RetryPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond}, always, []error{errRetry, errRetry, errRetry}, errRetry, 3},
+		{"no retries", RetryPolicy{}, always, []error{errRetry}, errRetry, 1},
+		{"not retryable", RetryPolicy{MaxRetries: 2}, func(err error) (bool, time.Duration) { return err == errRetry, 0 }, []error{errFatal}, errFatal, 1},
+		{"wait over max backoff", RetryPolicy{MaxRetries: 2, MaxBackoff: time.Second}, func(error) (bool, time.Duration) { return true, time.Minute }, []error{errRetry}, errRetry, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := tt.policy.Do(context.Background(), tt.retryable, func(ctx context.Context) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if err != tt.want || calls != tt.calls {
+				t.Errorf("Do = %v after %d calls, want %v after %d", err, calls, tt.want, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoTimesOutEachCall(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 1, BaseBackoff: time.Millisecond, Timeout: 10 * time.Millisecond}
+	calls := 0
+	err := p.Do(context.Background(), func(err error) (bool, time.Duration) { return err == context.DeadlineExceeded, 0 }, func(ctx context.Context) error {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("call has no deadline, want the policy's timeout")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != context.DeadlineExceeded || calls != 2 {
+		t.Errorf("Do = %v after %d calls, want a timeout after 2", err, calls)
+	}
+}
+
+func TestRetryPolicyDoStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errRetry := errors.New("retry me")
+	p := RetryPolicy{MaxRetries: 5, BaseBackoff: time.Hour}
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- p.Do(ctx, func(error) (bool, time.Duration) { return true, 0 }, func(ctx context.Context) error {
+			calls++
+			return errRetry
+		})
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != errRetry || calls != 1 {
+			t.Errorf("Do = %v after %d calls, want the first error once canceled", err, calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do kept waiting after its context was canceled")
+	}
+}
@@ -0,0 +1,54 @@
+package jitsi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const roomDeniedMsg = "That room name isn't allowed, please choose another or leave it empty for a new random room each time."
+
+// RoomDenylist blocks custom room names that are offensive or reserved.
+// Rooms are checked after sanitization, so Names are sanitized the same way
+// and compared ignoring case, and Pattern is matched against the sanitized
+// room name.
+type RoomDenylist struct {
+	Names   []string
+	Pattern *regexp.Regexp
+}
+
+// NewRoomDenylist creates a denylist from names and a regular expression,
+// either of which can be empty. It returns nil when both are.
+func NewRoomDenylist(names []string, pattern string) (*RoomDenylist, error) {
+	var denylist RoomDenylist
+	for _, name := range names {
+		if name = sanitizeRoomName(name); name != "" {
+			denylist.Names = append(denylist.Names, name)
+		}
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid room deny pattern: %v", err)
+		}
+		denylist.Pattern = re
+	}
+	if len(denylist.Names) == 0 && denylist.Pattern == nil {
+		return nil, nil
+	}
+	return &denylist, nil
+}
+
+// Denied reports whether a sanitized room name is blocked. Nothing is
+// blocked by a nil denylist.
+func (d *RoomDenylist) Denied(room string) bool {
+	if d == nil {
+		return false
+	}
+	for _, name := range d.Names {
+		if strings.EqualFold(sanitizeRoomName(name), room) {
+			return true
+		}
+	}
+	return d.Pattern != nil && d.Pattern.MatchString(room)
+}
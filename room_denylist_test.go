@@ -0,0 +1,155 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func testRoomDenylist(t *testing.T) *RoomDenylist {
+	t.Helper()
+	denylist, err := NewRoomDenylist([]string{"all-hands", "Admin"}, `(?i)^secret`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return denylist
+}
+
+func TestRoomDenylist(t *testing.T) {
+	denylist := testRoomDenylist(t)
+	for room, want := range map[string]bool{
+		"allhands":     true,
+		"ADMIN":        true,
+		"SecretPlans":  true,
+		"standup":      false,
+		"admins":       false,
+		"topsecret":    false,
+		"allhandsdemo": false,
+	} {
+		if got := denylist.Denied(room); got != want {
+			t.Errorf("Denied(%q) = %v, want %v", room, got, want)
+		}
+	}
+	var unset *RoomDenylist
+	if unset.Denied("admin") {
+		t.Error("nil denylist denied a room")
+	}
+}
+
+func TestNewRoomDenylist(t *testing.T) {
+	if denylist, err := NewRoomDenylist(nil, ""); denylist != nil || err != nil {
+		t.Errorf("empty denylist = %v, %v, want nil", denylist, err)
+	}
+	if _, err := NewRoomDenylist(nil, "("); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+// submitWorkflowStep submits the workflow step configuration with a room.
+func submitWorkflowStep(t *testing.T, s *SlashCommandHandlers, room string) *httptest.ResponseRecorder {
+	t.Helper()
+	payload := interactionPayload{Type: interactionViewSubmission, Team: interactionID{ID: testTeamID}}
+	payload.View.CallbackID = workflowStepCallbackID
+	payload.View.State.Values = map[string]map[string]struct {
+		Value string `json:"value"`
+	}{workflowRoomInput: {workflowRoomInput: {Value: room}}}
+	payload.WorkflowStep.WorkflowStepEditID = "E1"
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"payload": {string(body)}}
+	w := httptest.NewRecorder()
+	s.Interaction(w, signedRequest(t, PathInteraction, "application/x-www-form-urlencoded", form.Encode()))
+	return w
+}
+
+func TestWorkflowStepRejectsDeniedRoom(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.RoomDenylist = testRoomDenylist(t)
+
+	w := submitWorkflowStep(t, s, "All Hands")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var response struct {
+		ResponseAction string            `json:"response_action"`
+		Errors         map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+	if response.ResponseAction != "errors" || response.Errors[workflowRoomInput] != roomDeniedMsg {
+		t.Errorf("response = %s, want the denied room error", w.Body)
+	}
+	if calls := slack.Calls("workflows.updateStep"); len(calls) != 0 {
+		t.Errorf("saved a denied room %d times", len(calls))
+	}
+}
+
+func TestWorkflowStepSavesAllowedRoom(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.RoomDenylist = testRoomDenylist(t)
+
+	if w := submitWorkflowStep(t, s, "standup"); w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Fatalf("response = %d %s, want an empty 200", w.Code, w.Body)
+	}
+	calls := slack.Calls("workflows.updateStep")
+	if len(calls) != 1 {
+		t.Fatalf("workflows.updateStep calls = %d, want 1", len(calls))
+	}
+	inputs, _ := calls[0].Body["inputs"].(map[string]interface{})
+	room, _ := inputs[workflowRoomInput].(map[string]interface{})
+	if room["value"] != "standup" {
+		t.Errorf("saved inputs = %v, want the standup room", inputs)
+	}
+}
+
+// executeWorkflowRoom runs a start meeting step configured with a room and
+// returns the room it completed with.
+func executeWorkflowRoom(t *testing.T, s *SlashCommandHandlers, slack *fakeSlack, room string) string {
+	t.Helper()
+	var event workflowStepExecuteEvent
+	event.WorkflowStep.WorkflowStepExecuteID = "X1"
+	event.WorkflowStep.Inputs = map[string]workflowInput{workflowRoomInput: {Value: room}}
+	s.executeWorkflowStep(context.Background(), testTeamID, event)
+
+	calls := slack.Calls("workflows.stepCompleted")
+	if len(calls) != 1 {
+		t.Fatalf("workflows.stepCompleted calls = %d, want 1", len(calls))
+	}
+	outputs, _ := calls[0].Body["outputs"].(map[string]interface{})
+	got, _ := outputs[workflowRoomOutput].(string)
+	return got
+}
+
+func TestWorkflowStepDeniedRoomFallsBackToRandom(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	// The step was saved before the room was denied.
+	s.RoomDenylist = testRoomDenylist(t)
+
+	room := executeWorkflowRoom(t, s, slack, "secret-plans")
+	if room == "" || room == "secretplans" {
+		t.Errorf("room = %q, want a random room", room)
+	}
+	if !regexp.MustCompile(`^[A-Za-z]+$`).MatchString(room) {
+		t.Errorf("room = %q, want a random room name", room)
+	}
+}
+
+func TestWorkflowStepKeepsAllowedRoom(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.RoomDenylist = testRoomDenylist(t)
+
+	if room := executeWorkflowRoom(t, s, slack, "team standup!"); room != "teamstandup" {
+		t.Errorf("room = %q, want teamstandup", room)
+	}
+}
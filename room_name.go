@@ -1,7 +1,10 @@
 package jitsi
 
 import (
+	"fmt"
+	"hash/fnv"
 	"math/rand"
+	"regexp"
 	"time"
 )
 
@@ -127,3 +130,37 @@ func RandomName() string {
 	)
 	return adj + noun + verb + adv
 }
+
+// ChannelName generates a video name deterministically from a channel so
+// the same name is generated for the channel every time.
+func ChannelName(teamID, channelID string) string {
+	h := fnv.New64a()
+	h.Write([]byte(teamID + "/" + channelID))
+	r := rand.New(rand.NewSource(int64(h.Sum64())))
+	var (
+		adj  = adjectives[r.Intn(countAdjectives)]
+		noun = nouns[r.Intn(countNoun)]
+		verb = verbs[r.Intn(countVerb)]
+		adv  = adverbs[r.Intn(countAdverb)]
+	)
+	return adj + noun + verb + adv
+}
+
+// maxRoomAffix is the longest a room name prefix or suffix can be.
+const maxRoomAffix = 32
+
+var roomAffixRE = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// roomName adds the RoomPrefix and RoomSuffix to a generated room name.
+func (s *SlashCommandHandlers) roomName(name string) string {
+	return s.RoomPrefix + name + s.RoomSuffix
+}
+
+// validateRoomAffix checks that a room name prefix or suffix keeps room
+// names url safe.
+func validateRoomAffix(name, affix string) error {
+	if len(affix) > maxRoomAffix || !roomAffixRE.MatchString(affix) {
+		return fmt.Errorf("room %s can only use letters, numbers, - and _, and can be up to %d characters long", name, maxRoomAffix)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package jitsi
+
+import (
+	"strings"
+	"testing"
+)
+
+// hasAffixes reports whether room has the prefix and suffix around a
+// generated name.
+func hasAffixes(room, prefix, suffix string) bool {
+	return len(room) > len(prefix)+len(suffix) && strings.HasPrefix(room, prefix) && strings.HasSuffix(room, suffix)
+}
+
+func TestRoomNameAffixes(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.RoomPrefix = "acme-"
+	s.RoomSuffix = "_x"
+	s.ChannelRooms = &MemoryChannelRoomStore{}
+
+	meeting := processCommand(t, s, "")
+	if !hasAffixes(meeting.Room, "acme-", "_x") {
+		t.Errorf("room = %q, want the prefix and suffix", meeting.Room)
+	}
+	if got, want := hostURL(t, meeting), testConfHost+"/acme/"+meeting.Room; !strings.HasPrefix(got, want) {
+		t.Errorf("host url = %s, want the room url %s", got, want)
+	}
+
+	invite := processCommand(t, s, "<@UBOB>")
+	if !hasAffixes(invite.Room, "acme-", "_x") {
+		t.Errorf("room = %q, want the prefix and suffix", invite.Room)
+	}
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want an invite", len(posted))
+	}
+	inviteLink := inviteURL(t, posted[0])
+	if !strings.HasPrefix(inviteLink, testConfHost+"/acme/"+invite.Room+"?jwt=") {
+		t.Errorf("invite url = %s, want the room with the prefix and suffix", inviteLink)
+	}
+	if claims := tokenClaims(t, inviteLink); claims["room"] != invite.Room {
+		t.Errorf("room claim = %v, want %q", claims["room"], invite.Room)
+	}
+
+	guest := processCommand(t, s, "guest")
+	if !hasAffixes(guest.Room, "acme-", "_x") || guestLink(t, guest) != testConfHost+"/acme/"+guest.Room {
+		t.Errorf("guest link = %s, want the room with the prefix and suffix", guestLink(t, guest))
+	}
+
+	if got, want := processCommand(t, s, "channel-room").Room, "acme-"+ChannelName(testTeamID, "C1")+"_x"; got != want {
+		t.Errorf("channel room = %q, want %q", got, want)
+	}
+}
+
+func TestRoomNameWithoutAffixes(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ChannelRooms = &MemoryChannelRoomStore{}
+
+	if got, want := processCommand(t, s, "channel-room").Room, ChannelName(testTeamID, "C1"); got != want {
+		t.Errorf("channel room = %q, want the plain name %q", got, want)
+	}
+}
+
+func TestValidateRoomAffix(t *testing.T) {
+	tests := []struct {
+		affix string
+		valid bool
+	}{
+		{"", true},
+		{"acme-", true},
+		{"team_01", true},
+		{strings.Repeat("a", maxRoomAffix), true},
+		{strings.Repeat("a", maxRoomAffix+1), false},
+		{"acme/", false},
+		{"ac me", false},
+		{"acme?", false},
+		{"café", false},
+	}
+	for _, tt := range tests {
+		if err := validateRoomAffix("prefix", tt.affix); (err == nil) != tt.valid {
+			t.Errorf("validateRoomAffix(%q) = %v, want valid %v", tt.affix, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package jitsi
+
+import "net/http"
+
+const (
+	// PathSlashCommand is the path Slack posts slash commands to.
+	PathSlashCommand = "/slash/jitsi"
+	// PathOAuth is the path Slack redirects to after an install.
+	PathOAuth = "/slack/auth"
+	// PathInteraction is the path Slack posts interactive components to.
+	PathInteraction = "/slack/interaction"
+	// PathEvents is the path Slack posts Events API requests to.
+	PathEvents = "/slack/events"
+	// PathInstallURL is the path tools get the install url from.
+	PathInstallURL = "/admin/install-url"
+	// PathMetrics is the path monitoring gets service metrics from.
+	PathMetrics = "/admin/metrics"
+)
+
+// methodAllowed responds 405 and returns false when a request doesn't use
+// method.
+func methodAllowed(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+// allowMethod responds 405 to requests that don't use method.
+func allowMethod(method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if methodAllowed(w, r, method) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Routes registers the Slack handlers on mux at their standard paths,
+// wrapped with middleware. Slack posts commands, interactions and events
+// while it redirects installs to the oauth handler with a GET, so other
+// methods are rejected before reaching a handler. The admin routes are
+// also wrapped with cors, ahead of the method check so browsers' OPTIONS
+// preflight requests are answered.
+func Routes(mux *http.ServeMux, slash *SlashCommandHandlers, oauth *SlackOAuthHandlers, middleware func(http.Handler) http.Handler, cors *CORS) {
+	mux.Handle(PathSlashCommand, middleware(allowMethod(http.MethodPost, http.HandlerFunc(slash.Jitsi))))
+	mux.Handle(PathOAuth, middleware(allowMethod(http.MethodGet, http.HandlerFunc(oauth.Auth))))
+	mux.Handle(PathInteraction, middleware(allowMethod(http.MethodPost, http.HandlerFunc(slash.Interaction))))
+	mux.Handle(PathEvents, middleware(allowMethod(http.MethodPost, http.HandlerFunc(slash.Events))))
+	mux.Handle(PathInstallURL, middleware(cors.Handler(allowMethod(http.MethodGet, http.HandlerFunc(slash.InstallLink)))))
+	mux.Handle(PathMetrics, middleware(cors.Handler(allowMethod(http.MethodGet, http.HandlerFunc(slash.Metrics)))))
+}
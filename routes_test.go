@@ -0,0 +1,92 @@
+package jitsi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testRoutes mounts the test handlers on a mux with middleware marking
+// every response it wraps.
+func testRoutes(t *testing.T, slack *fakeSlack) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	s := newTestHandlers(t, slack)
+	s.AdminAPIToken = "admin"
+	middleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "yes")
+			next.ServeHTTP(w, r)
+		})
+	}
+	Routes(mux, s, newTestOAuthHandlers(slack, &MemoryTokenStore{}), middleware, &CORS{})
+	return mux
+}
+
+func TestRoutesRejectWrongMethods(t *testing.T) {
+	tests := []struct {
+		path    string
+		method  string
+		allowed string
+	}{
+		{PathSlashCommand, http.MethodGet, http.MethodPost},
+		{PathSlashCommand, http.MethodPut, http.MethodPost},
+		{PathInteraction, http.MethodGet, http.MethodPost},
+		{PathEvents, http.MethodGet, http.MethodPost},
+		{PathEvents, http.MethodDelete, http.MethodPost},
+		{PathOAuth, http.MethodPost, http.MethodGet},
+		{PathInstallURL, http.MethodPost, http.MethodGet},
+		{PathMetrics, http.MethodPost, http.MethodGet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			mux := testRoutes(t, slack)
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != tt.allowed {
+				t.Errorf("response = %d allowing %q, want 405 allowing %s", w.Code, w.Header().Get("Allow"), tt.allowed)
+			}
+			if w.Header().Get("X-Middleware") != "yes" {
+				t.Error("response skipped the middleware")
+			}
+			if calls := slack.Calls("oauth.v2.access"); len(calls) != 0 {
+				t.Errorf("oauth.v2.access calls = %d, want the handler not reached", len(calls))
+			}
+		})
+	}
+}
+
+func TestRoutesReachHandlers(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("oauth.v2.access", testAccessResponse)
+	mux := testRoutes(t, slack)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, slashCommand(t, "help"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/jitsi") {
+		t.Errorf("slash command = %d %s, want the help", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, signedRequest(t, PathEvents, "application/json", `{"type":"url_verification","challenge":"abc"}`))
+	if w.Code != http.StatusOK || w.Body.String() != "abc" {
+		t.Errorf("events = %d %s, want the challenge", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathOAuth+"?code=abc", nil))
+	if w.Code != http.StatusFound || len(slack.Calls("oauth.v2.access")) != 1 {
+		t.Errorf("oauth = %d, want the code exchanged and a redirect", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, PathMetrics, nil)
+	r.Header.Set("Authorization", "Bearer admin")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Header().Get("X-Middleware") != "yes" {
+		t.Errorf("metrics = %d %v, want a 200 through the middleware", w.Code, w.Header())
+	}
+}
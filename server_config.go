@@ -0,0 +1,248 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// KeyConferenceHost is the dynamo key for storing the conference host
+	// of a team's server config.
+	KeyConferenceHost = "conference-host"
+	// KeyMeetingDuration is the dynamo key for storing the meeting duration
+	// of a team's server config in seconds.
+	KeyMeetingDuration = "meeting-duration"
+	// KeyAppSecret is the dynamo key for storing the encrypted app secret
+	// of a team's server config.
+	KeyAppSecret = "app-secret"
+)
+
+// ErrServerConfigNotFound is returned by a ServerConfigReader for teams
+// without a server config.
+var ErrServerConfigNotFound = errors.New("server config not found")
+
+// ServerConfig is the conference server configuration of a team. Settings
+// left empty use the operator defaults.
+type ServerConfig struct {
+	// ConferenceHost is the conference server hosting the team's meetings.
+	ConferenceHost string
+	// MeetingDuration is how long the team's meeting links are valid for,
+	// replacing the token generator's lifetime.
+	MeetingDuration time.Duration
+	// Capabilities turns the team's optional capabilities on or off over
+	// the operator's Capabilities.
+	Capabilities Capabilities
+	// AppSecret signs the team's conference tokens with HS256 in place of
+	// the operator's private key, for conference servers configured with
+	// their own app secret. It's encrypted at rest.
+	AppSecret string
+	// UnauthenticatedURLs leaves conference tokens off the team's meeting
+	// links, for conference servers that don't check them.
+	UnauthenticatedURLs bool
+}
+
+// ServerConfigReader provides an interface for reading the server config of
+// a team. ErrServerConfigNotFound is returned for teams without one.
+type ServerConfigReader interface {
+	GetServerConfig(teamID string) (ServerConfig, error)
+}
+
+// ServerConfigWriter provides an interface for writing the server config of
+// a team.
+type ServerConfigWriter interface {
+	StoreServerConfig(teamID string, cfg ServerConfig) error
+}
+
+// ServerConfigStore reads and writes team server configs.
+type ServerConfigStore interface {
+	ServerConfigReader
+	ServerConfigWriter
+}
+
+// MemoryServerConfigStore stores team server configs in memory for local
+// development and tests. Configs are lost on restart and aren't shared
+// between instances, so it isn't suitable for production.
+type MemoryServerConfigStore struct {
+	mu    sync.RWMutex
+	teams map[string]ServerConfig
+}
+
+// GetServerConfig retrieves the server config stored for a team.
+func (m *MemoryServerConfigStore) GetServerConfig(teamID string) (ServerConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cfg, ok := m.teams[teamID]
+	if !ok {
+		return ServerConfig{}, ErrServerConfigNotFound
+	}
+	cfg.Capabilities = copyCapabilities(cfg.Capabilities)
+	return cfg, nil
+}
+
+// StoreServerConfig stores the server config for a team.
+func (m *MemoryServerConfigStore) StoreServerConfig(teamID string, cfg ServerConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.teams == nil {
+		m.teams = map[string]ServerConfig{}
+	}
+	cfg.Capabilities = copyCapabilities(cfg.Capabilities)
+	m.teams[teamID] = cfg
+	return nil
+}
+
+// copyCapabilities copies capabilities so stored configs aren't shared with
+// callers.
+func copyCapabilities(capabilities Capabilities) Capabilities {
+	if capabilities == nil {
+		return nil
+	}
+	copied := Capabilities{}
+	for name, enabled := range capabilities {
+		copied[name] = enabled
+	}
+	return copied
+}
+
+// DynamoServerConfigStore stores and retrieves team server configs from aws
+// dynamodb. App secrets are stored encrypted with Encryptor, configs with
+// an app secret can't be stored when it's nil.
+type DynamoServerConfigStore struct {
+	TableName string
+	DB        *dynamodb.DynamoDB
+	Encryptor Encryptor
+}
+
+// GetServerConfig retrieves the server config stored for a team.
+func (d *DynamoServerConfigStore) GetServerConfig(teamID string) (ServerConfig, error) {
+	result, err := d.DB.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			KeyTeamID: {
+				S: aws.String(teamID),
+			},
+		},
+	})
+	if err != nil {
+		return ServerConfig{}, err
+	}
+	if len(result.Item) == 0 {
+		return ServerConfig{}, ErrServerConfigNotFound
+	}
+	var cfg ServerConfig
+	if host, ok := result.Item[KeyConferenceHost]; ok && host.S != nil {
+		cfg.ConferenceHost = *host.S
+	}
+	if duration, ok := result.Item[KeyMeetingDuration]; ok && duration.N != nil {
+		seconds, err := strconv.ParseInt(*duration.N, 10, 64)
+		if err != nil {
+			return ServerConfig{}, err
+		}
+		cfg.MeetingDuration = time.Duration(seconds) * time.Second
+	}
+	if stored, ok := result.Item[KeyCapabilities]; ok && len(stored.M) > 0 {
+		cfg.Capabilities = Capabilities{}
+		for name, enabled := range stored.M {
+			if enabled.BOOL != nil {
+				cfg.Capabilities[name] = *enabled.BOOL
+			}
+		}
+	}
+	if secret, ok := result.Item[KeyAppSecret]; ok && len(secret.B) > 0 {
+		if d.Encryptor == nil {
+			return ServerConfig{}, errNoEncryptor
+		}
+		plaintext, err := d.Encryptor.Decrypt(secret.B)
+		if err != nil {
+			return ServerConfig{}, err
+		}
+		cfg.AppSecret = string(plaintext)
+	}
+	if unauthenticated, ok := result.Item[KeyUnauthenticatedURLs]; ok && unauthenticated.BOOL != nil {
+		cfg.UnauthenticatedURLs = *unauthenticated.BOOL
+	}
+	return cfg, nil
+}
+
+// StoreServerConfig stores the server config for a team.
+func (d *DynamoServerConfigStore) StoreServerConfig(teamID string, cfg ServerConfig) error {
+	item := map[string]*dynamodb.AttributeValue{
+		KeyTeamID: {
+			S: aws.String(teamID),
+		},
+	}
+	if cfg.ConferenceHost != "" {
+		item[KeyConferenceHost] = &dynamodb.AttributeValue{S: aws.String(cfg.ConferenceHost)}
+	}
+	if cfg.MeetingDuration > 0 {
+		seconds := strconv.FormatInt(int64(cfg.MeetingDuration/time.Second), 10)
+		item[KeyMeetingDuration] = &dynamodb.AttributeValue{N: aws.String(seconds)}
+	}
+	if len(cfg.Capabilities) > 0 {
+		stored := map[string]*dynamodb.AttributeValue{}
+		for name, enabled := range cfg.Capabilities {
+			stored[name] = &dynamodb.AttributeValue{BOOL: aws.Bool(enabled)}
+		}
+		item[KeyCapabilities] = &dynamodb.AttributeValue{M: stored}
+	}
+	if cfg.AppSecret != "" {
+		if d.Encryptor == nil {
+			return errNoEncryptor
+		}
+		ciphertext, err := d.Encryptor.Encrypt([]byte(cfg.AppSecret))
+		if err != nil {
+			return err
+		}
+		item[KeyAppSecret] = &dynamodb.AttributeValue{B: ciphertext}
+	}
+	if cfg.UnauthenticatedURLs {
+		item[KeyUnauthenticatedURLs] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+	}
+	_, err := d.DB.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(d.TableName),
+	})
+	return err
+}
+
+// serverConfig returns the server config of a team. Teams without one get
+// an empty config so the operator defaults apply, other store errors are
+// returned.
+func (s *SlashCommandHandlers) serverConfig(teamID string) (ServerConfig, error) {
+	if s.ServerConfigs == nil {
+		return ServerConfig{}, nil
+	}
+	cfg, err := s.ServerConfigs.GetServerConfig(teamID)
+	if errors.Is(err, ErrServerConfigNotFound) {
+		return ServerConfig{}, nil
+	}
+	return cfg, err
+}
+
+// teamServerConfig returns the server config of a team with the operator's
+// conference host when the team hasn't configured one and its capabilities
+// applied over the operator's. A zero meeting duration leaves token
+// lifetimes to the token generator. How long the read took is logged.
+func (s *SlashCommandHandlers) teamServerConfig(ctx context.Context, teamID string) (ServerConfig, error) {
+	start := time.Now()
+	cfg, err := s.serverConfig(teamID)
+	logTiming(ctx, "server config read", start)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("retrieving server config")
+		return ServerConfig{}, err
+	}
+	if cfg.ConferenceHost == "" {
+		cfg.ConferenceHost = s.conferenceHost()
+	}
+	cfg.Capabilities = s.resolveCapabilities(cfg.Capabilities)
+	return cfg, nil
+}
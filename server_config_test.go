@@ -0,0 +1,169 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// stubServerConfigs answers every team with cfg and err.
+type stubServerConfigs struct {
+	cfg ServerConfig
+	err error
+}
+
+func (s stubServerConfigs) GetServerConfig(teamID string) (ServerConfig, error) {
+	return s.cfg, s.err
+}
+
+func (s stubServerConfigs) StoreServerConfig(teamID string, cfg ServerConfig) error {
+	return s.err
+}
+
+func TestProcessCommandServerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs ServerConfigStore
+		want    string
+	}{
+		{"no store", nil, testConfHost},
+		{"not found", stubServerConfigs{err: ErrServerConfigNotFound}, testConfHost},
+		{"team host", stubServerConfigs{cfg: ServerConfig{ConferenceHost: "https://team.example.com"}}, "https://team.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHandlers(t, newFakeSlack(t))
+			s.ServerConfigs = tt.configs
+			result := processCommand(t, s, "")
+			if want := tt.want + "/" + testTeamDomain + "/" + result.Room; !strings.Contains(result.Body, want) {
+				t.Errorf("body = %s, want %s", result.Body, want)
+			}
+		})
+	}
+}
+
+func TestProcessCommandServerConfigError(t *testing.T) {
+	storeErr := errors.New("table unavailable")
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerConfigs = stubServerConfigs{err: storeErr}
+
+	_, err := s.ProcessCommand(context.Background(), CommandInput{
+		TeamID:   testTeamID,
+		TeamName: testTeamDomain,
+		UserID:   "UHOST",
+	})
+	if !errors.Is(err, storeErr) {
+		t.Errorf("err = %v, want %v", err, storeErr)
+	}
+}
+
+func testEncryptor(t *testing.T) *AESEncryptor {
+	t.Helper()
+	e, err := NewAESEncryptor(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestDynamoServerConfigEncryptsAppSecret(t *testing.T) {
+	dynamo, db := newFakeDynamo(t)
+	store := &DynamoServerConfigStore{TableName: "configs", DB: db, Encryptor: testEncryptor(t)}
+	want := ServerConfig{ConferenceHost: "https://team.example.com", AppSecret: "team-secret"}
+	if err := store.StoreServerConfig(testTeamID, want); err != nil {
+		t.Fatal(err)
+	}
+
+	items := dynamo.Items("configs")
+	if len(items) != 1 {
+		t.Fatalf("stored %d items, want 1", len(items))
+	}
+	if stored := string(items[0][KeyAppSecret]); stored == "" || strings.Contains(stored, "team-secret") ||
+		strings.Contains(stored, base64.StdEncoding.EncodeToString([]byte("team-secret"))) {
+		t.Errorf("stored app secret %s, want it encrypted", stored)
+	}
+	got, err := store.GetServerConfig(testTeamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AppSecret != want.AppSecret || got.ConferenceHost != want.ConferenceHost {
+		t.Errorf("read %+v, want %+v", got, want)
+	}
+
+	// A different key can't read the secret back.
+	other, _ := NewAESEncryptor(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{8}, 32)))
+	store.Encryptor = other
+	if _, err := store.GetServerConfig(testTeamID); err == nil {
+		t.Error("read the app secret with the wrong key")
+	}
+}
+
+func TestDynamoServerConfigNeedsEncryptorForAppSecret(t *testing.T) {
+	dynamo, db := newFakeDynamo(t)
+	store := &DynamoServerConfigStore{TableName: "configs", DB: db}
+	if err := store.StoreServerConfig(testTeamID, ServerConfig{AppSecret: "team-secret"}); err != errNoEncryptor {
+		t.Errorf("err = %v, want %v", err, errNoEncryptor)
+	}
+	if items := dynamo.Items("configs"); len(items) != 0 {
+		t.Errorf("stored %v without an encryptor", items)
+	}
+	// Configs without a secret don't need one.
+	if err := store.StoreServerConfig(testTeamID, ServerConfig{MeetingDuration: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := store.GetServerConfig(testTeamID); err != nil || cfg.MeetingDuration != time.Hour {
+		t.Errorf("read %+v, %v, want the meeting duration", cfg, err)
+	}
+}
+
+func TestAESEncryptorRejectsBadKeys(t *testing.T) {
+	for _, key := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := NewAESEncryptor(key); err == nil {
+			t.Errorf("NewAESEncryptor(%q) succeeded, want an error", key)
+		}
+	}
+}
+
+func TestTeamAppSecretSignsTokens(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = &MemoryServerConfigStore{}
+	s.ServerConfigs.StoreServerConfig(testTeamID, ServerConfig{AppSecret: "team-secret"})
+
+	processCommand(t, s, "<@UBOB>")
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want 1", len(posted))
+	}
+	var invite []struct {
+		Actions []struct {
+			URL string `json:"url"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(posted[0].Form.Get("attachments")), &invite); err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(invite[0].Actions[0].URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.Parse(u.Query().Get("jwt"), func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("signed with %v", token.Header["alg"])
+		}
+		return []byte("team-secret"), nil
+	})
+	if err != nil || !token.Valid {
+		t.Errorf("invite token doesn't verify with the team secret: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package jitsi
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ServerPool tracks the health of redundant conference hosts and selects a
+// healthy one for new meetings. Hosts are considered healthy until a check
+// reports otherwise.
+type ServerPool struct {
+	Hosts []string
+	// Jitter randomizes the wait between checks by up to this fraction of
+	// the interval so instances started together don't check in lockstep.
+	Jitter float64
+
+	mu        sync.RWMutex
+	unhealthy map[string]bool
+}
+
+// SetHealthy records the health of a host.
+func (p *ServerPool) SetHealthy(host string, healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unhealthy == nil {
+		p.unhealthy = map[string]bool{}
+	}
+	p.unhealthy[host] = !healthy
+}
+
+// Select returns the first healthy host in the pool, or fallback when every
+// host is unhealthy.
+func (p *ServerPool) Select(fallback string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, host := range p.Hosts {
+		if !p.unhealthy[host] {
+			return host
+		}
+	}
+	return fallback
+}
+
+// Monitor checks each host every interval until stop is closed. A host is
+// healthy when it responds to a GET without a server error.
+func (p *ServerPool) Monitor(client *http.Client, interval time.Duration, stop <-chan struct{}) {
+	client = httpClientOrDefault(client)
+	for {
+		for _, host := range p.Hosts {
+			p.SetHealthy(host, checkHost(client, host))
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(p.nextCheck(interval)):
+		}
+	}
+}
+
+// nextCheck returns the wait before the next round of checks, the interval
+// randomized by Jitter.
+func (p *ServerPool) nextCheck(interval time.Duration) time.Duration {
+	return RetryPolicy{BaseBackoff: interval, Jitter: p.Jitter}.Backoff(0)
+}
+
+func checkHost(client *http.Client, host string) bool {
+	resp, err := client.Get(host)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
@@ -0,0 +1,95 @@
+package jitsi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerPoolSelect(t *testing.T) {
+	p := &ServerPool{Hosts: []string{"https://a.example.com", "https://b.example.com"}}
+	if got := p.Select(testConfHost); got != "https://a.example.com" {
+		t.Errorf("Select = %s, want the first host while all are healthy", got)
+	}
+	p.SetHealthy("https://a.example.com", false)
+	if got := p.Select(testConfHost); got != "https://b.example.com" {
+		t.Errorf("Select = %s, want the second host with the first down", got)
+	}
+	p.SetHealthy("https://b.example.com", false)
+	if got := p.Select(testConfHost); got != testConfHost {
+		t.Errorf("Select = %s, want the fallback with every host down", got)
+	}
+	p.SetHealthy("https://a.example.com", true)
+	if got := p.Select(testConfHost); got != "https://a.example.com" {
+		t.Errorf("Select = %s, want the first host once it recovers", got)
+	}
+}
+
+func TestServerPoolMonitor(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	p := &ServerPool{Hosts: []string{down.URL, up.URL}}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Monitor(nil, time.Hour, stop)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Select(testConfHost) != up.URL && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := p.Select(testConfHost); got != up.URL {
+		t.Errorf("Select = %s, want the healthy host %s", got, up.URL)
+	}
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Monitor didn't stop")
+	}
+}
+
+func TestMeetingsUseHealthyServer(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.ServerPool = &ServerPool{Hosts: []string{"https://a.example.com", "https://b.example.com"}}
+	s.ServerPool.SetHealthy("https://a.example.com", false)
+
+	if got := hostURL(t, processCommand(t, s, "")); !strings.HasPrefix(got, "https://b.example.com/") {
+		t.Errorf("meeting url = %s, want one on the healthy host", got)
+	}
+}
+
+func TestServerPoolChecksSpreadOverJitterWindow(t *testing.T) {
+	p := &ServerPool{Jitter: 0.2}
+	const interval = 10 * time.Second
+	min, max := interval, interval
+	for i := 0; i < 1000; i++ {
+		wait := p.nextCheck(interval)
+		if wait < 8*time.Second || wait > 12*time.Second {
+			t.Fatalf("nextCheck = %v, want it within 20%% of %v", wait, interval)
+		}
+		if wait < min {
+			min = wait
+		}
+		if wait > max {
+			max = wait
+		}
+	}
+	// Waits spread over most of the window rather than bunching up.
+	if min > 9*time.Second || max < 11*time.Second {
+		t.Errorf("waits ranged from %v to %v, want them spread from 8s to 12s", min, max)
+	}
+
+	if wait := (&ServerPool{}).nextCheck(interval); wait != interval {
+		t.Errorf("nextCheck = %v without jitter, want %v", wait, interval)
+	}
+}
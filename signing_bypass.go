@@ -0,0 +1,8 @@
+//go:build !devsigning
+// +build !devsigning
+
+package jitsi
+
+// signingBypassAvailable reports whether this build can skip Slack request
+// signature verification. Only builds with the devsigning tag can.
+const signingBypassAvailable = false
@@ -0,0 +1,69 @@
+//go:build devsigning
+// +build devsigning
+
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func unsignedCommand(text string) *http.Request {
+	form := url.Values{
+		"team_id":     {testTeamID},
+		"team_domain": {testTeamDomain},
+		"user_id":     {"UHOST"},
+		"channel_id":  {"C1"},
+		"command":     {"/jitsi"},
+		"text":        {text},
+	}
+	return unsignedRequest(PathSlashCommand, "application/x-www-form-urlencoded", form.Encode())
+}
+
+func TestSigningBypass(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.InsecureSkipVerification = true
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate = %v, want skipping verification allowed in devsigning builds", err)
+	}
+
+	var logs bytes.Buffer
+	r := unsignedCommand("")
+	r = r.WithContext(zerolog.New(&logs).WithContext(context.Background()))
+	w := httptest.NewRecorder()
+	s.Jitsi(w, r)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), testConfHost) {
+		t.Errorf("unsigned command = %d %s, want a meeting", w.Code, w.Body)
+	}
+	if !strings.Contains(logs.String(), `"level":"warn"`) || !strings.Contains(logs.String(), "verification skipped") {
+		t.Errorf("logs = %s, want a warning that verification was skipped", logs.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Events(w, unsignedRequest(PathEvents, "application/json", `{"type":"url_verification","challenge":"abc"}`))
+	if w.Code != http.StatusOK || w.Body.String() != "abc" {
+		t.Errorf("unsigned event = %d %s, want the challenge", w.Code, w.Body)
+	}
+}
+
+func TestSigningBypassNeedsOptIn(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+
+	w := httptest.NewRecorder()
+	s.Jitsi(w, unsignedCommand(""))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned command = %d, want %d without opting in", w.Code, http.StatusUnauthorized)
+	}
+	w = httptest.NewRecorder()
+	s.Events(w, unsignedRequest(PathEvents, "application/json", `{"type":"url_verification","challenge":"abc"}`))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned event = %d, want %d without opting in", w.Code, http.StatusUnauthorized)
+	}
+}
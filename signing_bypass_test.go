@@ -0,0 +1,39 @@
+//go:build !devsigning
+// +build !devsigning
+
+package jitsi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSigningBypassUnavailable(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.InsecureSkipVerification = true
+	if err := s.Validate(); err == nil || !strings.Contains(err.Error(), "devsigning") {
+		t.Errorf("Validate = %v, want skipping verification refused without the devsigning tag", err)
+	}
+
+	// Handlers built without validating still verify every request.
+	for _, r := range []*http.Request{
+		unsignedRequest(PathSlashCommand, "application/x-www-form-urlencoded", "team_id=T1&user_id=UHOST&text="),
+		unsignedRequest(PathEvents, "application/json", `{"type":"url_verification","challenge":"abc"}`),
+		unsignedRequest(PathInteraction, "application/x-www-form-urlencoded", "payload={}"),
+	} {
+		w := httptest.NewRecorder()
+		switch r.URL.Path {
+		case PathSlashCommand:
+			s.Jitsi(w, r)
+		case PathEvents:
+			s.Events(w, r)
+		case PathInteraction:
+			s.Interaction(w, r)
+		}
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("unsigned %s = %d, want %d", r.URL.Path, w.Code, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nlopes/slack"
+)
+
+// slackResponseTimeout is how long Slack waits for a response to a slash
+// command, interaction or event.
+const slackResponseTimeout = 3 * time.Second
+
+// postingMethods are the Slack api methods that post a message. A post that
+// timed out may still have been delivered, so they're only retried when
+// Slack rate limited them.
+var postingMethods = map[string]bool{
+	"chat.postMessage":   true,
+	"chat.postEphemeral": true,
+}
+
+// callSlack calls fn under the SlackRetry policy so a hung or rate limited
+// Slack api call can't fail a command outright. Calls that time out are
+// retried after a backoff and rate limited calls after the wait Slack asks
+// for.
+func (s *SlashCommandHandlers) callSlack(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.SlackRetry.Do(ctx, retryableSlackError, fn)
+}
+
+// callSlackPost is callSlack for calls that post a message, which are only
+// retried when they're rate limited so a slow post isn't delivered twice.
+func (s *SlashCommandHandlers) callSlackPost(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.SlackRetry.Do(ctx, retryableRateLimit, fn)
+}
+
+// retryableSlackError reports whether a Slack api call that failed with err
+// can be retried, and how long Slack asked to wait if it was rate limited.
+func retryableSlackError(err error) (bool, time.Duration) {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return true, rateLimited.RetryAfter
+	}
+	return timedOut(err), 0
+}
+
+// retryableRateLimit reports whether a Slack api call that failed with err
+// was rate limited, and how long Slack asked to wait.
+func retryableRateLimit(err error) (bool, time.Duration) {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return true, rateLimited.RetryAfter
+	}
+	return false, 0
+}
+
+// timedOut reports whether err is the result of a call timing out.
+func timedOut(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// rateLimited returns a rate limited error for a Slack api response that
+// was rejected for exceeding the rate limit, otherwise nil.
+func rateLimited(resp *http.Response) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	retryAfter, _ := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64)
+	return &slack.RateLimitedError{RetryAfter: time.Duration(retryAfter) * time.Second}
+}
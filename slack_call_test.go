@@ -0,0 +1,152 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nlopes/slack"
+)
+
+// slowSlackRetry times calls out quickly and retries them once.
+var slowSlackRetry = RetryPolicy{MaxRetries: 1, BaseBackoff: time.Millisecond, Timeout: 100 * time.Millisecond}
+
+func TestSlowSlackCallsAreRetried(t *testing.T) {
+	fake := newFakeSlack(t)
+	fake.Delay("users.info", 300*time.Millisecond)
+	s := newTestHandlers(t, fake)
+	s.SlackRetry = slowSlackRetry
+	client := slack.New(testBotToken, slack.OptionHTTPClient(fake.Client()))
+
+	_, err := s.fetchUserInfo(context.Background(), client, "UHOST")
+	if !timedOut(err) {
+		t.Errorf("fetchUserInfo = %v, want a timeout", err)
+	}
+	if calls := fake.Calls("users.info"); len(calls) != 2 {
+		t.Errorf("users.info calls = %d, want a timed out call retried once", len(calls))
+	}
+}
+
+func TestTimedOutPostsAreNotRetried(t *testing.T) {
+	fake := newFakeSlack(t)
+	fake.AddUser("UBOB", "bob")
+	fake.Delay("chat.postMessage", 300*time.Millisecond)
+	s := newTestHandlers(t, fake)
+	s.SlackRetry = slowSlackRetry
+
+	result := processCommand(t, s, "<@UBOB>")
+	if calls := fake.Calls("chat.postMessage"); len(calls) != 1 {
+		t.Errorf("chat.postMessage calls = %d, want a timed out post sent once", len(calls))
+	}
+	if _, text := responseOf(t, result); !strings.Contains(text, fmt.Sprintf(failedInvitesMsg, "<@UBOB>")) {
+		t.Errorf("reply = %q, want the timed out invite reported", text)
+	}
+}
+
+func TestRetryableRateLimit(t *testing.T) {
+	retry, wait := retryableRateLimit(&slack.RateLimitedError{RetryAfter: 2 * time.Second})
+	if !retry || wait != 2*time.Second {
+		t.Errorf("rate limited = %v, %v, want a retry after 2s", retry, wait)
+	}
+	if retry, _ := retryableRateLimit(context.DeadlineExceeded); retry {
+		t.Error("timed out call retried, want only rate limited calls retried")
+	}
+}
+
+func TestRateLimitedCallsAreRetried(t *testing.T) {
+	tests := []struct {
+		method string
+		call   func(s *SlashCommandHandlers, client *slack.Client) error
+	}{
+		{"users.info", func(s *SlashCommandHandlers, client *slack.Client) error {
+			_, err := s.fetchUserInfo(context.Background(), client, "UHOST")
+			return err
+		}},
+		{"team.info", func(s *SlashCommandHandlers, client *slack.Client) error {
+			return s.callSlack(context.Background(), func(ctx context.Context) error {
+				_, err := client.GetTeamInfoContext(ctx)
+				return err
+			})
+		}},
+		{"chat.postMessage", func(s *SlashCommandHandlers, client *slack.Client) error {
+			_, err := s.postMessage(context.Background(), testBotToken, "D1", slack.PostMessageParameters{})
+			return err
+		}},
+		{"workflows.stepCompleted", func(s *SlashCommandHandlers, client *slack.Client) error {
+			return s.callSlackAPI(context.Background(), testBotToken, "workflows.stepCompleted", map[string]interface{}{"workflow_step_execute_id": "X1"})
+		}},
+		{"auth.revoke", func(s *SlashCommandHandlers, client *slack.Client) error {
+			return s.revokeToken(context.Background(), testBotToken)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			for _, limited := range []int{0, 1, 2} {
+				fake := newFakeSlack(t)
+				fake.RateLimit(tt.method, limited)
+				s := newTestHandlers(t, fake)
+				s.SlackRetry = RetryPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond}
+				client := slack.New(testBotToken, slack.OptionHTTPClient(fake.Client()))
+
+				if err := tt.call(s, client); err != nil {
+					t.Errorf("rate limited %d times: %v, want the call to succeed", limited, err)
+				}
+				if calls := fake.Calls(tt.method); len(calls) != limited+1 {
+					t.Errorf("rate limited %d times: %s calls = %d, want %d", limited, tt.method, len(calls), limited+1)
+				}
+			}
+
+			// Calls still rate limited once out of retries fail.
+			fake := newFakeSlack(t)
+			fake.RateLimit(tt.method, 3)
+			s := newTestHandlers(t, fake)
+			s.SlackRetry = RetryPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond}
+			client := slack.New(testBotToken, slack.OptionHTTPClient(fake.Client()))
+			var rateLimitedErr *slack.RateLimitedError
+			if err := tt.call(s, client); !errors.As(err, &rateLimitedErr) {
+				t.Errorf("out of retries: %v, want a rate limited error", err)
+			}
+			if calls := fake.Calls(tt.method); len(calls) != 3 {
+				t.Errorf("out of retries: %s calls = %d, want 3", tt.method, len(calls))
+			}
+		})
+	}
+}
+
+func TestRateLimitedError(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		want       error
+	}{
+		{http.StatusOK, "", nil},
+		{http.StatusTooManyRequests, "3", &slack.RateLimitedError{RetryAfter: 3 * time.Second}},
+		{http.StatusTooManyRequests, "", &slack.RateLimitedError{}},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		if tt.retryAfter != "" {
+			resp.Header.Set("Retry-After", tt.retryAfter)
+		}
+		if got := rateLimited(resp); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("rateLimited(%d, Retry-After %q) = %v, want %v", tt.status, tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestRetryableSlackError(t *testing.T) {
+	if retry, wait := retryableSlackError(&slack.RateLimitedError{RetryAfter: 2 * time.Second}); !retry || wait != 2*time.Second {
+		t.Errorf("rate limited = %v, %v, want a retry after 2s", retry, wait)
+	}
+	if retry, wait := retryableSlackError(context.DeadlineExceeded); !retry || wait != 0 {
+		t.Errorf("timed out = %v, %v, want a retry after the backoff", retry, wait)
+	}
+	if retry, _ := retryableSlackError(errors.New(errInvalidAuth)); retry {
+		t.Error("slack error retried, want it returned")
+	}
+}
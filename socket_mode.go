@@ -0,0 +1,309 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+)
+
+const (
+	socketModeOpenURL = "https://slack.com/api/apps.connections.open"
+
+	envelopeHello         = "hello"
+	envelopeDisconnect    = "disconnect"
+	envelopeSlashCommands = "slash_commands"
+	envelopeInteractive   = "interactive"
+	envelopeEventsAPI     = "events_api"
+)
+
+// socketEnvelope is a message received over a Socket Mode connection.
+type socketEnvelope struct {
+	Type       string          `json:"type"`
+	EnvelopeID string          `json:"envelope_id"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// socketAck acknowledges an envelope, optionally with a response payload.
+type socketAck struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+type connectionsOpenResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	URL   string `json:"url"`
+}
+
+// SocketConn is the subset of a websocket connection used by Socket Mode.
+type SocketConn interface {
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+	Close() error
+}
+
+// SocketModeRunner receives slash commands, interactions and events over a
+// Slack Socket Mode connection instead of the public http endpoints and
+// dispatches them through the same handler logic.
+type SocketModeRunner struct {
+	// AppToken is an app level token with the connections:write scope.
+	AppToken string
+	Handlers *SlashCommandHandlers
+	Log      zerolog.Logger
+	// HTTPClient is used to open connections. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Reconnect sets the backoff between failed connection attempts. Its
+	// retry limit and timeout aren't used. DefaultRetryPolicy is used when
+	// it has no base backoff.
+	Reconnect RetryPolicy
+}
+
+// Run connects to Slack and dispatches envelopes until stop is closed,
+// reconnecting whenever Slack drops or refreshes the connection.
+func (s *SocketModeRunner) Run(stop <-chan struct{}) {
+	policy := s.Reconnect
+	if policy.BaseBackoff == 0 {
+		policy = DefaultRetryPolicy
+	}
+	retries := 0
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		conn, err := s.connect()
+		if err != nil {
+			s.Log.Error().Err(err).Msg("opening socket mode connection")
+			select {
+			case <-stop:
+				return
+			case <-time.After(policy.Backoff(retries)):
+			}
+			retries++
+			continue
+		}
+		retries = 0
+
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-stop:
+			case <-done:
+			}
+			conn.Close()
+		}()
+		err = s.Serve(conn)
+		close(done)
+		if err != nil {
+			s.Log.Info().Err(err).Msg("socket mode connection closed")
+		}
+	}
+}
+
+// connect requests a websocket url from Slack and dials it.
+func (s *SocketModeRunner) connect() (*websocket.Conn, error) {
+	req, err := http.NewRequest(http.MethodPost, socketModeOpenURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.AppToken)
+	req.Header.Set("Content-type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClientOrDefault(s.HTTPClient).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var opened connectionsOpenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&opened); err != nil {
+		return nil, err
+	}
+	if !opened.OK {
+		return nil, errors.New(opened.Error)
+	}
+
+	// Dial through the same proxy as other outbound requests.
+	dialer := websocket.Dialer{Proxy: http.ProxyFromEnvironment}
+	if t, ok := httpClientOrDefault(s.HTTPClient).Transport.(*http.Transport); ok {
+		dialer.Proxy = t.Proxy
+	}
+	conn, _, err := dialer.Dial(opened.URL, nil)
+	return conn, err
+}
+
+// Serve reads envelopes from conn until the connection fails or Slack asks
+// for a reconnect. Envelopes are acknowledged as soon as they're read, well
+// within Slack's 3 second deadline, and then dispatched on the handlers'
+// workers so a slow command doesn't hold up the envelopes behind it.
+func (s *SocketModeRunner) Serve(conn SocketConn) error {
+	ctx := s.Log.WithContext(context.Background())
+	for {
+		var env socketEnvelope
+		if err := conn.ReadJSON(&env); err != nil {
+			return err
+		}
+
+		switch env.Type {
+		case envelopeHello:
+			continue
+		case envelopeDisconnect:
+			return errors.New("disconnect requested by slack")
+		}
+
+		ack := socketAck{EnvelopeID: env.EnvelopeID}
+		if env.Type == envelopeInteractive {
+			// Slack only reads a view submission's errors from its ack.
+			ack.Payload = s.interactionErrors(env.Payload)
+		}
+		if env.EnvelopeID != "" {
+			if err := conn.WriteJSON(ack); err != nil {
+				return err
+			}
+		}
+
+		payload := env.Payload
+		switch {
+		case env.Type == envelopeSlashCommands:
+			if !s.Handlers.async(ctx, func() { s.dispatchCommand(ctx, payload) }) {
+				s.commandBusy(ctx, payload)
+			}
+		case env.Type == envelopeInteractive && ack.Payload == nil:
+			if !s.Handlers.async(ctx, func() { s.dispatchInteraction(payload) }) {
+				s.interactionBusy(ctx, payload)
+			}
+		case env.Type == envelopeEventsAPI:
+			// The events handler queues its own work on the workers and
+			// answers busy when it's dropped.
+			s.dispatchEvent(payload)
+		}
+	}
+}
+
+// commandPayload is the slash command payload of a Socket Mode envelope.
+type commandPayload struct {
+	TeamID      string `json:"team_id"`
+	TeamDomain  string `json:"team_domain"`
+	UserID      string `json:"user_id"`
+	ChannelID   string `json:"channel_id"`
+	Text        string `json:"text"`
+	ResponseURL string `json:"response_url"`
+}
+
+// dispatchCommand runs a slash command payload through the slash command
+// handlers and sends the result to the command's response url, since the
+// envelope was already acknowledged. Requests over Socket Mode are
+// authenticated by the connection so no signature validation is performed.
+func (s *SocketModeRunner) dispatchCommand(ctx context.Context, payload json.RawMessage) {
+	var cmd commandPayload
+	if err := json.Unmarshal(payload, &cmd); err != nil {
+		s.Log.Error().Err(err).Msg("decoding slash command payload")
+		return
+	}
+
+	result, err := s.Handlers.ProcessCommand(ctx, CommandInput{
+		UserID:    cmd.UserID,
+		TeamID:    cmd.TeamID,
+		TeamName:  cmd.TeamDomain,
+		ChannelID: cmd.ChannelID,
+		Text:      cmd.Text,
+	})
+	if err != nil {
+		result = commandFailed(cmd.Text)
+	}
+	if err := s.Handlers.respond(cmd.ResponseURL, result.Body); err != nil {
+		s.Log.Error().Err(err).Msg("responding to slash command")
+	}
+}
+
+// commandBusy tells the user a slash command was dropped since the workers
+// are saturated.
+func (s *SocketModeRunner) commandBusy(ctx context.Context, payload json.RawMessage) {
+	var cmd commandPayload
+	if err := json.Unmarshal(payload, &cmd); err != nil {
+		s.Log.Error().Err(err).Msg("decoding slash command payload")
+		return
+	}
+	s.Handlers.respondBusy(ctx, interactionPayload{ResponseURL: cmd.ResponseURL})
+}
+
+// interactionErrors returns the errors of a view submission that can't be
+// saved, to send with its ack, or nil.
+func (s *SocketModeRunner) interactionErrors(payload json.RawMessage) json.RawMessage {
+	var interaction interactionPayload
+	if err := json.Unmarshal(payload, &interaction); err != nil {
+		return nil
+	}
+	if interaction.Type != interactionViewSubmission || interaction.View.CallbackID != workflowStepCallbackID {
+		return nil
+	}
+	if errs, ok := s.Handlers.workflowStepErrors(interaction); ok {
+		return json.RawMessage(errs)
+	}
+	return nil
+}
+
+// interactionBusy tells the user an interaction was dropped since the
+// workers are saturated.
+func (s *SocketModeRunner) interactionBusy(ctx context.Context, payload json.RawMessage) {
+	var interaction interactionPayload
+	if err := json.Unmarshal(payload, &interaction); err != nil {
+		s.Log.Error().Err(err).Msg("decoding interaction payload")
+		return
+	}
+	s.Handlers.respondBusy(ctx, interaction)
+}
+
+// dispatchInteraction runs an interaction payload through the interaction
+// handler as the form post Slack would have sent over http.
+func (s *SocketModeRunner) dispatchInteraction(payload json.RawMessage) {
+	form := url.Values{"payload": {string(payload)}}
+	s.dispatchHTTP(s.Handlers.handleInteraction, "application/x-www-form-urlencoded", form.Encode())
+}
+
+// dispatchEvent runs an Events API payload through the events handler.
+func (s *SocketModeRunner) dispatchEvent(payload json.RawMessage) {
+	s.dispatchHTTP(s.Handlers.handleEvents, "application/json", string(payload))
+}
+
+// dispatchHTTP serves body with an http handler. Envelopes are
+// authenticated by the connection, so no signature validation is
+// performed.
+func (s *SocketModeRunner) dispatchHTTP(handler http.HandlerFunc, contentType, body string) {
+	ctx := s.Log.WithContext(context.Background())
+	req, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if err != nil {
+		s.Log.Error().Err(err).Msg("building socket mode request")
+		return
+	}
+	req.Header.Set("Content-type", contentType)
+	w := &socketResponse{header: http.Header{}, status: http.StatusOK}
+	handler(w, req.WithContext(ctx))
+	if w.status != http.StatusOK {
+		s.Log.Error().Int("status", w.status).Msg("dispatching socket mode envelope")
+	}
+}
+
+// socketResponse collects the response of an http handler serving a
+// Socket Mode envelope.
+type socketResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *socketResponse) Header() http.Header { return w.header }
+
+func (w *socketResponse) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+func (w *socketResponse) WriteHeader(status int) { w.status = status }
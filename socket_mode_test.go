@@ -0,0 +1,239 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// mockSocket is a SocketConn that delivers envelopes and records acks.
+type mockSocket struct {
+	mu        sync.Mutex
+	envelopes []string
+	acks      []socketAck
+}
+
+func (c *mockSocket) ReadJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.envelopes) == 0 {
+		return io.EOF
+	}
+	env := c.envelopes[0]
+	c.envelopes = c.envelopes[1:]
+	return json.Unmarshal([]byte(env), v)
+}
+
+func (c *mockSocket) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var ack socketAck
+	if err := json.Unmarshal(b, &ack); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acks = append(c.acks, ack)
+	return nil
+}
+
+func (c *mockSocket) Close() error { return nil }
+
+// serveEnvelope serves a single envelope and returns its ack.
+func serveEnvelope(t *testing.T, s *SlashCommandHandlers, envType, payload string) socketAck {
+	t.Helper()
+	env, _ := json.Marshal(map[string]interface{}{
+		"type":        envType,
+		"envelope_id": "E1",
+		"payload":     json.RawMessage(payload),
+	})
+	conn := &mockSocket{envelopes: []string{`{"type":"hello"}`, string(env)}}
+	runner := &SocketModeRunner{Handlers: s, Log: zerolog.Nop()}
+	if err := runner.Serve(conn); err != io.EOF {
+		t.Fatalf("Serve = %v, want io.EOF", err)
+	}
+	if len(conn.acks) != 1 || conn.acks[0].EnvelopeID != "E1" {
+		t.Fatalf("acks = %+v, want one for E1", conn.acks)
+	}
+	return conn.acks[0]
+}
+
+// waitForCalls waits for work done after the ack to call a Slack method.
+func waitForCalls(t *testing.T, slack *fakeSlack, method string) []slackCall {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if calls := slack.Calls(method); len(calls) > 0 || time.Now().After(deadline) {
+			return calls
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSocketModeDispatchesSlashCommands(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+
+	ack := serveEnvelope(t, s, envelopeSlashCommands, `{"team_id":"T1","team_domain":"acme","user_id":"UHOST","channel_id":"C1","text":"<@UBOB>","response_url":"https://hooks.slack.com/commands/T1/1/abc"}`)
+	if len(ack.Payload) != 0 {
+		t.Errorf("ack payload = %s, want none", ack.Payload)
+	}
+	responses := waitForCalls(t, slack, "commands/T1/1/abc")
+	if len(responses) != 1 || !strings.Contains(string(mustMarshal(t, responses[0].Body)), "Invitations have been sent") {
+		t.Fatalf("responses = %+v, want the invite confirmation", responses)
+	}
+	if calls := slack.Calls("chat.postMessage"); len(calls) != 1 || calls[0].Form.Get("channel") != "DUBOB" {
+		t.Errorf("chat.postMessage calls = %+v, want the invite to DUBOB", calls)
+	}
+}
+
+func TestSocketModeAcksBeforeDispatching(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	slack.Delay("chat.postMessage", time.Second)
+	s := newTestHandlers(t, slack)
+
+	start := time.Now()
+	serveEnvelope(t, s, envelopeSlashCommands, `{"team_id":"T1","team_domain":"acme","user_id":"UHOST","channel_id":"C1","text":"<@UBOB>","response_url":"https://hooks.slack.com/commands/T1/1/abc"}`)
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("acked after %v, want before the invite is sent", elapsed)
+	}
+	if responses := waitForCalls(t, slack, "commands/T1/1/abc"); len(responses) != 1 {
+		t.Errorf("responses = %d, want 1 once the invite is sent", len(responses))
+	}
+}
+
+func TestSocketModeAnswersBusyCommands(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.Workers = &WorkerPool{Size: 1, Queue: 1}
+	release := blockWorkers(t, s.Workers)
+	defer release()
+
+	serveEnvelope(t, s, envelopeSlashCommands, `{"team_id":"T1","team_domain":"acme","user_id":"UHOST","channel_id":"C1","text":"","response_url":"https://hooks.slack.com/commands/T1/1/abc"}`)
+	responses := slack.Calls("commands/T1/1/abc")
+	if len(responses) != 1 || !strings.Contains(string(mustMarshal(t, responses[0].Body)), busyMsg) {
+		t.Errorf("responses = %+v, want the busy notice", responses)
+	}
+}
+
+func TestSocketModeAcksViewSubmissionErrors(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.RoomDenylist = testRoomDenylist(t)
+
+	payload := `{
+		"type": "view_submission",
+		"team": {"id": "T1", "domain": "acme"},
+		"user": {"id": "UHOST"},
+		"workflow_step": {"workflow_step_edit_id": "WSE1"},
+		"view": {"callback_id": "` + workflowStepCallbackID + `", "state": {"values": {"room": {"room": {"value": "all-hands"}}}}}
+	}`
+	ack := serveEnvelope(t, s, envelopeInteractive, payload)
+	if !strings.Contains(string(ack.Payload), `"response_action":"errors"`) {
+		t.Errorf("ack payload = %s, want the view errors", ack.Payload)
+	}
+}
+
+func TestSocketModeRunStopsDuringBackoff(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("apps.connections.open", `{"ok":false,"error":"invalid_auth"}`)
+	runner := &SocketModeRunner{
+		Handlers:   &SlashCommandHandlers{},
+		Log:        zerolog.Nop(),
+		HTTPClient: slack.Client(),
+		Reconnect:  RetryPolicy{BaseBackoff: time.Hour},
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		runner.Run(stop)
+		close(done)
+	}()
+	waitForCalls(t, slack, "apps.connections.open")
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't stop during its backoff")
+	}
+}
+
+func TestSocketModeDispatchesInteractions(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	payload := `{
+		"type": "block_actions",
+		"response_url": "https://hooks.slack.com/actions/T1/1/abc",
+		"user": {"id": "UHOST"},
+		"team": {"id": "T1", "domain": "acme"},
+		"channel": {"id": "C1"},
+		"actions": [{"action_id": "post_meeting", "value": "https://meet.example.com/acme/SocketRoom"}]
+	}`
+	ack := serveEnvelope(t, s, envelopeInteractive, payload)
+	if len(ack.Payload) != 0 {
+		t.Errorf("ack payload = %s, want none", ack.Payload)
+	}
+	responses := waitForCalls(t, slack, "actions/T1/1/abc")
+	if len(responses) != 1 {
+		t.Fatalf("responses = %d, want 1", len(responses))
+	}
+	if got := responses[0].Body["response_type"]; got != "in_channel" {
+		t.Errorf("response_type = %v, want in_channel", got)
+	}
+	if !strings.Contains(string(mustMarshal(t, responses[0].Body)), "SocketRoom") {
+		t.Errorf("response %v doesn't post the meeting", responses[0].Body)
+	}
+}
+
+func TestSocketModeDispatchesEvents(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	payload := `{
+		"type": "event_callback",
+		"team_id": "T1",
+		"event": {"type": "app_mention", "user": "UHOST", "channel": "C1", "text": "<@UBOT> start"}
+	}`
+	ack := serveEnvelope(t, s, envelopeEventsAPI, payload)
+	if len(ack.Payload) != 0 {
+		t.Errorf("ack payload = %s, want none", ack.Payload)
+	}
+	calls := waitForCalls(t, slack, "chat.postMessage")
+	if len(calls) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want 1", len(calls))
+	}
+	if got := calls[0].Body["channel"]; got != "C1" {
+		t.Errorf("meeting posted to %v, want C1", got)
+	}
+}
+
+func TestSocketModeStopsOnDisconnect(t *testing.T) {
+	conn := &mockSocket{envelopes: []string{`{"type":"disconnect"}`, `{"type":"hello"}`}}
+	runner := &SocketModeRunner{Handlers: &SlashCommandHandlers{}, Log: zerolog.Nop()}
+	if err := runner.Serve(conn); err == nil || err == io.EOF {
+		t.Errorf("Serve = %v, want a disconnect error", err)
+	}
+	if len(conn.envelopes) != 1 {
+		t.Errorf("read past the disconnect")
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package jitsi
+
+import (
+	"context"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+// TeamReader provides an interface to read the token data stored for a team,
+// which includes the team's name and domain at install time.
+type TeamReader interface {
+	GetFirstTokenDataForTeam(teamID string) (*TokenData, error)
+}
+
+// teamDomain looks up the domain of a newly installed team so it can be
+// stored with its tokens. The access response only names the team. Failures
+// are logged and leave the domain empty since it's only a fallback.
+func (o *SlackOAuthHandlers) teamDomain(ctx context.Context, botToken string) string {
+	client := slack.New(botToken, slack.OptionHTTPClient(httpClientOrDefault(o.HTTPClient)))
+	team, err := client.GetTeamInfoContext(ctx)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().
+			Err(err).
+			Msg("retrieving team info for install")
+		return ""
+	}
+	return team.Domain
+}
+
+// storedTeamDomain returns the domain stored for a team at install time, for
+// requests that don't carry it. It's empty when Teams isn't set or nothing
+// was stored.
+func (s *SlashCommandHandlers) storedTeamDomain(ctx context.Context, teamID string) string {
+	if s.Teams == nil {
+		return ""
+	}
+	data, err := s.Teams.GetFirstTokenDataForTeam(teamID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("retrieving stored team domain")
+		return ""
+	}
+	return data.TeamDomain
+}
+
+// storedBotUserID returns the user id of the app's bot stored for a team at
+// install time. It's empty when Teams isn't set or nothing was stored.
+func (s *SlashCommandHandlers) storedBotUserID(ctx context.Context, teamID string) string {
+	if s.Teams == nil {
+		return ""
+	}
+	data, err := s.Teams.GetFirstTokenDataForTeam(teamID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("retrieving stored bot user id")
+		return ""
+	}
+	return data.BotUserID
+}
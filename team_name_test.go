@@ -0,0 +1,128 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type failingTeams struct{}
+
+func (failingTeams) GetFirstTokenDataForTeam(string) (*TokenData, error) {
+	return nil, errors.New("table unavailable")
+}
+
+func TestAuthStoresTeamNameAndDomain(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("oauth.v2.access", testAccessResponse)
+	tokens := &MemoryTokenStore{}
+	o := newTestOAuthHandlers(slack, tokens)
+
+	o.Auth(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slack/auth?code=abc", nil))
+	data, err := tokens.GetFirstTokenDataForTeam("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TeamName != "Acme" || data.TeamDomain != testTeamDomain {
+		t.Errorf("stored team %q %q, want Acme %s", data.TeamName, data.TeamDomain, testTeamDomain)
+	}
+	if calls := slack.Calls("team.info"); len(calls) != 1 || calls[0].Form.Get("token") != "xoxb-new" {
+		t.Errorf("team.info calls = %+v, want one with the installed bot token", calls)
+	}
+}
+
+func TestAuthStoresInstallWithoutTeamDomain(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("oauth.v2.access", testAccessResponse)
+	slack.Handle("team.info", `{"ok":false,"error":"missing_scope"}`)
+	tokens := &MemoryTokenStore{}
+	o := newTestOAuthHandlers(slack, tokens)
+
+	w := httptest.NewRecorder()
+	o.Auth(w, httptest.NewRequest(http.MethodGet, "/slack/auth?code=abc", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, want the install to go ahead", w.Code)
+	}
+	data, err := tokens.GetFirstTokenDataForTeam("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.BotToken != "xoxb-new" || data.TeamName != "Acme" || data.TeamDomain != "" {
+		t.Errorf("stored %+v, want the install without a domain", data)
+	}
+}
+
+func TestTokenStoreKeepsTeamNameAndDomain(t *testing.T) {
+	_, db := newFakeDynamo(t)
+	store := &TokenStore{TableName: "tokens", DB: db}
+	for _, d := range []TokenData{
+		{TeamID: "T1", UserID: "U1", BotToken: "xoxb-1", TeamName: "Acme", TeamDomain: "acme"},
+		{TeamID: "T2", UserID: "U2", BotToken: "xoxb-2"},
+	} {
+		d := d
+		if err := store.Store(&d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := store.GetFirstTokenDataForTeam("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TeamName != "Acme" || data.TeamDomain != "acme" {
+		t.Errorf("read team %q %q, want Acme acme", data.TeamName, data.TeamDomain)
+	}
+	data, err = store.GetFirstTokenDataForTeam("T2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TeamName != "" || data.TeamDomain != "" {
+		t.Errorf("read team %q %q, want none for an install without them", data.TeamName, data.TeamDomain)
+	}
+}
+
+func TestStoredTeamDomainFallback(t *testing.T) {
+	for payload, wantPath := range map[string]string{"acme": "/acme/", "": "/globex/"} {
+		slack := newFakeSlack(t)
+		slack.AddUser("UBOB", "bob")
+		s := newTestHandlers(t, slack)
+		tokens := s.TokenReader.(*MemoryTokenStore)
+		err := tokens.Store(&TokenData{TeamID: testTeamID, UserID: "UHOST", BotToken: testBotToken, TeamDomain: "globex"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Teams = tokens
+
+		result, err := s.ProcessCommand(context.Background(), CommandInput{
+			TeamID:    testTeamID,
+			TeamName:  payload,
+			UserID:    "UHOST",
+			ChannelID: "C1",
+			Text:      "<@UBOB>",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if host := hostURL(t, result); !strings.HasPrefix(host, testConfHost+wantPath) {
+			t.Errorf("payload domain %q: host url = %s, want %s%s", payload, host, testConfHost, wantPath)
+		}
+		if invite := inviteURL(t, slack.Calls("chat.postMessage")[0]); !strings.HasPrefix(invite, testConfHost+wantPath) {
+			t.Errorf("payload domain %q: invite url = %s, want %s%s", payload, invite, testConfHost, wantPath)
+		}
+	}
+}
+
+func TestStoredTeamDomainFailure(t *testing.T) {
+	s := newTestHandlers(t, newFakeSlack(t))
+	s.Teams = failingTeams{}
+	if got := s.storedTeamDomain(context.Background(), testTeamID); got != "" {
+		t.Errorf("storedTeamDomain = %q, want none when the store fails", got)
+	}
+	s.Teams = nil
+	if got := s.storedTeamDomain(context.Background(), testTeamID); got != "" {
+		t.Errorf("storedTeamDomain = %q, want none without a store", got)
+	}
+}
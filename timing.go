@@ -0,0 +1,40 @@
+package jitsi
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// slowCall is how long a token or server config read or signing can take
+// before it's logged as slow.
+const slowCall = 500 * time.Millisecond
+
+// logTiming logs how long a call to a dependency took, as a warning when
+// it's slow so slow dependencies stand out.
+func logTiming(ctx context.Context, call string, start time.Time) {
+	elapsed := time.Since(start)
+	event := zerolog.Ctx(ctx).Debug()
+	if elapsed >= slowCall {
+		event = zerolog.Ctx(ctx).Warn()
+	}
+	event.
+		Str("call", call).
+		Dur("duration", elapsed).
+		Msg("call timed")
+}
+
+// botToken reads a team's bot token from the TokenReader, logging how long
+// the read took.
+func (s *SlashCommandHandlers) botToken(ctx context.Context, teamID string) (string, error) {
+	defer logTiming(ctx, "token read", time.Now())
+	return s.TokenReader.GetFirstBotTokenForTeam(teamID)
+}
+
+// createJWT creates a conference token with the TokenGenerator, logging how
+// long signing took.
+func (s *SlashCommandHandlers) createJWT(ctx context.Context, in JWTInput) (string, error) {
+	defer logTiming(ctx, "token signing", time.Now())
+	return s.TokenGenerator.CreateJWT(in)
+}
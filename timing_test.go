@@ -0,0 +1,110 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type timedCall struct {
+	Level    string  `json:"level"`
+	Call     string  `json:"call"`
+	Duration float64 `json:"duration"`
+}
+
+// timedCalls decodes the calls logged as timed.
+func timedCalls(t *testing.T, logs *bytes.Buffer) []timedCall {
+	t.Helper()
+	var calls []timedCall
+	dec := json.NewDecoder(logs)
+	for dec.More() {
+		var line struct {
+			timedCall
+			Message string `json:"message"`
+		}
+		if err := dec.Decode(&line); err != nil {
+			t.Fatal(err)
+		}
+		if line.Message == "call timed" {
+			calls = append(calls, line.timedCall)
+		}
+	}
+	return calls
+}
+
+func TestLogTiming(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Duration
+		level string
+	}{
+		{"fast", 0, "debug"},
+		{"slow", slowCall, "warn"},
+		{"very slow", 3 * time.Second, "warn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			ctx := zerolog.New(&logs).WithContext(context.Background())
+			logTiming(ctx, "token signing", time.Now().Add(-tt.start))
+
+			calls := timedCalls(t, &logs)
+			if len(calls) != 1 || calls[0].Call != "token signing" || calls[0].Level != tt.level {
+				t.Fatalf("logged %+v, want token signing at %s", calls, tt.level)
+			}
+			if got := time.Duration(calls[0].Duration * float64(time.Millisecond)); got < tt.start {
+				t.Errorf("duration = %v, want at least %v", got, tt.start)
+			}
+		})
+	}
+}
+
+func TestMeetingTimesDependencies(t *testing.T) {
+	tests := []struct {
+		name            string
+		unauthenticated bool
+		want            map[string]bool
+	}{
+		{"tokens", false, map[string]bool{"token read": true, "server config read": true, "token signing": true}},
+		{"no tokens", true, map[string]bool{"token read": true, "server config read": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			configs := &MemoryServerConfigStore{}
+			configs.StoreServerConfig(testTeamID, ServerConfig{UnauthenticatedURLs: tt.unauthenticated})
+			s := newTestHandlers(t, slack)
+			s.ServerConfigs = configs
+
+			var logs bytes.Buffer
+			ctx := zerolog.New(&logs).WithContext(context.Background())
+			_, err := s.ProcessCommand(ctx, CommandInput{
+				TeamID:    testTeamID,
+				TeamName:  testTeamDomain,
+				UserID:    "UHOST",
+				ChannelID: "C1",
+				Text:      "<@UBOB>",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]bool{}
+			for _, call := range timedCalls(t, &logs) {
+				got[call.Call] = true
+			}
+			for call := range tt.want {
+				if !got[call] {
+					t.Errorf("timed %v, want %s timed", got, call)
+				}
+			}
+			if got["token signing"] && !tt.want["token signing"] {
+				t.Errorf("timed %v, want no token signing without tokens", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package jitsi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var emojiShortcodeRE = regexp.MustCompile(`^:[a-z0-9_+'-]+:$`)
+
+// titlePrefix returns the TitleEmoji followed by a space to lead meeting
+// message titles with, or nothing when it isn't set.
+func (s *SlashCommandHandlers) titlePrefix() string {
+	if s.TitleEmoji == "" {
+		return ""
+	}
+	return s.TitleEmoji + " "
+}
+
+// validateTitleEmoji checks that emoji is a Slack shortcode like
+// :video_camera: or a unicode emoji.
+func validateTitleEmoji(emoji string) error {
+	if emoji == "" || emojiShortcodeRE.MatchString(emoji) {
+		return nil
+	}
+	keycap := strings.ContainsRune(emoji, '\u20e3')
+	for _, r := range emoji {
+		switch {
+		case unicode.Is(unicode.So, r), unicode.Is(unicode.Sk, r):
+		case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r):
+			// variation selectors and keycaps
+		case keycap && strings.ContainsRune("0123456789#*", r):
+			// the key of a keycap emoji like 1️⃣
+		case r == '\u200d':
+			// zero width joiner of emoji sequences
+		default:
+			return fmt.Errorf("title emoji %q must be a shortcode like :video_camera: or a unicode emoji", emoji)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// replyTitle returns the title of the first attachment of a reply.
+func replyTitle(t *testing.T, result CommandResult) string {
+	t.Helper()
+	var reply struct {
+		Attachments []struct {
+			Title string `json:"title"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &reply); err != nil || len(reply.Attachments) == 0 {
+		t.Fatalf("decoding %s: %v", result.Body, err)
+	}
+	return reply.Attachments[0].Title
+}
+
+func TestTitleEmoji(t *testing.T) {
+	tests := []struct {
+		name   string
+		emoji  string
+		prefix string
+	}{
+		{"unset", "", ""},
+		{"shortcode", ":video_camera:", ":video_camera: "},
+		{"unicode", "📹", "📹 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.TitleEmoji = tt.emoji
+
+			titles := map[string]string{
+				"room":     replyTitle(t, processCommand(t, s, "")),
+				"personal": replyTitle(t, processCommand(t, s, "<@UBOB>")),
+				"invite":   inviteTitle(t, slack.Calls("chat.postMessage")[0]),
+			}
+			wants := map[string]string{
+				"room":     "Meeting started ",
+				"personal": "Invitations have been sent",
+				"invite":   "<@UHOST> would like you to join",
+			}
+			for name, title := range titles {
+				if !strings.HasPrefix(title, tt.prefix+wants[name]) {
+					t.Errorf("%s title = %q, want %q followed by %q", name, title, tt.prefix, wants[name])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTitleEmoji(t *testing.T) {
+	tests := []struct {
+		emoji string
+		valid bool
+	}{
+		{"", true},
+		{":video_camera:", true},
+		{":+1:", true},
+		{":man-woman-girl:", true},
+		{"📹", true},
+		{"👍🏽", true},
+		{"👨‍💻", true},
+		{"1️⃣", true},
+		{"#️⃣", true},
+		{"1", false},
+		{"video_camera", false},
+		{":Video Camera:", false},
+		{"::", false},
+		{"Meet", false},
+		{"📹 now", false},
+		{"<!channel>", false},
+	}
+	for _, tt := range tests {
+		if err := validateTitleEmoji(tt.emoji); (err == nil) != tt.valid {
+			t.Errorf("validateTitleEmoji(%q) = %v, want valid %v", tt.emoji, err, tt.valid)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package jitsi
 
 import (
 	"crypto/x509"
+	"strconv"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
@@ -15,27 +16,114 @@ type TokenGenerator struct {
 	Issuer     string
 	Audience   string
 	Kid        string
+	// Leeway backdates the nbf claim so a conference server with a clock
+	// running behind accepts freshly issued tokens.
+	Leeway time.Duration
+	// Subject is the sub claim of every token, i.e. meet.jit.si for
+	// deployments that check it against their domain. It defaults to the
+	// tenant name.
+	Subject string
+	// WildcardRoom sets the room claim to "*" so tokens can join any room
+	// of their tenant, for deployments that prefer tenant-wide tokens.
+	WildcardRoom bool
+	// CustomClaims are added to every token for deployments that need
+	// claims this package doesn't model. Reserved claims such as room, sub
+	// and exp aren't replaced.
+	CustomClaims map[string]interface{}
+}
+
+// wildcardRoomClaim is the room claim that matches every room.
+const wildcardRoomClaim = "*"
+
+// JWTInput is the data used to generate a conference token for a user.
+type JWTInput struct {
+	TenantID   string
+	TenantName string
+	RoomClaim  string
+	UserID     string
+	UserName   string
+	AvatarURL  string
+	// Moderator marks the user as able to moderate the conference,
+	// including admitting participants waiting in the lobby.
+	Moderator bool
+	// Lobby requests that the conference holds non-moderators in a
+	// lobby until they're admitted.
+	Lobby bool
+	// Features enables or disables conference features such as recording.
+	// Features that aren't set are left to the conference service.
+	Features map[string]bool
+	// MaxOccupants limits how many participants can join the conference
+	// for deployments that honor a maxOccupants claim. Zero leaves it to
+	// the conference service.
+	MaxOccupants int
+	// Lifetime replaces the generator's token lifetime when set, i.e. with
+	// a team's meeting duration.
+	Lifetime time.Duration
+	// AppSecret signs the token with HS256 in place of the generator's
+	// private key when set, i.e. with a team's app secret.
+	AppSecret string
+}
+
+// Expiry returns when a token created at now expires.
+func (g TokenGenerator) Expiry(now time.Time) time.Time {
+	return now.Add(g.Lifetime)
 }
 
 // CreateJWT generates conference tokens for auth'ed users.
-func (g TokenGenerator) CreateJWT(tenantID, tenantName, roomClaim, userID, userName, avatarURL string) (string, error) {
+func (g TokenGenerator) CreateJWT(in JWTInput) (string, error) {
+	if err := validateMaxOccupants(in.MaxOccupants); err != nil {
+		return "", err
+	}
 	now := time.Now()
-	exp := now.Add(g.Lifetime)
-	claims := jwt.MapClaims{
-		"iss":  g.Issuer,
-		"nbf":  now.Unix(),
-		"exp":  exp.Unix(),
-		"sub":  tenantName,
-		"aud":  g.Audience,
-		"room": roomClaim,
-		"context": contextClaim{
-			User: userClaim{
-				DisplayName: userName,
-				ID:          userID,
-				AvatarURL:   avatarURL,
-			},
-			Group: tenantName,
+	lifetime := g.Lifetime
+	if in.Lifetime > 0 {
+		lifetime = in.Lifetime
+	}
+	exp := now.Add(lifetime)
+	ctxClaim := contextClaim{
+		User: userClaim{
+			DisplayName: in.UserName,
+			ID:          in.UserID,
+			AvatarURL:   in.AvatarURL,
+			Moderator:   in.Moderator,
 		},
+		Group:        in.TenantName,
+		MaxOccupants: in.MaxOccupants,
+	}
+	if in.Lobby {
+		ctxClaim.Room = &roomSettingsClaim{Lobby: true}
+	}
+	if len(in.Features) > 0 {
+		// Jitsi reads feature flags as strings.
+		ctxClaim.Features = map[string]string{}
+		for name, enabled := range in.Features {
+			ctxClaim.Features[name] = strconv.FormatBool(enabled)
+		}
+	}
+	subject := in.TenantName
+	if g.Subject != "" {
+		subject = g.Subject
+	}
+	roomClaim := in.RoomClaim
+	if g.WildcardRoom {
+		roomClaim = wildcardRoomClaim
+	}
+	claims := jwt.MapClaims{
+		"iss":     g.Issuer,
+		"nbf":     now.Add(-g.Leeway).Unix(),
+		"exp":     exp.Unix(),
+		"sub":     subject,
+		"aud":     g.Audience,
+		"room":    roomClaim,
+		"context": ctxClaim,
+	}
+	for name, value := range g.CustomClaims {
+		if !reservedClaims[name] {
+			claims[name] = value
+		}
+	}
+	if in.AppSecret != "" {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(in.AppSecret))
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 	token.Header["kid"] = g.Kid
@@ -57,9 +145,18 @@ type userClaim struct {
 	ID          string `json:"id"`
 	DisplayName string `json:"name"`
 	AvatarURL   string `json:"avatar"`
+	Moderator   bool   `json:"moderator,omitempty"`
+}
+
+type roomSettingsClaim struct {
+	Lobby bool `json:"lobby"`
 }
 
 type contextClaim struct {
-	User  userClaim `json:"user"`
-	Group string    `json:"group"`
+	User  userClaim          `json:"user"`
+	Group string             `json:"group"`
+	Room  *roomSettingsClaim `json:"room,omitempty"`
+	// Features is keyed by feature name.
+	Features     map[string]string `json:"features,omitempty"`
+	MaxOccupants int               `json:"maxOccupants,omitempty"`
 }
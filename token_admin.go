@@ -0,0 +1,268 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const (
+	slackAuthRevokeURL = "https://slack.com/api/auth.revoke"
+
+	tokensRevoke = "revoke"
+
+	tokensTemplate = `{"response_type":"ephemeral","text":%s,"attachments":[{"text":%s}]}`
+
+	tokensUnsupportedMsg = "Managing stored tokens is not supported by this installation."
+	tokensAdminMsg       = "Only workspace admins can manage stored tokens."
+	tokensNoneMsg        = "No tokens are stored for this workspace."
+	tokensListMsg        = "These tokens are stored for this workspace. Use '%s tokens revoke' to revoke and remove them."
+	tokensRevokedMsg     = "Revoked and removed %d stored tokens. The app needs to be installed again to be used."
+	tokensRevokeFailMsg  = "Removed %d stored tokens but %d couldn't be revoked with Slack, revoke them from the app's settings."
+
+	errTokenRevoked = "token_revoked"
+)
+
+// TokenAdmin provides an interface for listing and removing every token
+// stored for a team.
+type TokenAdmin interface {
+	GetTokenDataForTeam(teamID string) ([]TokenData, error)
+	RemoveTeam(teamID string) error
+}
+
+// GetTokenDataForTeam retrieves all token data stored with the provided team id.
+func (t *TokenStore) GetTokenDataForTeam(teamID string) ([]TokenData, error) {
+	teamIDKey := KeyTeamID
+	queryInput := &dynamodb.QueryInput{
+		TableName:                aws.String(t.TableName),
+		IndexName:                aws.String(fmt.Sprintf("%s-index", KeyTeamID)),
+		ExpressionAttributeNames: map[string]*string{"#teamid": &teamIDKey},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v1": {
+				S: aws.String(teamID),
+			},
+		},
+		KeyConditionExpression: aws.String("#teamid = :v1"),
+	}
+
+	var data []TokenData
+	for {
+		result, err := t.DB.Query(queryInput)
+		if err != nil {
+			return nil, err
+		}
+		var page []TokenData
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, page...)
+		if len(result.LastEvaluatedKey) == 0 {
+			return data, nil
+		}
+		queryInput.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// RemoveTeam removes all token data stored with the provided team id.
+func (t *TokenStore) RemoveTeam(teamID string) error {
+	return t.removeTeamExcept(teamID, "")
+}
+
+// removeTeamExcept removes the token data stored with the provided team id
+// other than that of keepUserID, which is left for a reinstall by the same
+// user to replace since user id is the primary key.
+func (t *TokenStore) removeTeamExcept(teamID, keepUserID string) error {
+	data, err := t.GetTokenDataForTeam(teamID)
+	if err != nil {
+		return err
+	}
+	for _, d := range data {
+		if d.UserID == keepUserID {
+			continue
+		}
+		_, err = t.DB.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName: aws.String(t.TableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				KeyUserID: {
+					S: aws.String(d.UserID),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTokenDataForTeam retrieves the token data stored with the provided team id.
+func (m *MemoryTokenStore) GetTokenDataForTeam(teamID string) ([]TokenData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.teams[teamID]
+	if !ok {
+		return nil, nil
+	}
+	return []TokenData{d}, nil
+}
+
+// RemoveTeam removes the token data stored with the provided team id.
+func (m *MemoryTokenStore) RemoveTeam(teamID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.teams, teamID)
+	return nil
+}
+
+// redactToken keeps only the type prefix and last characters of a token.
+func redactToken(token string) string {
+	if token == "" {
+		return "none"
+	}
+	prefix := ""
+	if i := strings.Index(token, "-"); i > 0 && i < 5 {
+		prefix = token[:i+1]
+	}
+	if len(token) <= 8 {
+		return prefix + "…"
+	}
+	return prefix + "…" + token[len(token)-4:]
+}
+
+// revokeToken revokes a token with Slack. Tokens that are already revoked
+// or invalid are treated as revoked.
+func (s *SlashCommandHandlers) revokeToken(ctx context.Context, token string) error {
+	var revoked struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	err := s.callSlack(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodPost, slackAuthRevokeURL, strings.NewReader(url.Values{"token": {token}}.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := httpClientOrDefault(s.HTTPClient).Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := rateLimited(resp); err != nil {
+			return err
+		}
+		return json.NewDecoder(resp.Body).Decode(&revoked)
+	})
+	if err != nil {
+		return err
+	}
+	switch {
+	case revoked.OK, revoked.Error == errInvalidAuth, revoked.Error == errTokenRevoked, revoked.Error == errInactiveAccount:
+		return nil
+	}
+	return errors.New(revoked.Error)
+}
+
+// tokens lists the tokens stored for a team with their values redacted, or
+// revokes and removes them when asked to. Both are limited to workspace
+// admins.
+func (s *SlashCommandHandlers) tokens(ctx context.Context, client *slack.Client, teamID, userID, text string) (CommandResult, error) {
+	if s.TokenAdmin == nil {
+		return ephemeral(tokensUnsupportedMsg), nil
+	}
+	log := zerolog.Ctx(ctx)
+
+	user, err := s.fetchUserInfo(ctx, client, userID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			return install(s.installURL()), nil
+		default:
+			log.Error().
+				Err(err).
+				Msg("retrieving user info from slack")
+			return CommandResult{}, err
+		}
+	}
+	if !user.IsAdmin && !user.IsOwner {
+		return ephemeral(tokensAdminMsg), nil
+	}
+
+	data, err := s.TokenAdmin.GetTokenDataForTeam(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("listing tokens")
+		return CommandResult{}, err
+	}
+	if len(data) == 0 {
+		return ephemeral(tokensNoneMsg), nil
+	}
+
+	if strings.TrimSpace(text) != tokensRevoke {
+		var lines []string
+		for _, d := range data {
+			line := fmt.Sprintf(
+				"installed by: <@%s> bot user: <@%s> bot token: %s access token: %s",
+				d.UserID,
+				d.BotUserID,
+				redactToken(d.BotToken),
+				redactToken(d.AccessToken),
+			)
+			if d.TokenExpiry != 0 {
+				line += " expires: " + time.Unix(d.TokenExpiry, 0).UTC().Format(time.RFC3339)
+			}
+			lines = append(lines, line)
+		}
+		title, _ := json.Marshal(fmt.Sprintf(tokensListMsg, s.commandName()))
+		list, _ := json.Marshal(strings.Join(lines, "\n"))
+		return CommandResult{Body: fmt.Sprintf(tokensTemplate, title, list)}, nil
+	}
+
+	failed := 0
+	for _, d := range data {
+		for _, token := range []string{d.BotToken, d.AccessToken} {
+			if token == "" {
+				continue
+			}
+			err = s.revokeToken(ctx, token)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("user_id", d.UserID).
+					Msg("revoking token")
+				failed++
+			}
+		}
+	}
+	err = s.TokenAdmin.RemoveTeam(teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("removing tokens")
+		return CommandResult{}, err
+	}
+	if s.UserInfoCache != nil {
+		s.UserInfoCache.InvalidateTeam(teamID)
+	}
+	log.Info().
+		Str("team_id", teamID).
+		Str("user_id", userID).
+		Msg("tokens revoked")
+
+	if failed > 0 {
+		return ephemeral(fmt.Sprintf(tokensRevokeFailMsg, len(data), failed)), nil
+	}
+	return ephemeral(fmt.Sprintf(tokensRevokedMsg, len(data))), nil
+}
@@ -0,0 +1,128 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenDataStore provides an interface for reading and writing the full
+// access token data for a team.
+type TokenDataStore interface {
+	GetFirstTokenDataForTeam(teamID string) (*TokenData, error)
+	Store(data *TokenData) error
+}
+
+type refreshResponse struct {
+	OK           bool   `json:"ok"`
+	Error        string `json:"error"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// TokenRefresher is a TokenReader that exchanges a stored refresh token for
+// a new bot token when the stored bot token is near expiry. Installs without
+// token rotation are passed through untouched.
+type TokenRefresher struct {
+	RefreshURLTemplate string
+	ClientID           string
+	ClientSecret       string
+	// Window is how long before expiry a token is considered stale.
+	Window time.Duration
+	Store  TokenDataStore
+	// HTTPClient is used for the refresh exchange. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// mu guards teams, which serializes refreshes per team. A rotated
+	// refresh token can only be used once, so concurrent refreshes would
+	// fail all but the first with invalid auth.
+	mu    sync.Mutex
+	teams map[string]*sync.Mutex
+}
+
+// GetFirstBotTokenForTeam retrieves the first bot token stored with the
+// provided team id, refreshing it first if it's about to expire.
+func (t *TokenRefresher) GetFirstBotTokenForTeam(teamID string) (string, error) {
+	data, err := t.Store.GetFirstTokenDataForTeam(teamID)
+	if err != nil {
+		return "", err
+	}
+	if t.fresh(data) {
+		return data.BotToken, nil
+	}
+
+	lock := t.teamLock(teamID)
+	lock.Lock()
+	defer lock.Unlock()
+	// Another request may have refreshed the token while this one waited.
+	data, err = t.Store.GetFirstTokenDataForTeam(teamID)
+	if err != nil {
+		return "", err
+	}
+	if t.fresh(data) {
+		return data.BotToken, nil
+	}
+
+	err = t.refresh(data)
+	if err != nil {
+		return "", err
+	}
+	return data.BotToken, nil
+}
+
+// fresh reports whether data doesn't need a refresh, either because the
+// install doesn't rotate tokens or the token isn't near expiry.
+func (t *TokenRefresher) fresh(data *TokenData) bool {
+	if data.RefreshToken == "" {
+		return true
+	}
+	expiry := time.Unix(data.TokenExpiry, 0)
+	return time.Now().Add(t.Window).Before(expiry)
+}
+
+func (t *TokenRefresher) teamLock(teamID string) *sync.Mutex {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.teams == nil {
+		t.teams = map[string]*sync.Mutex{}
+	}
+	lock, ok := t.teams[teamID]
+	if !ok {
+		lock = &sync.Mutex{}
+		t.teams[teamID] = lock
+	}
+	return lock
+}
+
+// refresh exchanges the refresh token held by data and stores the result.
+// A rejected refresh is reported as invalid auth so the caller is prompted
+// to reinstall.
+func (t *TokenRefresher) refresh(data *TokenData) error {
+	resp, err := httpClientOrDefault(t.HTTPClient).Get(fmt.Sprintf(
+		t.RefreshURLTemplate,
+		t.ClientID,
+		t.ClientSecret,
+		data.RefreshToken,
+	))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var refreshed refreshResponse
+	if err := json.NewDecoder(resp.Body).Decode(&refreshed); err != nil {
+		return err
+	}
+	if !refreshed.OK {
+		return errors.New(errInvalidAuth)
+	}
+
+	data.BotToken = refreshed.AccessToken
+	data.RefreshToken = refreshed.RefreshToken
+	data.TokenExpiry = time.Now().Unix() + refreshed.ExpiresIn
+	return t.Store.Store(data)
+}
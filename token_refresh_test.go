@@ -0,0 +1,144 @@
+package jitsi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// refreshServer emulates oauth.v2.access token refreshes, answering with
+// body and counting the refreshes.
+func refreshServer(t *testing.T, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if got := r.URL.Query().Get("refresh_token"); got != "refresh-1" {
+			t.Errorf("refresh_token = %q, want refresh-1", got)
+		}
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func refresherFor(srv *httptest.Server, store TokenDataStore) *TokenRefresher {
+	return &TokenRefresher{
+		RefreshURLTemplate: srv.URL + "/?client_id=%s&client_secret=%s&grant_type=refresh_token&refresh_token=%s",
+		ClientID:           "client",
+		ClientSecret:       "secret",
+		Window:             5 * time.Minute,
+		Store:              store,
+		HTTPClient:         srv.Client(),
+	}
+}
+
+func storeExpiring(t *testing.T, expiry time.Time) *MemoryTokenStore {
+	t.Helper()
+	store := &MemoryTokenStore{}
+	err := store.Store(&TokenData{
+		TeamID:       "T1",
+		UserID:       "U1",
+		BotToken:     "xoxb-old",
+		RefreshToken: "refresh-1",
+		TokenExpiry:  expiry.Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+const refreshedBody = `{"ok":true,"access_token":"xoxb-new","refresh_token":"refresh-2","expires_in":43200}`
+
+func TestTokenRefresherRefreshesExpiredToken(t *testing.T) {
+	srv, calls := refreshServer(t, refreshedBody)
+	store := storeExpiring(t, time.Now().Add(-time.Minute))
+
+	token, err := refresherFor(srv, store).GetFirstBotTokenForTeam("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "xoxb-new" {
+		t.Errorf("token = %q, want xoxb-new", token)
+	}
+	if *calls != 1 {
+		t.Errorf("refreshed %d times, want 1", *calls)
+	}
+	data, _ := store.GetFirstTokenDataForTeam("T1")
+	if data.BotToken != "xoxb-new" || data.RefreshToken != "refresh-2" {
+		t.Errorf("stored %q and %q, want the refreshed tokens", data.BotToken, data.RefreshToken)
+	}
+	if time.Until(time.Unix(data.TokenExpiry, 0)) < 11*time.Hour {
+		t.Errorf("stored expiry %v isn't the refreshed expiry", time.Unix(data.TokenExpiry, 0))
+	}
+}
+
+func TestTokenRefresherKeepsFreshToken(t *testing.T) {
+	srv, calls := refreshServer(t, refreshedBody)
+	store := storeExpiring(t, time.Now().Add(time.Hour))
+
+	token, err := refresherFor(srv, store).GetFirstBotTokenForTeam("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "xoxb-old" || *calls != 0 {
+		t.Errorf("token = %q after %d refreshes, want xoxb-old without a refresh", token, *calls)
+	}
+}
+
+func TestTokenRefresherSerializesRefreshes(t *testing.T) {
+	srv, calls := refreshServer(t, refreshedBody)
+	store := storeExpiring(t, time.Now().Add(-time.Minute))
+	refresher := refresherFor(srv, store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := refresher.GetFirstBotTokenForTeam("T1")
+			if err != nil || token != "xoxb-new" {
+				t.Errorf("token = %q, %v, want xoxb-new", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if *calls != 1 {
+		t.Errorf("refreshed %d times, want 1", *calls)
+	}
+}
+
+func TestTokenRefresherFailurePromptsReinstall(t *testing.T) {
+	srv, _ := refreshServer(t, `{"ok":false,"error":"invalid_refresh_token"}`)
+	store := storeExpiring(t, time.Now().Add(-time.Minute))
+	refresher := refresherFor(srv, store)
+
+	_, err := refresher.GetFirstBotTokenForTeam("T1")
+	if err == nil || err.Error() != errInvalidAuth {
+		t.Fatalf("err = %v, want %s", err, errInvalidAuth)
+	}
+
+	s := &SlashCommandHandlers{
+		ConferenceHost: "https://meet.example.com",
+		TokenReader:    refresher,
+		InstallURL:     "https://slack.com/oauth/v2/authorize?client_id=client",
+	}
+	result, err := s.ProcessCommand(context.Background(), CommandInput{
+		TeamID:   "T1",
+		TeamName: "acme",
+		UserID:   "U1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Body, "Add to Slack") || !strings.Contains(result.Body, s.InstallURL) {
+		t.Errorf("body = %s, want the install prompt", result.Body)
+	}
+}
@@ -3,6 +3,7 @@ package jitsi
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -22,6 +23,15 @@ const (
 	KeyBotUserID = "bot-user-id"
 	// KeyAccessToken is the dynamo ke for storing the access token.
 	KeyAccessToken = "access-token"
+	// KeyRefreshToken is the dynamo key for storing the bot refresh token.
+	KeyRefreshToken = "refresh-token"
+	// KeyTokenExpiry is the dynamo key for storing the bot token expiry as
+	// seconds since the unix epoch.
+	KeyTokenExpiry = "token-expiry"
+	// KeyTeamName is the dynamo key for storing the team name.
+	KeyTeamName = "team-name"
+	// KeyTeamDomain is the dynamo key for storing the team domain.
+	KeyTeamDomain = "team-domain"
 )
 
 // TokenData is the access token data stored from oauth.
@@ -31,6 +41,14 @@ type TokenData struct {
 	BotToken    string `json:"bot-token"`
 	BotUserID   string `json:"bot-user-id"`
 	AccessToken string `json:"access-token"`
+	// RefreshToken and TokenExpiry are only set for installs with
+	// token rotation enabled.
+	RefreshToken string `json:"refresh-token"`
+	TokenExpiry  int64  `json:"token-expiry"`
+	// TeamName and TeamDomain are the team's name and domain at install
+	// time. They're empty for installs stored before they were recorded.
+	TeamName   string `json:"team-name"`
+	TeamDomain string `json:"team-domain"`
 }
 
 // TokenStore stores and retrieves access tokens from aws dynamodb.
@@ -41,6 +59,15 @@ type TokenStore struct {
 
 // GetFirstBotTokenForTeam retrieves the first bot token stored with the provided team id.
 func (t *TokenStore) GetFirstBotTokenForTeam(teamID string) (string, error) {
+	d, err := t.GetFirstTokenDataForTeam(teamID)
+	if err != nil {
+		return "", err
+	}
+	return d.BotToken, nil
+}
+
+// GetFirstTokenDataForTeam retrieves the first token data stored with the provided team id.
+func (t *TokenStore) GetFirstTokenDataForTeam(teamID string) (*TokenData, error) {
 	teamIDKey := KeyTeamID
 	queryLimit := int64(1)
 	queryInput := &dynamodb.QueryInput{
@@ -57,22 +84,23 @@ func (t *TokenStore) GetFirstBotTokenForTeam(teamID string) (string, error) {
 	}
 	result, err := t.DB.Query(queryInput)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if len(result.Items) < 1 {
-		return "", errors.New(errMissingAuthToken)
+		return nil, errors.New(errMissingAuthToken)
 	}
 
 	d := TokenData{}
 	err = dynamodbattribute.UnmarshalMap(result.Items[0], &d)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return d.BotToken, nil
+	return &d, nil
 }
 
-// Store will store access token data.
+// Store will store access token data, replacing any stored for the team so
+// a reinstall doesn't leave stale tokens to be looked up.
 func (t *TokenStore) Store(data *TokenData) error {
 	input := &dynamodb.PutItemInput{
 		Item: map[string]*dynamodb.AttributeValue{
@@ -94,10 +122,28 @@ func (t *TokenStore) Store(data *TokenData) error {
 		},
 		TableName: aws.String(t.TableName),
 	}
+	if data.RefreshToken != "" {
+		input.Item[KeyRefreshToken] = &dynamodb.AttributeValue{
+			S: aws.String(data.RefreshToken),
+		}
+		input.Item[KeyTokenExpiry] = &dynamodb.AttributeValue{
+			N: aws.String(strconv.FormatInt(data.TokenExpiry, 10)),
+		}
+	}
+	if data.TeamName != "" {
+		input.Item[KeyTeamName] = &dynamodb.AttributeValue{
+			S: aws.String(data.TeamName),
+		}
+	}
+	if data.TeamDomain != "" {
+		input.Item[KeyTeamDomain] = &dynamodb.AttributeValue{
+			S: aws.String(data.TeamDomain),
+		}
+	}
 
 	_, err := t.DB.PutItem(input)
 	if err != nil {
 		return err
 	}
-	return nil
+	return t.removeTeamExcept(data.TeamID, data.UserID)
 }
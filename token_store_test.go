@@ -0,0 +1,66 @@
+package jitsi
+
+import (
+	"testing"
+)
+
+func TestTokenStoreReinstall(t *testing.T) {
+	tests := []struct {
+		name      string
+		reinstall TokenData
+	}{
+		{"same user", TokenData{TeamID: "T1", UserID: "U1", BotToken: "xoxb-new"}},
+		{"other user", TokenData{TeamID: "T1", UserID: "U2", BotToken: "xoxb-new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db := newFakeDynamo(t)
+			store := &TokenStore{TableName: "tokens", DB: db}
+			for _, d := range []TokenData{
+				{TeamID: "T1", UserID: "U1", BotToken: "xoxb-old"},
+				{TeamID: "T2", UserID: "U3", BotToken: "xoxb-other"},
+				tt.reinstall,
+			} {
+				d := d
+				if err := store.Store(&d); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := store.GetTokenDataForTeam("T1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != 1 || data[0].UserID != tt.reinstall.UserID || data[0].BotToken != "xoxb-new" {
+				t.Errorf("stored %+v, want only the reinstall", data)
+			}
+			if token, err := store.GetFirstBotTokenForTeam("T1"); err != nil || token != "xoxb-new" {
+				t.Errorf("GetFirstBotTokenForTeam = %q, %v, want xoxb-new", token, err)
+			}
+			if token, err := store.GetFirstBotTokenForTeam("T2"); err != nil || token != "xoxb-other" {
+				t.Errorf("other team's token = %q, %v, want it left alone", token, err)
+			}
+		})
+	}
+}
+
+func TestMemoryTokenStoreReinstall(t *testing.T) {
+	store := &MemoryTokenStore{}
+	for _, d := range []TokenData{
+		{TeamID: "T1", UserID: "U1", BotToken: "xoxb-old"},
+		{TeamID: "T1", UserID: "U2", BotToken: "xoxb-new"},
+	} {
+		d := d
+		if err := store.Store(&d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := store.GetTokenDataForTeam("T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].BotToken != "xoxb-new" {
+		t.Errorf("stored %+v, want only the reinstall", data)
+	}
+}
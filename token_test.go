@@ -0,0 +1,216 @@
+package jitsi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// tokenClaims returns the claims of the token in a meeting url.
+func tokenClaims(t *testing.T, meetingURL string) jwt.MapClaims {
+	t.Helper()
+	u, err := url.Parse(meetingURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(u.Query().Get("jwt"), claims); err != nil {
+		t.Fatalf("parsing token of %s: %v", meetingURL, err)
+	}
+	return claims
+}
+
+// contextOf decodes the context claim of a token.
+func contextOf(t *testing.T, claims jwt.MapClaims) contextClaim {
+	t.Helper()
+	b, _ := json.Marshal(claims["context"])
+	var ctxClaim contextClaim
+	if err := json.Unmarshal(b, &ctxClaim); err != nil {
+		t.Fatal(err)
+	}
+	return ctxClaim
+}
+
+// testTokenGenerator returns a generator signing with the test key.
+func testTokenGenerator(t *testing.T) TokenGenerator {
+	t.Helper()
+	return TokenGenerator{
+		Lifetime:   time.Hour,
+		PrivateKey: testSigningKey(t),
+		Issuer:     "test",
+		Audience:   "test",
+		Kid:        "test",
+	}
+}
+
+// createTestJWT creates a token with g and returns its claims.
+func createTestJWT(t *testing.T, g TokenGenerator, in JWTInput) jwt.MapClaims {
+	t.Helper()
+	token, err := g.CreateJWT(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenClaims(t, "https://meet.example.com/acme/room?jwt="+token)
+}
+
+func TestLobbyClaims(t *testing.T) {
+	g := testTokenGenerator(t)
+	in := JWTInput{TenantName: "acme", RoomClaim: "room", UserID: "UHOST", Lobby: true}
+	guest := contextOf(t, createTestJWT(t, g, in))
+	in.Moderator = true
+	host := contextOf(t, createTestJWT(t, g, in))
+
+	if guest.Room == nil || !guest.Room.Lobby || host.Room == nil || !host.Room.Lobby {
+		t.Errorf("rooms = %+v, %+v, want the lobby enabled for both", guest.Room, host.Room)
+	}
+	if guest.User.Moderator || !host.User.Moderator {
+		t.Errorf("moderator = %v for the guest and %v for the host, want only the host", guest.User.Moderator, host.User.Moderator)
+	}
+
+	plain := contextOf(t, createTestJWT(t, g, JWTInput{TenantName: "acme", RoomClaim: "room"}))
+	if plain.Room != nil || plain.User.Moderator {
+		t.Errorf("context = %+v, want no lobby or moderator without a lobby", plain)
+	}
+}
+
+func TestWildcardRoomClaim(t *testing.T) {
+	tests := []struct {
+		name     string
+		wildcard bool
+		want     string
+	}{
+		{"room", false, "BraveTiger"},
+		{"wildcard", true, "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testTokenGenerator(t)
+			g.WildcardRoom = tt.wildcard
+			claims := createTestJWT(t, g, JWTInput{TenantName: "acme", RoomClaim: "BraveTiger", UserID: "UHOST"})
+			if claims["room"] != tt.want {
+				t.Errorf("room claim = %v, want %q", claims["room"], tt.want)
+			}
+		})
+	}
+}
+
+func TestWildcardRoomClaimInMeetingURL(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	g := testTokenGenerator(t)
+	g.WildcardRoom = true
+	s.TokenGenerator = g
+
+	result := processCommand(t, s, "<@UBOB>")
+	host := hostURL(t, result)
+	// The url still leads to the meeting's room.
+	if !strings.HasPrefix(host, testConfHost+"/acme/"+result.Room+"?jwt=") {
+		t.Errorf("host url = %s, want the room", host)
+	}
+	if room := tokenClaims(t, host)["room"]; room != "*" {
+		t.Errorf("room claim = %v, want *", room)
+	}
+	if room := tokenClaims(t, inviteURL(t, slack.Calls("chat.postMessage")[0]))["room"]; room != "*" {
+		t.Errorf("invite room claim = %v, want *", room)
+	}
+}
+
+func TestSubjectClaim(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		tenant  string
+		want    string
+	}{
+		{"tenant", "", "acme", "acme"},
+		{"other tenant", "", "globex", "globex"},
+		{"fixed", "meet.jit.si", "acme", "meet.jit.si"},
+		{"fixed for other tenant", "meet.jit.si", "globex", "meet.jit.si"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testTokenGenerator(t)
+			g.Subject = tt.subject
+			claims := createTestJWT(t, g, JWTInput{TenantName: tt.tenant, RoomClaim: "BraveTiger", UserID: "UHOST"})
+			if claims["sub"] != tt.want {
+				t.Errorf("sub claim = %v, want %q", claims["sub"], tt.want)
+			}
+		})
+	}
+}
+
+func TestSubjectClaimForTeams(t *testing.T) {
+	for name, subject := range map[string]string{"tenant": "", "fixed": "meet.jit.si"} {
+		t.Run(name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			slack.AddUser("UBOB", "bob")
+			s := newTestHandlers(t, slack)
+			s.TokenReader.(*MemoryTokenStore).Store(&TokenData{
+				TeamID:     "T2",
+				UserID:     "UHOST",
+				BotToken:   testBotToken,
+				TeamDomain: "globex",
+			})
+			g := testTokenGenerator(t)
+			g.Subject = subject
+			s.TokenGenerator = g
+
+			for team, domain := range map[string]string{testTeamID: "acme", "T2": "globex"} {
+				result, err := s.ProcessCommand(context.Background(), CommandInput{
+					TeamID:    team,
+					TeamName:  domain,
+					UserID:    "UHOST",
+					ChannelID: "C1",
+					Text:      "<@UBOB>",
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := subject
+				if want == "" {
+					want = domain
+				}
+				if got := tokenClaims(t, hostURL(t, result))["sub"]; got != want {
+					t.Errorf("%s sub claim = %v, want %q", team, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLeewayBackdatesNotBefore(t *testing.T) {
+	tests := []struct {
+		name   string
+		leeway time.Duration
+	}{
+		{"none", 0},
+		{"seconds", 30 * time.Second},
+		{"minutes", 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testTokenGenerator(t)
+			g.Leeway = tt.leeway
+			before := time.Now().Unix()
+			claims := createTestJWT(t, g, JWTInput{TenantName: "acme", RoomClaim: "BraveTiger", UserID: "UHOST"})
+			after := time.Now().Unix()
+
+			nbf := int64(claims["nbf"].(float64))
+			leeway := int64(tt.leeway / time.Second)
+			if nbf < before-leeway || nbf > after-leeway {
+				t.Errorf("nbf = %d, want %ds before issuing at %d-%d", nbf, leeway, before, after)
+			}
+			// The leeway doesn't extend the token's lifetime.
+			exp := int64(claims["exp"].(float64))
+			if exp < before+3600 || exp > after+3600 {
+				t.Errorf("exp = %d, want an hour after issuing at %d-%d", exp, before, after)
+			}
+		})
+	}
+}
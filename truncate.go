@@ -0,0 +1,21 @@
+package jitsi
+
+import "unicode/utf8"
+
+const (
+	// maxTitleLength is the most characters Slack shows of a header or
+	// title.
+	maxTitleLength = 150
+	// maxTextLength is the most characters Slack accepts in a text object.
+	maxTextLength = 3000
+)
+
+// truncate shortens text to at most max characters, ending it with an
+// ellipsis when it was cut.
+func truncate(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,63 @@
+package jitsi
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+	}{
+		{"short", "hello", 10, "hello"},
+		{"at max", "hello", 5, "hello"},
+		{"over max", "hello world", 8, "hello w…"},
+		{"multibyte", "héllo wörld", 8, "héllo w…"},
+		{"emoji", "📹📹📹📹", 3, "📹📹…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.text, tt.max)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) = %q, want valid utf-8", tt.text, tt.max, got)
+			}
+		})
+	}
+}
+
+func TestInviteTitleTruncated(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.InviteText = "{{.Host}} invites you to " + strings.Repeat("a very long meeting ", 20)
+
+	processCommand(t, s, "<@UBOB>")
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want an invite", len(posted))
+	}
+	title := inviteTitle(t, posted[0])
+	if n := utf8.RuneCountInString(title); n != maxTitleLength || !strings.HasSuffix(title, "…") {
+		t.Errorf("title has %d characters %q, want it cut to %d with an ellipsis", n, title, maxTitleLength)
+	}
+	if !strings.HasPrefix(title, "<@UHOST> invites you to a very long meeting") {
+		t.Errorf("title = %q, want the start of the invite text", title)
+	}
+}
+
+func TestRenderTextTruncatesWithEllipsis(t *testing.T) {
+	got, err := renderText("{{.Host}} "+strings.Repeat("é", maxTextLength), "U1", testConfHost, "room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := utf8.RuneCountInString(got); n != maxTextLength || !strings.HasSuffix(got, "…") || !strings.HasPrefix(got, "<@U1> ") {
+		t.Errorf("rendered %d characters ending %q, want %d ending with an ellipsis", n, got[len(got)-8:], maxTextLength)
+	}
+}
@@ -0,0 +1,61 @@
+package jitsi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultMaxURLLength is the longest authenticated meeting url kept as is
+// when MaxURLLength isn't set. Some clients and proxies refuse urls longer
+// than about 2000 characters.
+const defaultMaxURLLength = 2000
+
+func (s *SlashCommandHandlers) maxURLLength() int {
+	if s.MaxURLLength == 0 {
+		return defaultMaxURLLength
+	}
+	return s.MaxURLLength
+}
+
+// conferenceURL returns the url of a room on the team's conference host
+// with a token for in, or without one when the team has turned
+// authenticated urls off. A url over the max length is made again with a
+// token without the avatar, which is the claim most likely to be long. Urls
+// still over the max length are returned since the url shortener can make
+// them usable.
+func (s *SlashCommandHandlers) conferenceURL(ctx context.Context, serverCfg ServerConfig, in JWTInput) (string, error) {
+	confHost := serverCfg.ConferenceHost
+	if !serverCfg.authenticatedURLs() {
+		return roomURL(confHost, in.TenantName, in.RoomClaim), nil
+	}
+	token, err := s.createJWT(ctx, in)
+	if err != nil {
+		return "", err
+	}
+	confURL := fmt.Sprintf("%s/%s/%s?jwt=%s", confHost, in.TenantName, in.RoomClaim, token)
+	if len(confURL) <= s.maxURLLength() {
+		return confURL, nil
+	}
+
+	log := zerolog.Ctx(ctx)
+	if in.AvatarURL != "" {
+		log.Warn().
+			Int("length", len(confURL)).
+			Msg("meeting url too long, dropping avatar from token")
+		in.AvatarURL = ""
+		token, err = s.createJWT(ctx, in)
+		if err != nil {
+			return "", err
+		}
+		confURL = fmt.Sprintf("%s/%s/%s?jwt=%s", confHost, in.TenantName, in.RoomClaim, token)
+	}
+	if len(confURL) > s.maxURLLength() {
+		log.Warn().
+			Int("length", len(confURL)).
+			Bool("shortened", s.URLShortener != nil).
+			Msg("meeting url too long")
+	}
+	return confURL, nil
+}
@@ -0,0 +1,97 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// warnings decodes the warning messages logged to logs.
+func warnings(t *testing.T, logs *bytes.Buffer) []string {
+	t.Helper()
+	var msgs []string
+	dec := json.NewDecoder(logs)
+	for dec.More() {
+		var line struct {
+			Level   string `json:"level"`
+			Message string `json:"message"`
+		}
+		if err := dec.Decode(&line); err != nil {
+			t.Fatal(err)
+		}
+		if line.Level == "warn" {
+			msgs = append(msgs, line.Message)
+		}
+	}
+	return msgs
+}
+
+func TestConferenceURLLength(t *testing.T) {
+	longAvatar := "https://avatars.example.com/" + strings.Repeat("a", 3000) + ".png"
+	tests := []struct {
+		name       string
+		max        int
+		avatar     string
+		claims     map[string]interface{}
+		wantAvatar bool
+		warnings   []string
+	}{
+		{"short", 0, "https://avatars.example.com/UBOB.png", nil, true, nil},
+		{"long avatar", 0, longAvatar, nil, false, []string{"meeting url too long, dropping avatar from token"}},
+		{"lower limit", 1000, "https://avatars.example.com/" + strings.Repeat("a", 400) + ".png", nil, false, []string{"meeting url too long, dropping avatar from token"}},
+		{"raised limit", 5000, longAvatar, nil, true, nil},
+		{"still too long", 0, longAvatar, map[string]interface{}{"notes": strings.Repeat("n", 3000)}, false, []string{"meeting url too long, dropping avatar from token", "meeting url too long"}},
+		{"too long without avatar", 0, "", map[string]interface{}{"notes": strings.Repeat("n", 3000)}, false, []string{"meeting url too long"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHandlers(t, newFakeSlack(t))
+			s.MaxURLLength = tt.max
+			g := testTokenGenerator(t)
+			g.CustomClaims = tt.claims
+			s.TokenGenerator = g
+
+			var logs bytes.Buffer
+			ctx := zerolog.New(&logs).WithContext(context.Background())
+			confURL, err := s.conferenceURL(ctx, ServerConfig{ConferenceHost: testConfHost}, JWTInput{TenantName: "acme", RoomClaim: "BraveTiger", UserID: "UBOB", UserName: "bob", AvatarURL: tt.avatar})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(confURL, testConfHost+"/acme/BraveTiger?jwt=") {
+				t.Errorf("url = %s, want the room with a token", confURL)
+			}
+			got := contextOf(t, tokenClaims(t, confURL)).User
+			if hasAvatar := got.AvatarURL != ""; hasAvatar != tt.wantAvatar {
+				t.Errorf("avatar claim = %q, want it kept %v", got.AvatarURL, tt.wantAvatar)
+			}
+			if got.DisplayName != "bob" {
+				t.Errorf("name claim = %q, want the rest of the token kept", got.DisplayName)
+			}
+			if msgs := warnings(t, &logs); strings.Join(msgs, "\n") != strings.Join(tt.warnings, "\n") {
+				t.Errorf("warned %q, want %q", msgs, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestInviteWithLongAvatarFallsBack(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Users["UBOB"] = `{"ok":true,"user":{"id":"UBOB","name":"bob","profile":{"display_name":"bob","image_192":"https://avatars.example.com/` + strings.Repeat("a", 3000) + `.png"}}}`
+	s := newTestHandlers(t, slack)
+
+	result := processCommand(t, s, "<@UBOB>")
+	invite := inviteURL(t, slack.Calls("chat.postMessage")[0])
+	if len(invite) > defaultMaxURLLength {
+		t.Errorf("invite url has %d characters, want at most %d", len(invite), defaultMaxURLLength)
+	}
+	if avatar := contextOf(t, tokenClaims(t, invite)).User.AvatarURL; avatar != "" {
+		t.Errorf("invite avatar claim = %q, want it dropped", avatar)
+	}
+	if avatar := contextOf(t, tokenClaims(t, hostURL(t, result))).User.AvatarURL; avatar != "https://avatars.example.com/UHOST.png" {
+		t.Errorf("host avatar claim = %q, want the host's short url kept", avatar)
+	}
+}
@@ -0,0 +1,30 @@
+package jitsi
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// URLShortener provides an interface for shortening meeting urls before
+// they're shown to users. Short urls must redirect to the full url,
+// including its jwt query parameter.
+type URLShortener interface {
+	Shorten(ctx context.Context, longURL string) (string, error)
+}
+
+// shorten shortens a meeting url with the URLShortener when one is
+// configured. The full url is used when shortening fails.
+func (s *SlashCommandHandlers) shorten(ctx context.Context, longURL string) string {
+	if s.URLShortener == nil {
+		return longURL
+	}
+	shortURL, err := s.URLShortener.Shorten(ctx, longURL)
+	if err != nil || shortURL == "" {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("shortening meeting url")
+		return longURL
+	}
+	return shortURL
+}
@@ -0,0 +1,95 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// mockShortener shortens urls to numbered links it can resolve.
+type mockShortener struct {
+	mu    sync.Mutex
+	links map[string]string
+	err   error
+}
+
+func (m *mockShortener) Shorten(ctx context.Context, longURL string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return "", m.err
+	}
+	if m.links == nil {
+		m.links = map[string]string{}
+	}
+	shortURL := fmt.Sprintf("https://short.example.com/%d", len(m.links))
+	m.links[shortURL] = longURL
+	return shortURL, nil
+}
+
+// resolve returns the url a short link redirects to.
+func (m *mockShortener) resolve(t *testing.T, shortURL string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	longURL, ok := m.links[shortURL]
+	if !ok {
+		t.Fatalf("%s isn't a short link", shortURL)
+	}
+	return longURL
+}
+
+func TestShortenedMeetingURLs(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	shortener := &mockShortener{}
+	s := newTestHandlers(t, slack)
+	s.URLShortener = shortener
+
+	result := processCommand(t, s, "<@UBOB>")
+	links := map[string]string{"host": hostURL(t, result)}
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 {
+		t.Fatalf("chat.postMessage calls = %d, want an invite", len(posted))
+	}
+	links["invite"] = inviteURL(t, posted[0])
+	for name, link := range links {
+		if !strings.HasPrefix(link, "https://short.example.com/") {
+			t.Errorf("%s link = %s, want a short link", name, link)
+			continue
+		}
+		// The short link leads to the room with its token.
+		longURL := shortener.resolve(t, link)
+		if !strings.HasPrefix(longURL, testConfHost+"/acme/"+result.Room+"?jwt=") {
+			t.Errorf("%s link resolves to %s, want the room with a jwt", name, longURL)
+		}
+	}
+	if tokenClaims(t, shortener.resolve(t, links["invite"]))["sub"] == nil {
+		t.Errorf("invite link resolves to a url without a valid token")
+	}
+}
+
+func TestShortenerFailureUsesFullURL(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.AddUser("UBOB", "bob")
+	s := newTestHandlers(t, slack)
+	s.URLShortener = &mockShortener{err: errors.New("shortener down")}
+
+	result := processCommand(t, s, "<@UBOB>")
+	if got := hostURL(t, result); !strings.HasPrefix(got, testConfHost+"/acme/"+result.Room+"?jwt=") {
+		t.Errorf("host url = %s, want the full url", got)
+	}
+	if got := inviteURL(t, slack.Calls("chat.postMessage")[0]); !strings.HasPrefix(got, testConfHost+"/acme/"+result.Room+"?jwt=") {
+		t.Errorf("invite url = %s, want the full url", got)
+	}
+}
+
+func TestNoShortener(t *testing.T) {
+	s := &SlashCommandHandlers{}
+	if got := s.shorten(context.Background(), testConfHost+"/acme/Room"); got != testConfHost+"/acme/Room" {
+		t.Errorf("shorten = %s, want the url unchanged", got)
+	}
+}
@@ -0,0 +1,146 @@
+package jitsi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nlopes/slack"
+)
+
+// UserInfoCache provides an interface for caching Slack user info lookups.
+type UserInfoCache interface {
+	Get(teamID, userID string) (*slack.User, bool)
+	Set(teamID, userID string, user *slack.User)
+	// InvalidateTeam drops all cached users for a team.
+	InvalidateTeam(teamID string)
+}
+
+type cachedUser struct {
+	user    *slack.User
+	expires time.Time
+}
+
+// TTLUserInfoCache is an in memory UserInfoCache that expires entries after TTL.
+type TTLUserInfoCache struct {
+	TTL time.Duration
+
+	mu    sync.Mutex
+	teams map[string]map[string]cachedUser
+}
+
+// Get returns the cached user info for a team's user if it hasn't expired.
+func (c *TTLUserInfoCache) Get(teamID, userID string) (*slack.User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.teams[teamID][userID]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(cached.expires) {
+		delete(c.teams[teamID], userID)
+		return nil, false
+	}
+	return cached.user, true
+}
+
+// Set caches the user info for a team's user.
+func (c *TTLUserInfoCache) Set(teamID, userID string, user *slack.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.teams == nil {
+		c.teams = map[string]map[string]cachedUser{}
+	}
+	if c.teams[teamID] == nil {
+		c.teams[teamID] = map[string]cachedUser{}
+	}
+	c.teams[teamID][userID] = cachedUser{
+		user:    user,
+		expires: time.Now().Add(c.TTL),
+	}
+}
+
+// InvalidateTeam drops all cached users for a team.
+func (c *TTLUserInfoCache) InvalidateTeam(teamID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.teams, teamID)
+}
+
+// userInfo looks up a user's info, using the UserInfoCache when one is
+// configured. Cached entries for the team are dropped on auth errors since
+// they may have been fetched under a revoked install.
+func (s *SlashCommandHandlers) userInfo(ctx context.Context, client *slack.Client, teamID, userID string) (*slack.User, error) {
+	if s.UserInfoCache == nil {
+		return s.fetchUserInfo(ctx, client, userID)
+	}
+
+	if user, ok := s.UserInfoCache.Get(teamID, userID); ok {
+		return user, nil
+	}
+	user, err := s.fetchUserInfo(ctx, client, userID)
+	if err != nil {
+		switch err.Error() {
+		case errInvalidAuth, errInactiveAccount, errMissingAuthToken:
+			s.UserInfoCache.InvalidateTeam(teamID)
+		}
+		return nil, err
+	}
+	s.UserInfoCache.Set(teamID, userID, user)
+	return user, nil
+}
+
+// fetchUserInfo looks up a user's info from Slack, bypassing the
+// UserInfoCache. Authorization checks use it so a user who loses their
+// admin role can't keep acting as an admin until their cached info expires.
+func (s *SlashCommandHandlers) fetchUserInfo(ctx context.Context, client *slack.Client, userID string) (*slack.User, error) {
+	var user *slack.User
+	err := s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = client.GetUserInfoContext(ctx, userID)
+		return err
+	})
+	return user, err
+}
+
+// userPresence looks up a user's info along with their presence. Presence
+// is cached with the rest of the user info when a UserInfoCache is
+// configured.
+func (s *SlashCommandHandlers) userPresence(ctx context.Context, client *slack.Client, teamID, userID string) (*slack.User, error) {
+	user, err := s.userInfo(ctx, client, teamID, userID)
+	if err != nil || user.Presence != "" {
+		return user, err
+	}
+
+	var presence *slack.UserPresence
+	err = s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		presence, err = client.GetUserPresenceContext(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	withPresence := *user
+	withPresence.Presence = presence.Presence
+	if s.UserInfoCache != nil {
+		s.UserInfoCache.Set(teamID, userID, &withPresence)
+	}
+	return &withPresence, nil
+}
+
+// activeUsers splits users into those who are active and those who are away.
+func (s *SlashCommandHandlers) activeUsers(ctx context.Context, client *slack.Client, teamID string, userIDs []string) (active, away []string, err error) {
+	for _, userID := range userIDs {
+		user, err := s.userPresence(ctx, client, teamID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if user.Presence == "active" {
+			active = append(active, userID)
+		} else {
+			away = append(away, userID)
+		}
+	}
+	return active, away, nil
+}
@@ -0,0 +1,95 @@
+package jitsi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nlopes/slack"
+)
+
+func TestUserInfoCacheHit(t *testing.T) {
+	fake := newFakeSlack(t)
+	s := newTestHandlers(t, fake)
+	s.UserInfoCache = &TTLUserInfoCache{TTL: time.Hour}
+	client := slack.New(testBotToken, slack.OptionHTTPClient(fake.Client()))
+
+	for i := 0; i < 2; i++ {
+		user, err := s.userInfo(context.Background(), client, testTeamID, "UHOST")
+		if err != nil || user.Name != "host" {
+			t.Fatalf("userInfo = %+v, %v, want host", user, err)
+		}
+	}
+	if calls := fake.Calls("users.info"); len(calls) != 1 {
+		t.Errorf("users.info calls = %d, want 1 with the second lookup cached", len(calls))
+	}
+}
+
+func TestUserInfoCacheExpires(t *testing.T) {
+	fake := newFakeSlack(t)
+	s := newTestHandlers(t, fake)
+	s.UserInfoCache = &TTLUserInfoCache{TTL: 10 * time.Millisecond}
+	client := slack.New(testBotToken, slack.OptionHTTPClient(fake.Client()))
+
+	s.userInfo(context.Background(), client, testTeamID, "UHOST")
+	time.Sleep(20 * time.Millisecond)
+	s.userInfo(context.Background(), client, testTeamID, "UHOST")
+	if calls := fake.Calls("users.info"); len(calls) != 2 {
+		t.Errorf("users.info calls = %d, want 2 after the ttl", len(calls))
+	}
+}
+
+func TestUserInfoCacheInvalidatedOnAuthError(t *testing.T) {
+	fake := newFakeSlack(t)
+	fake.Handle("users.info", `{"ok":false,"error":"invalid_auth"}`)
+	cache := &TTLUserInfoCache{TTL: time.Hour}
+	cache.Set(testTeamID, "UBOB", &slack.User{ID: "UBOB"})
+	s := newTestHandlers(t, fake)
+	s.UserInfoCache = cache
+	client := slack.New(testBotToken, slack.OptionHTTPClient(fake.Client()))
+
+	if _, err := s.userInfo(context.Background(), client, testTeamID, "UHOST"); err == nil {
+		t.Fatal("userInfo succeeded, want invalid_auth")
+	}
+	if _, ok := cache.Get(testTeamID, "UBOB"); ok {
+		t.Error("the team's cached users were kept after an auth error")
+	}
+}
+
+func TestAdminChecksBypassUserInfoCache(t *testing.T) {
+	fake := newFakeSlack(t)
+	cache := &TTLUserInfoCache{TTL: time.Hour}
+	cache.Set(testTeamID, "UHOST", &slack.User{ID: "UHOST", Name: "host", IsAdmin: true})
+	s := newTestHandlers(t, fake)
+	s.UserInfoCache = cache
+	s.ServerConfigs = &MemoryServerConfigStore{}
+
+	_, got := responseOf(t, processCommand(t, s, "set-duration 45m"))
+	if got != setDurationAdminMsg {
+		t.Errorf("reply = %q, want %q for a user no longer an admin", got, setDurationAdminMsg)
+	}
+	if calls := fake.Calls("users.info"); len(calls) != 1 {
+		t.Errorf("users.info calls = %d, want the admin check to ask Slack", len(calls))
+	}
+}
+
+func TestUserPresenceCached(t *testing.T) {
+	fake := newFakeSlack(t)
+	fake.Presence["UHOST"] = "active"
+	s := newTestHandlers(t, fake)
+	s.UserInfoCache = &TTLUserInfoCache{TTL: time.Hour}
+	client := slack.New(testBotToken, slack.OptionHTTPClient(fake.Client()))
+
+	for i := 0; i < 2; i++ {
+		user, err := s.userPresence(context.Background(), client, testTeamID, "UHOST")
+		if err != nil || user.Presence != "active" {
+			t.Fatalf("userPresence = %+v, %v, want active", user, err)
+		}
+	}
+	if calls := fake.Calls("users.getPresence"); len(calls) != 1 {
+		t.Errorf("users.getPresence calls = %d, want 1 with the second lookup cached", len(calls))
+	}
+	if calls := fake.Calls("users.info"); len(calls) != 1 {
+		t.Errorf("users.info calls = %d, want 1 with the second lookup cached", len(calls))
+	}
+}
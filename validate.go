@@ -0,0 +1,150 @@
+package jitsi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// validateURL checks that raw is an absolute http(s) url.
+func validateURL(name, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid url: %v", name, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute http(s) url: %s", name, raw)
+	}
+	return nil
+}
+
+// NewSlashCommandHandlers returns slash command handlers with the
+// configuration of cfg, or the error from Validate when it's misconfigured.
+func NewSlashCommandHandlers(cfg SlashCommandHandlers) (*SlashCommandHandlers, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks that the slash command handlers are configured well
+// enough to serve requests so misconfiguration fails at startup.
+func (s *SlashCommandHandlers) Validate() error {
+	if err := validateURL("conference host", s.ConferenceHost); err != nil {
+		return err
+	}
+	if s.TokenGenerator == nil {
+		return errors.New("token generator is required")
+	}
+	// Sign a throwaway token so a bad signing key fails at startup rather
+	// than on the first invite.
+	_, err := s.TokenGenerator.CreateJWT(JWTInput{
+		TenantID:   "validate",
+		TenantName: "validate",
+		RoomClaim:  "validate",
+	})
+	if err != nil {
+		return fmt.Errorf("token generator can't sign tokens: %v", err)
+	}
+	if s.InsecureSkipVerification && !signingBypassAvailable {
+		return errors.New("skipping signature verification requires a build with the devsigning tag")
+	}
+	if s.SlackSigningSecret == "" {
+		return errors.New("slack signing secret is required")
+	}
+	if s.TokenReader == nil {
+		return errors.New("token reader is required")
+	}
+	if s.installURL() == "" {
+		return errors.New("an install or sharable url is required")
+	}
+	if err := validateURL("install url", s.installURL()); err != nil {
+		return err
+	}
+	if s.ConfirmChannelSize < 0 {
+		return errors.New("confirm channel size can't be negative")
+	}
+	if s.SlackRetry.Timeout >= slackResponseTimeout {
+		return fmt.Errorf("slack call timeout must be less than the %v Slack waits for a response", slackResponseTimeout)
+	}
+	if s.MaxChannelInvitees < 0 {
+		return errors.New("max channel invitees can't be negative")
+	}
+	if s.Workers != nil && (s.Workers.Size <= 0 || s.Workers.Queue < 0) {
+		return errors.New("workers need a positive size and a queue that isn't negative")
+	}
+	if s.MaxURLLength < 0 {
+		return errors.New("max url length can't be negative")
+	}
+	if err := validateMaxOccupants(s.MaxOccupants); err != nil {
+		return err
+	}
+	if s.GroupInviteLimit < 0 || s.GroupInviteLimit > maxGroupInvitees {
+		return fmt.Errorf("group invite limit must be between 0 and %d", maxGroupInvitees)
+	}
+	if err := validateNameField(s.NameField); err != nil {
+		return err
+	}
+	if err := s.validateInviteText(); err != nil {
+		return err
+	}
+	if err := validateTitleEmoji(s.TitleEmoji); err != nil {
+		return err
+	}
+	if err := s.Fallbacks.validate(); err != nil {
+		return err
+	}
+	if err := validateRoomAffix("prefix", s.RoomPrefix); err != nil {
+		return err
+	}
+	if err := validateRoomAffix("suffix", s.RoomSuffix); err != nil {
+		return err
+	}
+	if s.ServerPool != nil {
+		for _, host := range s.ServerPool.Hosts {
+			if err := validateURL("conference host", host); err != nil {
+				return err
+			}
+		}
+	}
+	return s.InviteIdentity.Validate()
+}
+
+// NewSlackOAuthHandlers returns oauth handlers with the configuration of
+// cfg, or the error from Validate when it's misconfigured.
+func NewSlackOAuthHandlers(cfg SlackOAuthHandlers) (*SlackOAuthHandlers, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks that the oauth handlers are configured well enough to
+// complete installs so misconfiguration fails at startup.
+func (o *SlackOAuthHandlers) Validate() error {
+	if strings.Count(o.AccessURLTemplate, "%s") != 3 {
+		return errors.New("access url template must have client id, client secret and code placeholders")
+	}
+	if o.ClientID == "" {
+		return errors.New("slack client id is required")
+	}
+	if o.ClientSecret == "" {
+		return errors.New("slack client secret is required")
+	}
+	if o.AppID == "" {
+		return errors.New("slack app id is required")
+	}
+	if o.TokenWriter == nil {
+		return errors.New("token writer is required")
+	}
+	if o.CanceledURL != "" {
+		if err := validateURL("canceled url", o.CanceledURL); err != nil {
+			return err
+		}
+	}
+	if o.SharableURL != "" {
+		return validateURL("sharable url", o.SharableURL)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package jitsi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewSlashCommandHandlers(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(s *SlashCommandHandlers)
+	}{
+		{"no conference host", func(s *SlashCommandHandlers) { s.ConferenceHost = "" }},
+		{"relative conference host", func(s *SlashCommandHandlers) { s.ConferenceHost = "meet.example.com" }},
+		{"no token generator", func(s *SlashCommandHandlers) { s.TokenGenerator = nil }},
+		{"bad signing key", func(s *SlashCommandHandlers) { s.TokenGenerator = TokenGenerator{PrivateKey: "not a key"} }},
+		{"no signing secret", func(s *SlashCommandHandlers) { s.SlackSigningSecret = "" }},
+		{"no token reader", func(s *SlashCommandHandlers) { s.TokenReader = nil }},
+		{"no install url", func(s *SlashCommandHandlers) { s.InstallURL, s.SharableURL = "", "" }},
+		{"bad install url", func(s *SlashCommandHandlers) { s.InstallURL = "ftp://slack.com/install" }},
+		{"negative channel size", func(s *SlashCommandHandlers) { s.ConfirmChannelSize = -1 }},
+		{"slack timeout too long", func(s *SlashCommandHandlers) { s.SlackRetry = RetryPolicy{Timeout: slackResponseTimeout} }},
+		{"empty worker pool", func(s *SlashCommandHandlers) { s.Workers = &WorkerPool{} }},
+		{"group limit too large", func(s *SlashCommandHandlers) { s.GroupInviteLimit = maxGroupInvitees + 1 }},
+		{"unknown name field", func(s *SlashCommandHandlers) { s.NameField = "nickname" }},
+		{"bad invite text", func(s *SlashCommandHandlers) { s.InviteText = "{{.Nope" }},
+		{"unknown invite text field", func(s *SlashCommandHandlers) { s.InviteText = "{{.Subject}}" }},
+		{"negative max url length", func(s *SlashCommandHandlers) { s.MaxURLLength = -1 }},
+		{"negative max occupants", func(s *SlashCommandHandlers) { s.MaxOccupants = -1 }},
+		{"max occupants too large", func(s *SlashCommandHandlers) { s.MaxOccupants = maxOccupantsLimit + 1 }},
+		{"bad room prefix", func(s *SlashCommandHandlers) { s.RoomPrefix = "acme/" }},
+		{"room suffix too long", func(s *SlashCommandHandlers) { s.RoomSuffix = strings.Repeat("x", maxRoomAffix+1) }},
+		{"bad pool host", func(s *SlashCommandHandlers) { s.ServerPool = &ServerPool{Hosts: []string{"nope"}} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestHandlers(t, newFakeSlack(t))
+			tt.configure(cfg)
+			if s, err := NewSlashCommandHandlers(*cfg); err == nil || s != nil {
+				t.Errorf("NewSlashCommandHandlers = %v, %v, want an error", s, err)
+			}
+		})
+	}
+
+	cfg := newTestHandlers(t, newFakeSlack(t))
+	s, err := NewSlashCommandHandlers(*cfg)
+	if err != nil {
+		t.Fatalf("NewSlashCommandHandlers = %v, want the test handlers valid", err)
+	}
+	if s.ConferenceHost != testConfHost || s.SlackSigningSecret != testSigningSecret {
+		t.Errorf("handlers = %+v, want the given configuration", s)
+	}
+}
+
+func TestNewSlackOAuthHandlers(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(o *SlackOAuthHandlers)
+	}{
+		{"bad access template", func(o *SlackOAuthHandlers) { o.AccessURLTemplate = "https://slack.com/api/oauth.v2.access" }},
+		{"no client id", func(o *SlackOAuthHandlers) { o.ClientID = "" }},
+		{"no client secret", func(o *SlackOAuthHandlers) { o.ClientSecret = "" }},
+		{"no app id", func(o *SlackOAuthHandlers) { o.AppID = "" }},
+		{"no token writer", func(o *SlackOAuthHandlers) { o.TokenWriter = nil }},
+		{"bad canceled url", func(o *SlackOAuthHandlers) { o.CanceledURL = "/canceled" }},
+		{"bad sharable url", func(o *SlackOAuthHandlers) { o.SharableURL = "slack.com/install" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestOAuthHandlers(newFakeSlack(t), &MemoryTokenStore{})
+			tt.configure(cfg)
+			if o, err := NewSlackOAuthHandlers(*cfg); err == nil || o != nil {
+				t.Errorf("NewSlackOAuthHandlers = %v, %v, want an error", o, err)
+			}
+		})
+	}
+
+	if _, err := NewSlackOAuthHandlers(*newTestOAuthHandlers(newFakeSlack(t), &MemoryTokenStore{})); err != nil {
+		t.Errorf("NewSlackOAuthHandlers = %v, want the test handlers valid", err)
+	}
+}
+
+// recordingTokenGenerator records the tokens it's asked for and fails with
+// err.
+type recordingTokenGenerator struct {
+	inputs []JWTInput
+	err    error
+}
+
+func (g *recordingTokenGenerator) CreateJWT(in JWTInput) (string, error) {
+	g.inputs = append(g.inputs, in)
+	return "token", g.err
+}
+
+func TestValidateSignsTokenAtStartup(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator ConferenceTokenGenerator
+		wantErr   bool
+	}{
+		{"signs", &recordingTokenGenerator{}, false},
+		{"can't sign", &recordingTokenGenerator{err: errors.New("no key")}, true},
+		{"no private key", TokenGenerator{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestHandlers(t, newFakeSlack(t))
+			cfg.TokenGenerator = tt.generator
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate = %v, want an error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "can't sign tokens") {
+				t.Errorf("Validate = %v, want it to say tokens can't be signed", err)
+			}
+			if g, ok := tt.generator.(*recordingTokenGenerator); ok && len(g.inputs) != 1 {
+				t.Errorf("signed %d tokens, want one throwaway token", len(g.inputs))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package jitsi
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+const busyMsg = "Too many meetings are being started right now, please try again in a moment."
+
+// WorkerPool runs the work done after responding to Slack, such as inviting
+// a channel, on a fixed number of goroutines so a burst of requests can't
+// exhaust the service. Work is queued while every worker is busy and
+// rejected once the queue is full.
+type WorkerPool struct {
+	// Size is the number of workers.
+	Size int
+	// Queue is how much work can wait for a worker.
+	Queue int
+
+	once    sync.Once
+	jobs    chan func()
+	queued  uint64
+	dropped uint64
+}
+
+// WorkerStats describes the load on a WorkerPool.
+type WorkerStats struct {
+	Workers    int `json:"workers"`
+	QueueSize  int `json:"queue_size"`
+	QueueDepth int `json:"queue_depth"`
+	// Queued and Dropped count the work queued and rejected since start.
+	Queued  uint64 `json:"queued"`
+	Dropped uint64 `json:"dropped"`
+}
+
+func (p *WorkerPool) start() {
+	p.jobs = make(chan func(), p.Queue)
+	for i := 0; i < p.Size; i++ {
+		go func() {
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+}
+
+// Go queues fn to run on a worker, returning false without running it when
+// the queue is full.
+func (p *WorkerPool) Go(fn func()) bool {
+	p.once.Do(p.start)
+	select {
+	case p.jobs <- fn:
+		atomic.AddUint64(&p.queued, 1)
+		return true
+	default:
+		atomic.AddUint64(&p.dropped, 1)
+		return false
+	}
+}
+
+// Depth is how much work is waiting for a worker.
+func (p *WorkerPool) Depth() int {
+	p.once.Do(p.start)
+	return len(p.jobs)
+}
+
+// Stats returns the pool's current load.
+func (p *WorkerPool) Stats() WorkerStats {
+	return WorkerStats{
+		Workers:    p.Size,
+		QueueSize:  p.Queue,
+		QueueDepth: p.Depth(),
+		Queued:     atomic.LoadUint64(&p.queued),
+		Dropped:    atomic.LoadUint64(&p.dropped),
+	}
+}
+
+// async runs fn after the request is responded to, on the Workers when
+// they're set. It returns false when the Workers are saturated and fn was
+// dropped.
+func (s *SlashCommandHandlers) async(ctx context.Context, fn func()) bool {
+	if s.Workers == nil {
+		go fn()
+		return true
+	}
+	log := zerolog.Ctx(ctx)
+	if !s.Workers.Go(fn) {
+		log.Warn().
+			Int("queue_depth", s.Workers.Depth()).
+			Msg("workers saturated, work dropped")
+		return false
+	}
+	log.Debug().
+		Int("queue_depth", s.Workers.Depth()).
+		Msg("work queued")
+	return true
+}
@@ -0,0 +1,178 @@
+package jitsi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockWorkers fills every worker and the queue of a pool with work that
+// waits for the returned release func.
+func blockWorkers(t *testing.T, p *WorkerPool) (release func()) {
+	t.Helper()
+	unblock := make(chan struct{})
+	started := make(chan struct{}, p.Size)
+	for i := 0; i < p.Size; i++ {
+		if !p.Go(func() { started <- struct{}{}; <-unblock }) {
+			t.Fatal("worker rejected work while idle")
+		}
+	}
+	for i := 0; i < p.Size; i++ {
+		<-started
+	}
+	for i := 0; i < p.Queue; i++ {
+		if !p.Go(func() { <-unblock }) {
+			t.Fatal("queue rejected work before it was full")
+		}
+	}
+	return func() { close(unblock) }
+}
+
+func TestWorkerPoolRejectsWorkWhenSaturated(t *testing.T) {
+	p := &WorkerPool{Size: 2, Queue: 3}
+	release := blockWorkers(t, p)
+	defer release()
+
+	if p.Go(func() { t.Error("dropped work ran") }) {
+		t.Fatal("saturated pool accepted work")
+	}
+	stats := p.Stats()
+	want := WorkerStats{Workers: 2, QueueSize: 3, QueueDepth: 3, Queued: 5, Dropped: 1}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestWorkerPoolEventuallyRunsQueuedWork(t *testing.T) {
+	p := &WorkerPool{Size: 2, Queue: 10}
+	release := blockWorkers(t, p)
+
+	var wg sync.WaitGroup
+	ran := make(chan int, 10)
+	release()
+	for i := 0; i < 10; i++ {
+		i := i
+		wg.Add(1)
+		for !p.Go(func() { defer wg.Done(); ran <- i }) {
+			// The blocked work is still draining.
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+	close(ran)
+	seen := map[int]bool{}
+	for i := range ran {
+		seen[i] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("ran %d jobs, want 10", len(seen))
+	}
+	if depth := p.Depth(); depth != 0 {
+		t.Errorf("queue depth = %d after draining, want 0", depth)
+	}
+}
+
+func TestSaturatedEventsReplyBusy(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  string
+		method string
+		check  func(t *testing.T, body map[string]interface{})
+	}{
+		{
+			"mention",
+			`{"type":"app_mention","user":"UBOB","channel":"C1","text":"<@UBOT> start"}`,
+			"chat.postEphemeral",
+			func(t *testing.T, body map[string]interface{}) {
+				if body["text"] != busyMsg || body["user"] != "UBOB" || body["channel"] != "C1" {
+					t.Errorf("reply = %v, want busyMsg to UBOB in C1", body)
+				}
+			},
+		},
+		{
+			"reaction",
+			`{"type":"reaction_added","user":"UBOB","reaction":"video_camera","item_user":"UCAROL","item":{"channel":"C2"}}`,
+			"chat.postEphemeral",
+			func(t *testing.T, body map[string]interface{}) {
+				if body["text"] != busyMsg || body["user"] != "UBOB" || body["channel"] != "C2" {
+					t.Errorf("reply = %v, want busyMsg to UBOB in C2", body)
+				}
+			},
+		},
+		{
+			"workflow step",
+			`{"type":"workflow_step_execute","callback_id":"start_meeting","workflow_step":{"workflow_step_execute_id":"X1"}}`,
+			"workflows.stepFailed",
+			func(t *testing.T, body map[string]interface{}) {
+				msg, _ := body["error"].(map[string]interface{})
+				if body["workflow_step_execute_id"] != "X1" || msg["message"] != busyMsg {
+					t.Errorf("reply = %v, want step X1 failed with busyMsg", body)
+				}
+			},
+		},
+		{
+			"home",
+			`{"type":"app_home_opened","user":"UBOB","tab":"home"}`,
+			"views.publish",
+			func(t *testing.T, body map[string]interface{}) {
+				view, _ := json.Marshal(body["view"])
+				if body["user_id"] != "UBOB" || !json.Valid(view) || !strings.Contains(string(view), busyMsg) {
+					t.Errorf("reply = %v, want a busy home view for UBOB", body)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			s := newTestHandlers(t, slack)
+			s.ReactionTrigger = "video_camera"
+			s.Workers = &WorkerPool{Size: 1, Queue: 1}
+			release := blockWorkers(t, s.Workers)
+			defer release()
+
+			body := `{"type":"event_callback","team_id":"T1","event":` + tt.event + `}`
+			w := httptest.NewRecorder()
+			s.Events(w, signedRequest(t, PathEvents, "application/json", body))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			calls := slack.Calls(tt.method)
+			if len(calls) != 1 {
+				t.Fatalf("%s calls = %d, want 1", tt.method, len(calls))
+			}
+			tt.check(t, calls[0].Body)
+			if dropped := s.Workers.Stats().Dropped; dropped != 1 {
+				t.Errorf("dropped = %d, want 1", dropped)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	s := &SlashCommandHandlers{AdminAPIToken: "admin", Workers: &WorkerPool{Size: 1, Queue: 2}}
+	release := blockWorkers(t, s.Workers)
+	defer release()
+
+	r := httptest.NewRequest(http.MethodGet, PathMetrics, nil)
+	w := httptest.NewRecorder()
+	s.Metrics(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status without a token = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	r.Header.Set("Authorization", "Bearer admin")
+	w = httptest.NewRecorder()
+	s.Metrics(w, r)
+	var metrics metricsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &metrics); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+	if metrics.Workers == nil || metrics.Workers.QueueDepth != 2 || metrics.Workers.QueueSize != 2 {
+		t.Errorf("metrics = %s, want a queue depth of 2", w.Body)
+	}
+}
@@ -0,0 +1,294 @@
+package jitsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/rs/zerolog"
+)
+
+const (
+	slackAPIURL = "https://slack.com/api/"
+
+	eventTypeWorkflowStepExecute = "workflow_step_execute"
+
+	interactionWorkflowStepEdit = "workflow_step_edit"
+	interactionViewSubmission   = "view_submission"
+
+	// workflowStepCallbackID identifies the start meeting step and its
+	// configuration view.
+	workflowStepCallbackID = "start_meeting"
+
+	workflowRoomInput  = "room"
+	workflowURLOutput  = "meeting_url"
+	workflowRoomOutput = "room"
+	// workflowHostInput records who configured the step so the HostPolicy
+	// can be checked when it runs, since executions don't name a user.
+	workflowHostInput = "host"
+
+	// errExpiredTriggerID is returned by views.open when the trigger id
+	// it was given is more than 3 seconds old.
+	errExpiredTriggerID = "expired_trigger_id"
+
+	expiredTriggerMsg = "The meeting step configuration took too long to open, please try editing the step again."
+	workflowFailedMsg = "The meeting couldn't be started, please try again later."
+
+	workflowStepErrorsTemplate = `{"response_action":"errors","errors":{"%s":%s}}`
+
+	workflowStepView = `{"type":"workflow_step","callback_id":"%[1]s","blocks":[{"type":"input","block_id":"%[2]s","optional":true,"label":{"type":"plain_text","text":"Room name"},"hint":{"type":"plain_text","text":"Leave empty for a new random room each time."},"element":{"type":"plain_text_input","action_id":"%[2]s"}}]}`
+)
+
+// workflowRoomRE matches the characters allowed in a configured room name.
+var workflowRoomRE = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// sanitizeRoomName removes the characters a configured room name can't use.
+func sanitizeRoomName(room string) string {
+	return workflowRoomRE.ReplaceAllString(room, "")
+}
+
+type workflowInput struct {
+	Value string `json:"value"`
+}
+
+type workflowStepExecuteEvent struct {
+	Type         string `json:"type"`
+	CallbackID   string `json:"callback_id"`
+	WorkflowStep struct {
+		WorkflowStepExecuteID string                   `json:"workflow_step_execute_id"`
+		Inputs                map[string]workflowInput `json:"inputs"`
+	} `json:"workflow_step"`
+}
+
+type workflowStepOutput struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+var workflowStepOutputs = []workflowStepOutput{
+	{Name: workflowURLOutput, Type: "text", Label: "Meeting URL"},
+	{Name: workflowRoomOutput, Type: "text", Label: "Room name"},
+}
+
+type slackAPIResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	// Channel and TS identify the message posted by chat.postMessage.
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// callSlackAPI posts a json body to a Slack api method the slack client
+// doesn't support.
+func (s *SlashCommandHandlers) callSlackAPI(ctx context.Context, token, method string, body interface{}) error {
+	_, err := s.callSlackAPIWith(ctx, s.SlackRetry, token, method, body)
+	return err
+}
+
+// callSlackAPIWith is callSlackAPI under the given retry policy, returning
+// the api response. Methods that post a message are only retried when
+// they're rate limited.
+func (s *SlashCommandHandlers) callSlackAPIWith(ctx context.Context, policy RetryPolicy, token, method string, body interface{}) (slackAPIResponse, error) {
+	var result slackAPIResponse
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return result, err
+	}
+	retryable := retryableSlackError
+	if postingMethods[method] {
+		retryable = retryableRateLimit
+	}
+	err = policy.Do(ctx, retryable, func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodPost, slackAPIURL+method, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := httpClientOrDefault(s.HTTPClient).Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := rateLimited(resp); err != nil {
+			return err
+		}
+		result = slackAPIResponse{}
+		return json.NewDecoder(resp.Body).Decode(&result)
+	})
+	if err != nil {
+		return result, err
+	}
+	if !result.OK {
+		return result, errors.New(result.Error)
+	}
+	return result, nil
+}
+
+// editWorkflowStep opens the configuration view for the start meeting step.
+// A trigger id is only valid for 3 seconds, so the view is opened before
+// anything but the token read and views.open isn't retried since a retry
+// would only fail with an expired trigger. The user is told to try again
+// when the trigger has expired.
+func (s *SlashCommandHandlers) editWorkflowStep(ctx context.Context, payload interactionPayload) error {
+	token, err := s.botToken(ctx, payload.Team.ID)
+	if err != nil {
+		return err
+	}
+	_, err = s.callSlackAPIWith(ctx, RetryPolicy{Timeout: s.SlackRetry.Timeout}, token, "views.open", map[string]interface{}{
+		"trigger_id": payload.TriggerID,
+		"view":       json.RawMessage(fmt.Sprintf(workflowStepView, workflowStepCallbackID, workflowRoomInput)),
+	})
+	if err == nil || err.Error() != errExpiredTriggerID {
+		return err
+	}
+	zerolog.Ctx(ctx).Warn().
+		Err(err).
+		Msg("workflow step configuration trigger expired")
+	// Workflow step edits have no response url, so the user is sent a DM.
+	return s.callSlackAPI(ctx, token, "chat.postMessage", map[string]interface{}{
+		"channel": payload.User.ID,
+		"text":    expiredTriggerMsg,
+	})
+}
+
+// saveWorkflowStep stores the configuration submitted from the step's view.
+func (s *SlashCommandHandlers) saveWorkflowStep(ctx context.Context, payload interactionPayload) error {
+	token, err := s.botToken(ctx, payload.Team.ID)
+	if err != nil {
+		return err
+	}
+	room := payload.View.State.Values[workflowRoomInput][workflowRoomInput].Value
+	return s.callSlackAPI(ctx, token, "workflows.updateStep", map[string]interface{}{
+		"workflow_step_edit_id": payload.WorkflowStep.WorkflowStepEditID,
+		"inputs": map[string]workflowInput{
+			workflowRoomInput: {Value: room},
+			workflowHostInput: {Value: payload.User.ID},
+		},
+		"outputs": workflowStepOutputs,
+	})
+}
+
+// workflowStepErrors returns the view response rejecting a step
+// configuration with a denied room name, or false when it can be saved.
+func (s *SlashCommandHandlers) workflowStepErrors(payload interactionPayload) (string, bool) {
+	room := sanitizeRoomName(payload.View.State.Values[workflowRoomInput][workflowRoomInput].Value)
+	if room == "" || !s.RoomDenylist.Denied(room) {
+		return "", false
+	}
+	msg, _ := json.Marshal(roomDeniedMsg)
+	return fmt.Sprintf(workflowStepErrorsTemplate, workflowRoomInput, msg), true
+}
+
+// executeWorkflowStep creates a meeting for a workflow and completes the
+// step with the meeting's url. The url isn't tied to a user since it's
+// shared by the workflow's later steps.
+func (s *SlashCommandHandlers) executeWorkflowStep(ctx context.Context, teamID string, event workflowStepExecuteEvent) {
+	log := zerolog.Ctx(ctx)
+	token, err := s.botToken(ctx, teamID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving token")
+		return
+	}
+
+	executeID := event.WorkflowStep.WorkflowStepExecuteID
+	if s.MaintenanceMode {
+		s.failWorkflowStep(ctx, token, executeID, s.maintenanceMessage())
+		return
+	}
+
+	slackClient := slack.New(token, slack.OptionHTTPClient(httpClientOrDefault(s.HTTPClient)))
+	// Steps saved before the host was recorded have no host, which only
+	// an unrestricted policy allows.
+	allowed := !s.HostPolicy.restricted()
+	if host := event.WorkflowStep.Inputs[workflowHostInput].Value; host != "" {
+		allowed, err = s.canHost(ctx, slackClient, teamID, host)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("checking host policy")
+			return
+		}
+	}
+	if !allowed {
+		s.failWorkflowStep(ctx, token, executeID, notHostMsg)
+		return
+	}
+
+	var team *slack.TeamInfo
+	err = s.callSlack(ctx, func(ctx context.Context) error {
+		var err error
+		team, err = slackClient.GetTeamInfoContext(ctx)
+		return err
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("retrieving team info from slack")
+		return
+	}
+
+	room := sanitizeRoomName(event.WorkflowStep.Inputs[workflowRoomInput].Value)
+	if s.RoomDenylist.Denied(room) {
+		// The step was saved before the room was denied.
+		log.Info().
+			Str("room", room).
+			Msg("configured room name denied, using a random room")
+		room = ""
+	}
+	if room == "" {
+		room = s.roomName(RandomName())
+	}
+	serverCfg, err := s.teamServerConfig(ctx, teamID)
+	if err != nil {
+		s.failWorkflowStep(ctx, token, executeID, workflowFailedMsg)
+		return
+	}
+	if !serverCfg.Capabilities.Enabled(capabilityWorkflowMeetings) {
+		s.failWorkflowStep(ctx, token, executeID, fmt.Sprintf(capabilityDisabledMsg, knownCapabilities[capabilityWorkflowMeetings]))
+		return
+	}
+	meetingURL := fmt.Sprintf(
+		"%s/%s/%s",
+		serverCfg.ConferenceHost,
+		strings.ToLower(team.Domain),
+		room,
+	)
+	logMeetingURL(ctx, teamID, false)
+
+	err = s.callSlackAPI(ctx, token, "workflows.stepCompleted", map[string]interface{}{
+		"workflow_step_execute_id": executeID,
+		"outputs": map[string]string{
+			workflowURLOutput:  meetingURL,
+			workflowRoomOutput: room,
+		},
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("completing workflow step")
+	}
+}
+
+// failWorkflowStep fails a workflow step execution with a message shown in
+// the workflow's activity.
+func (s *SlashCommandHandlers) failWorkflowStep(ctx context.Context, token, executeID, msg string) {
+	err := s.callSlackAPI(ctx, token, "workflows.stepFailed", map[string]interface{}{
+		"workflow_step_execute_id": executeID,
+		"error":                    map[string]string{"message": msg},
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().
+			Err(err).
+			Msg("failing workflow step")
+	}
+}
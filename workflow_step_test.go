@@ -0,0 +1,290 @@
+package jitsi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const workflowStepEditPayload = `{"type":"workflow_step_edit","callback_id":"start_meeting","trigger_id":"TR1","user":{"id":"UHOST"},"team":{"id":"T1"}}`
+
+// executeWorkflowStep runs a start meeting step with inputs.
+func executeWorkflowStep(s *SlashCommandHandlers, inputs map[string]workflowInput) {
+	var event workflowStepExecuteEvent
+	event.Type = eventTypeWorkflowStepExecute
+	event.CallbackID = workflowStepCallbackID
+	event.WorkflowStep.WorkflowStepExecuteID = "X1"
+	event.WorkflowStep.Inputs = inputs
+	s.executeWorkflowStep(context.Background(), testTeamID, event)
+}
+
+// stepFailure returns the message of the single workflows.stepFailed call.
+func stepFailure(t *testing.T, slack *fakeSlack) string {
+	t.Helper()
+	calls := slack.Calls("workflows.stepFailed")
+	if len(calls) != 1 {
+		t.Fatalf("workflows.stepFailed calls = %d, want 1", len(calls))
+	}
+	if id := calls[0].Body["workflow_step_execute_id"]; id != "X1" {
+		t.Errorf("failed step %v, want X1", id)
+	}
+	msg, _ := calls[0].Body["error"].(map[string]interface{})
+	got, _ := msg["message"].(string)
+	return got
+}
+
+func TestWorkflowStepOutputsMeetingURL(t *testing.T) {
+	tests := []struct {
+		name string
+		room string
+		want string
+	}{
+		{"configured room", "WeeklySync", "WeeklySync"},
+		{"sanitized room", "Weekly Sync!", "WeeklySync"},
+		{"random room", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			s := newTestHandlers(t, slack)
+
+			executeWorkflowStep(s, map[string]workflowInput{workflowRoomInput: {Value: tt.room}})
+			calls := slack.Calls("workflows.stepCompleted")
+			if len(calls) != 1 {
+				t.Fatalf("workflows.stepCompleted calls = %d, want 1", len(calls))
+			}
+			if id := calls[0].Body["workflow_step_execute_id"]; id != "X1" {
+				t.Errorf("completed step %v, want X1", id)
+			}
+			outputs, _ := calls[0].Body["outputs"].(map[string]interface{})
+			room, _ := outputs[workflowRoomOutput].(string)
+			if tt.want != "" && room != tt.want {
+				t.Errorf("room output = %q, want %q", room, tt.want)
+			}
+			if room == "" {
+				t.Fatalf("outputs = %v, want a room", outputs)
+			}
+			if got, want := outputs[workflowURLOutput], testConfHost+"/acme/"+room; got != want {
+				t.Errorf("meeting url output = %v, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestWorkflowStepRandomRoomsDiffer(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	executeWorkflowStep(s, nil)
+	executeWorkflowStep(s, nil)
+	calls := slack.Calls("workflows.stepCompleted")
+	if len(calls) != 2 {
+		t.Fatalf("workflows.stepCompleted calls = %d, want 2", len(calls))
+	}
+	first, _ := calls[0].Body["outputs"].(map[string]interface{})
+	second, _ := calls[1].Body["outputs"].(map[string]interface{})
+	if first[workflowRoomOutput] == second[workflowRoomOutput] {
+		t.Errorf("both executions used room %v, want a new room each time", first[workflowRoomOutput])
+	}
+}
+
+func TestWorkflowStepFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(s *SlashCommandHandlers)
+		inputs map[string]workflowInput
+		want   string
+	}{
+		{
+			"maintenance",
+			func(s *SlashCommandHandlers) { s.MaintenanceMode = true },
+			nil,
+			defaultMaintenanceMessage,
+		},
+		{
+			"host not allowed",
+			func(s *SlashCommandHandlers) { s.HostPolicy = HostPolicy{Users: []string{"UALICE"}} },
+			map[string]workflowInput{workflowHostInput: {Value: "UBOB"}},
+			notHostMsg,
+		},
+		{
+			"no host with a restricted policy",
+			func(s *SlashCommandHandlers) { s.HostPolicy = HostPolicy{Users: []string{"UALICE"}} },
+			nil,
+			notHostMsg,
+		},
+		{
+			"capability disabled",
+			func(s *SlashCommandHandlers) {
+				configs := &MemoryServerConfigStore{}
+				configs.StoreServerConfig(testTeamID, ServerConfig{Capabilities: Capabilities{capabilityWorkflowMeetings: false}})
+				s.ServerConfigs = configs
+			},
+			nil,
+			fmt.Sprintf(capabilityDisabledMsg, knownCapabilities[capabilityWorkflowMeetings]),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			s := newTestHandlers(t, slack)
+			tt.setup(s)
+
+			executeWorkflowStep(s, tt.inputs)
+			if got := stepFailure(t, slack); got != tt.want {
+				t.Errorf("failure = %q, want %q", got, tt.want)
+			}
+			if calls := slack.Calls("workflows.stepCompleted"); len(calls) != 0 {
+				t.Errorf("workflows.stepCompleted calls = %d, want none", len(calls))
+			}
+		})
+	}
+}
+
+func TestWorkflowStepAllowedHost(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.HostPolicy = HostPolicy{Users: []string{"UALICE"}}
+
+	executeWorkflowStep(s, map[string]workflowInput{workflowHostInput: {Value: "UALICE"}})
+	if calls := slack.Calls("workflows.stepCompleted"); len(calls) != 1 {
+		t.Errorf("workflows.stepCompleted calls = %d, want 1", len(calls))
+	}
+}
+
+func TestWorkflowStepExecuteEvent(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	body := `{"type":"event_callback","team_id":"T1","event":{"type":"workflow_step_execute","callback_id":"start_meeting","workflow_step":{"workflow_step_execute_id":"X1","inputs":{"room":{"value":"standup"}}}}}`
+	w := httptest.NewRecorder()
+	s.Events(w, signedRequest(t, PathEvents, "application/json", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	// The step is completed after the event is acknowledged.
+	calls := waitForCalls(t, slack, "workflows.stepCompleted")
+	if len(calls) != 1 {
+		t.Fatalf("workflows.stepCompleted calls = %d, want 1", len(calls))
+	}
+	outputs, _ := calls[0].Body["outputs"].(map[string]interface{})
+	if got, want := outputs[workflowURLOutput], testConfHost+"/acme/standup"; got != want {
+		t.Errorf("meeting url output = %v, want %s", got, want)
+	}
+}
+
+func TestWorkflowStepEditOpensView(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	w := interact(t, s, workflowStepEditPayload)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	calls := slack.Calls("views.open")
+	if len(calls) != 1 {
+		t.Fatalf("views.open calls = %d, want 1", len(calls))
+	}
+	if calls[0].Body["trigger_id"] != "TR1" {
+		t.Errorf("views.open trigger = %v, want TR1", calls[0].Body["trigger_id"])
+	}
+	view, _ := calls[0].Body["view"].(map[string]interface{})
+	if view["type"] != "workflow_step" || view["callback_id"] != workflowStepCallbackID {
+		t.Errorf("view = %v, want the start meeting step view", view)
+	}
+}
+
+func TestWorkflowStepEditExpiredTrigger(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("views.open", `{"ok":false,"error":"expired_trigger_id"}`)
+	s := newTestHandlers(t, slack)
+
+	interact(t, s, workflowStepEditPayload)
+	if calls := slack.Calls("views.open"); len(calls) != 1 {
+		t.Errorf("views.open calls = %d, want no retries", len(calls))
+	}
+	posted := slack.Calls("chat.postMessage")
+	if len(posted) != 1 || posted[0].Body["channel"] != "UHOST" || !strings.Contains(fmt.Sprint(posted[0].Body["text"]), "took too long") {
+		t.Errorf("chat.postMessage calls = %+v, want the editor told to try again", posted)
+	}
+}
+
+func TestWorkflowStepSaveRecordsHost(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+
+	payload := `{"type":"view_submission","user":{"id":"UHOST"},"team":{"id":"T1"},"workflow_step":{"workflow_step_edit_id":"E1"},"view":{"callback_id":"start_meeting","state":{"values":{"room":{"room":{"value":"standup"}}}}}}`
+	interact(t, s, payload)
+	calls := slack.Calls("workflows.updateStep")
+	if len(calls) != 1 {
+		t.Fatalf("workflows.updateStep calls = %d, want 1", len(calls))
+	}
+	if calls[0].Body["workflow_step_edit_id"] != "E1" {
+		t.Errorf("updated step %v, want E1", calls[0].Body["workflow_step_edit_id"])
+	}
+	inputs, _ := calls[0].Body["inputs"].(map[string]interface{})
+	host, _ := inputs[workflowHostInput].(map[string]interface{})
+	if host["value"] != "UHOST" {
+		t.Errorf("saved inputs = %v, want the host recorded", inputs)
+	}
+	if outputs, _ := calls[0].Body["outputs"].([]interface{}); len(outputs) != len(workflowStepOutputs) {
+		t.Errorf("saved outputs = %v, want %d outputs", calls[0].Body["outputs"], len(workflowStepOutputs))
+	}
+}
+
+func TestWorkflowStepEditViewNotRetried(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(slack *fakeSlack)
+	}{
+		{"rate limited", func(slack *fakeSlack) { slack.RateLimit("views.open", 1) }},
+		{"timed out", func(slack *fakeSlack) { slack.Delay("views.open", 300*time.Millisecond) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newFakeSlack(t)
+			tt.setup(slack)
+			s := newTestHandlers(t, slack)
+			s.SlackRetry = RetryPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond, Timeout: 100 * time.Millisecond}
+
+			// A retry would only fail with an expired trigger.
+			if w := interact(t, s, workflowStepEditPayload); w.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+			}
+			if calls := slack.Calls("views.open"); len(calls) != 1 {
+				t.Errorf("views.open calls = %d, want 1", len(calls))
+			}
+		})
+	}
+}
+
+func TestWorkflowStepEditOpensViewFirst(t *testing.T) {
+	slack := newFakeSlack(t)
+	s := newTestHandlers(t, slack)
+	s.ServerConfigs = stubServerConfigs{err: errors.New("table unavailable")}
+
+	if w := interact(t, s, workflowStepEditPayload); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if calls := slack.Calls("views.open"); len(calls) != 1 {
+		t.Errorf("views.open calls = %d, want the view opened without reading the server config", len(calls))
+	}
+}
+
+func TestWorkflowStepEditOtherErrors(t *testing.T) {
+	slack := newFakeSlack(t)
+	slack.Handle("views.open", `{"ok":false,"error":"invalid_arguments"}`)
+	s := newTestHandlers(t, slack)
+
+	if w := interact(t, s, workflowStepEditPayload); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if calls := slack.Calls("chat.postMessage"); len(calls) != 0 {
+		t.Errorf("chat.postMessage calls = %d, want the expired trigger message only for expired triggers", len(calls))
+	}
+}